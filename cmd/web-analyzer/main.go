@@ -3,13 +3,15 @@ package main
 import (
 	"WebAppAnalyzer/config/env"
 	"WebAppAnalyzer/config/logger"
+	"WebAppAnalyzer/internal/analyzer"
+	"WebAppAnalyzer/internal/cli"
 	"WebAppAnalyzer/internal/server"
 	"context"
+	"github.com/sirupsen/logrus"
 	"log"
 	"os"
 	"os/signal"
 	"path/filepath"
-	"strconv"
 	"syscall"
 	"time"
 
@@ -17,29 +19,20 @@ import (
 	_ "WebAppAnalyzer/internal/docs"
 )
 
+// defaultShutdownTimeout bounds the drain of the server when CONTEXT_TIMEOUT_SECONDS is not set
+const defaultShutdownTimeout = 30 * time.Second
+
 // @title           Web Page Analyzer API
 // @version         1.0
 // @description     A web service that analyzes web pages and provides detailed information about their structure, links, and forms.
 // @host      localhost:8080
 // @BasePath  /api/v1
 func main() {
-	configPaths := []string{
-		"cmd/web-analyzer/app.env",
-		"./app.env",
+	if len(os.Args) > 1 && os.Args[1] != "serve" {
+		os.Exit(runCLI(os.Args[1:]))
 	}
-	correctConfigPaths := "."
 
-	for _, path := range configPaths {
-		matches, err := filepath.Glob(path)
-		if err == nil && len(matches) > 0 {
-			//correctConfigPaths = matches[0]
-			correctConfigPaths = filepath.Dir(matches[0])
-			break
-		} else {
-			log.Println("Config not found at path:", path)
-		}
-	}
-	config, err := env.LoadConfig(correctConfigPaths)
+	config, err := loadConfig()
 	if err != nil {
 		panic("Failed to load environment variables: " + err.Error())
 	}
@@ -49,7 +42,7 @@ func main() {
 	srv := server.NewServer(log, &config)
 
 	go func() {
-		log.Infof("Starting web srv on port 8080")
+		log.Infof("Starting web srv on port %s", config.Port)
 		if err := srv.ListenAndServe(&config.Port); err != nil {
 			log.Fatalf("Failed to start srv: %v", err)
 		}
@@ -61,9 +54,12 @@ func main() {
 
 	log.Infof("Shutting down server...")
 
-	contextTimeOutConfigured, _ := strconv.Atoi(config.Port)
+	shutdownTimeout := time.Duration(config.ContextTimeoutInSeconds) * time.Second
+	if shutdownTimeout <= 0 {
+		shutdownTimeout = defaultShutdownTimeout
+	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(contextTimeOutConfigured)*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
 	defer cancel()
 
 	if err := srv.Shutdown(ctx); err != nil {
@@ -71,3 +67,43 @@ func main() {
 	}
 	log.Infof("Server exited gracefully")
 }
+
+// runCLI runs a command from the terminal with the analyzer and returns the exit code. Without an app.env the
+// defaults are used, so the CLI works from any directory.
+func runCLI(args []string) int {
+	config, err := loadConfig()
+	if err != nil {
+		config = env.Config{}
+	}
+	log := logger.NewLogger(config)
+	// Only problems are logged, the results are the output of the CLI
+	log.SetLevel(logrus.WarnLevel)
+
+	pageAnalyzer := analyzer.NewPageAnalyzer(log, &config)
+	defer pageAnalyzer.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	return cli.New(pageAnalyzer, &config, os.Stdin, os.Stdout, os.Stderr).Run(ctx, args)
+}
+
+func loadConfig() (env.Config, error) {
+	configPaths := []string{
+		"cmd/web-analyzer/app.env",
+		"./app.env",
+	}
+	correctConfigPaths := "."
+
+	for _, path := range configPaths {
+		matches, err := filepath.Glob(path)
+		if err == nil && len(matches) > 0 {
+			//correctConfigPaths = matches[0]
+			correctConfigPaths = filepath.Dir(matches[0])
+			break
+		} else {
+			log.Println("Config not found at path:", path)
+		}
+	}
+	return env.LoadConfig(correctConfigPaths)
+}
@@ -3,11 +3,55 @@ package env
 import "github.com/spf13/viper"
 
 type Config struct {
-	LogLevel                string `mapstructure:"LOG_LEVEL"`
-	Port                    string `mapstructure:"PORT"`
-	NumOfWorkers            int    `mapstructure:"NUM_OF_WORKERS"`
-	ContextTimeoutInSeconds int    `mapstructure:"CONTEXT_TIMEOUT_SECONDS"`
-	WebAppTitle             string `mapstructure:"WEB_APP_TITLE"`
+	LogLevel                     string  `mapstructure:"LOG_LEVEL"`
+	Port                         string  `mapstructure:"PORT"`
+	NumOfWorkers                 int     `mapstructure:"NUM_OF_WORKERS"`
+	ContextTimeoutInSeconds      int     `mapstructure:"CONTEXT_TIMEOUT_SECONDS"`
+	WebAppTitle                  string  `mapstructure:"WEB_APP_TITLE"`
+	LinkCheckScope               string  `mapstructure:"LINK_CHECK_SCOPE"`
+	MaxLinksPerPage              int     `mapstructure:"MAX_LINKS_PER_PAGE"`
+	LinkMaxRedirects             int     `mapstructure:"LINK_MAX_REDIRECTS"`
+	LinkCacheTTLInSeconds        int     `mapstructure:"LINK_CACHE_TTL_SECONDS"`
+	LinkCacheSize                int     `mapstructure:"LINK_CACHE_SIZE"`
+	HostMaxConcurrency           int     `mapstructure:"HOST_MAX_CONCURRENCY"`
+	HostRequestsPerSecond        float64 `mapstructure:"HOST_REQUESTS_PER_SECOND"`
+	LinkMaxRetries               int     `mapstructure:"LINK_MAX_RETRIES"`
+	MaxRetryAfterInSeconds       int     `mapstructure:"MAX_RETRY_AFTER_SECONDS"`
+	CrawlMaxDepth                int     `mapstructure:"CRAWL_MAX_DEPTH"`
+	CrawlMaxPages                int     `mapstructure:"CRAWL_MAX_PAGES"`
+	JobWorkers                   int     `mapstructure:"JOB_WORKERS"`
+	JobQueueSize                 int     `mapstructure:"JOB_QUEUE_SIZE"`
+	JobRetentionInSeconds        int     `mapstructure:"JOB_RETENTION_SECONDS"`
+	BatchMaxURLs                 int     `mapstructure:"BATCH_MAX_URLS"`
+	BatchParallelism             int     `mapstructure:"BATCH_PARALLELISM"`
+	HistoryEnabled               bool    `mapstructure:"HISTORY_ENABLED"`
+	HistoryDBPath                string  `mapstructure:"HISTORY_DB_PATH"`
+	HistoryRetentionInSeconds    int     `mapstructure:"HISTORY_RETENTION_SECONDS"`
+	MonitorsEnabled              bool    `mapstructure:"MONITORS_ENABLED"`
+	WebhookTimeoutInSeconds      int     `mapstructure:"WEBHOOK_TIMEOUT_SECONDS"`
+	WebhookSecret                string  `mapstructure:"WEBHOOK_SECRET"`
+	WebhookMaxAttempts           int     `mapstructure:"WEBHOOK_MAX_ATTEMPTS"`
+	WebhookRetryBackoffInSeconds int     `mapstructure:"WEBHOOK_RETRY_BACKOFF_SECONDS"`
+	HTMLUploadMaxBytes           int64   `mapstructure:"HTML_UPLOAD_MAX_BYTES"`
+	PageMaxBytes                 int64   `mapstructure:"PAGE_MAX_BYTES"`
+	SSRFAllowlist                string  `mapstructure:"SSRF_ALLOWLIST"`
+	DomainAllowlist              string  `mapstructure:"DOMAIN_ALLOWLIST"`
+	DomainDenylist               string  `mapstructure:"DOMAIN_DENYLIST"`
+	DomainPolicies               string  `mapstructure:"DOMAIN_POLICIES"`
+	ServerReadTimeoutInSeconds   int     `mapstructure:"SERVER_READ_TIMEOUT_SECONDS"`
+	ServerWriteTimeoutInSeconds  int     `mapstructure:"SERVER_WRITE_TIMEOUT_SECONDS"`
+	ServerIdleTimeoutInSeconds   int     `mapstructure:"SERVER_IDLE_TIMEOUT_SECONDS"`
+	ServerDrainDelayInSeconds    int     `mapstructure:"SERVER_DRAIN_DELAY_SECONDS"`
+	TLSCertFile                  string  `mapstructure:"TLS_CERT_FILE"`
+	TLSKeyFile                   string  `mapstructure:"TLS_KEY_FILE"`
+	TLSClientCAFile              string  `mapstructure:"TLS_CLIENT_CA_FILE"`
+	TLSReloadIntervalInSeconds   int     `mapstructure:"TLS_RELOAD_INTERVAL_SECONDS"`
+	HTTP2Enabled                 bool    `mapstructure:"HTTP2_ENABLED"`
+	MetricsEnabled               bool    `mapstructure:"METRICS_ENABLED"`
+	TracingExporter              string  `mapstructure:"TRACING_EXPORTER"`
+	TracingOTLPEndpoint          string  `mapstructure:"TRACING_OTLP_ENDPOINT"`
+	TracingSampleRatio           float64 `mapstructure:"TRACING_SAMPLE_RATIO"`
+	TracingPropagateOutbound     bool    `mapstructure:"TRACING_PROPAGATE_OUTBOUND"`
 }
 
 func LoadConfig(path string) (config Config, err error) {
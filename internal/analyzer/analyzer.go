@@ -3,130 +3,377 @@ package analyzer
 import (
 	"WebAppAnalyzer/config/env"
 	"WebAppAnalyzer/config/logger"
+	"WebAppAnalyzer/internal/metrics"
 	"WebAppAnalyzer/internal/models"
+	"WebAppAnalyzer/internal/netguard"
 	"WebAppAnalyzer/internal/validator"
+	"bytes"
 	"context"
 	"fmt"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/net/html"
 	"io"
+	"mime"
 	"net/http"
 	"net/url"
 	"strings"
-	"sync"
 	"time"
 )
 
+// Link check scopes accepted by the LINK_CHECK_SCOPE setting
+const (
+	LinkCheckScopeExternal = "external"
+	LinkCheckScopeInternal = "internal"
+	LinkCheckScopeAll      = "all"
+	LinkCheckScopeNone     = "none"
+)
+
+const (
+	defaultNumOfWorkers = 5
+	defaultPageMaxBytes = 10 << 20
+	// linkQueueSize bounds the number of links an analysis may have waiting for a checker, the page walker blocks beyond it
+	linkQueueSize = 100
+)
+
 type PageAnalyzer struct {
-	client    *http.Client
-	validator *validator.URLValidator
-	logger    *logger.Logger
-	config    *env.Config
+	client      *http.Client
+	validator   *validator.URLValidator
+	logger      *logger.Logger
+	config      *env.Config
+	linkCache   *linkStatusCache
+	hostLimiter *hostLimiter
+	pool        *linkCheckPool
+
+	metrics *metrics.Metrics
 }
 
 type LinkCheckResult struct {
-	URL          string
-	IsAccessible bool
-	StatusCode   int
-	Error        error
+	URL              string
+	AnchorText       string
+	IsExternal       bool
+	IsAccessible     bool
+	StatusCode       int
+	Error            error
+	ResponseTime     time.Duration
+	SkipReason       string
+	Cached           bool
+	CheckedAt        time.Time
+	Method           string
+	FinalURL         string
+	RedirectChain    []models.RedirectHop
+	RedirectLoop     bool
+	TooManyRedirects bool
+	Timing           *models.TimingBreakdown
+}
+
+// linkTarget is a link queued for checking together with the text it was found under
+type linkTarget struct {
+	URL        string
+	AnchorText string
+	IsExternal bool
+}
+
+// linkSink collects the links discovered while walking a page
+type linkSink struct {
+	session     *poolSession
+	progress    *progressReporter
+	queued      int
+	occurrences map[string]int
+	internal    []string
+	invalid     []models.LinkDetail
+	skipped     []models.SkippedLink
+	// site looks links up in the files of a static site build instead of checking them over HTTP, when set
+	site    *staticSite
+	offline []models.LinkDetail
 }
 
 func NewPageAnalyzer(logger *logger.Logger, c *env.Config) *PageAnalyzer {
-	return &PageAnalyzer{
+	// Pages and links are fetched from user-supplied URLs, so connections to the internal network are refused
+	// unless their addresses are on the SSRF_ALLOWLIST
+	guard, err := netguard.NewGuard(c.SSRFAllowlist)
+	if err != nil {
+		logger.Error("Ignoring invalid SSRF allowlist entries", err)
+	}
+	urlValidator, err := validator.NewURLValidatorWithPolicies(c)
+	if err != nil {
+		logger.Error("Ignoring invalid domain patterns and policies", err)
+	}
+
+	p := &PageAnalyzer{
 		client: &http.Client{
 			Timeout: 30 * time.Second,
 			Transport: &http.Transport{
+				DialContext:         urlValidator.DialContext(guard),
 				MaxIdleConns:        100,
 				MaxIdleConnsPerHost: 10,
 				IdleConnTimeout:     90 * time.Second,
 			},
 		},
-		validator: validator.NewURLValidator(),
-		logger:    logger,
-		config:    c,
+		validator:   urlValidator,
+		logger:      logger,
+		config:      c,
+		linkCache:   newLinkStatusCache(time.Duration(c.LinkCacheTTLInSeconds)*time.Second, c.LinkCacheSize),
+		hostLimiter: newHostLimiter(c.HostMaxConcurrency, c.HostRequestsPerSecond),
 	}
+	p.pool = newLinkCheckPool(c.NumOfWorkers, p.checkLink)
+
+	return p
+}
+
+// Close stops the shared link check workers once the links already queued have been checked
+func (p *PageAnalyzer) Close() {
+	p.pool.close()
+}
+
+// PoolStats returns the utilization and queue depth of the shared link check workers
+func (p *PageAnalyzer) PoolStats() models.LinkCheckPoolStats {
+	return p.pool.stats()
+}
+
+// URLValidator returns the validator the analyzer checks URLs with, applying the domain lists and policies
+func (p *PageAnalyzer) URLValidator() *validator.URLValidator {
+	return p.validator
+}
+
+// SetMetrics makes the analyzer record its analyses, page fetches and link checks in m
+func (p *PageAnalyzer) SetMetrics(m *metrics.Metrics) {
+	p.metrics = m
 }
 
 // Analyze performs the analysis of the given URL and returns the result
 func (p *PageAnalyzer) Analyze(ctx context.Context, url string) *models.AnalysisResult {
+	result, _, _ := p.analyze(ctx, url)
+	return result
+}
+
+// analyze performs the analysis of the given URL and also returns the internal links discovered on the page. When
+// the response is not an HTML document its media type is returned, and the analysis fails without reading the body.
+func (p *PageAnalyzer) analyze(ctx context.Context, url string) (result *models.AnalysisResult, internalLinks []string, notHTML string) {
+	startTime := time.Now()
+	ctx, span := startSpan(ctx, "analyze", trace.WithAttributes(semconv.URLFull(url)))
+	result = models.NewAnalysisResult(url)
+	defer func() {
+		p.metrics.ObserveAnalysis(metrics.SourceFetch, result, time.Since(startTime))
+		span.SetAttributes(semconv.HTTPResponseStatusCode(result.HTTPStatusCode))
+		endAnalysisSpan(span, result)
+	}()
 
-	result := models.NewAnalysisResult(url)
 	validatedUrl, err := p.validator.ValidateURL(url)
 	if err != nil {
-		result.SetError(fmt.Sprintf("Invalid URL: %s", url), 400)
+		result.SetError(invalidURLError(url, err))
 		p.logger.Error("Invalid URL", err)
-		return result
+		return result, nil, ""
 	}
 
 	p.logger.Info("Analyzing URL", validatedUrl)
 
-	resp, err := p.fetchPage(ctx, validatedUrl)
+	progress := newProgressReporter(ctx)
+	progress.stage(models.StageFetching)
+
+	// Registered before the body is closed, so the download of a body left unread ends at its close
+	timing := newRequestTiming()
+	defer func() {
+		result.FetchTiming = timing.result()
+	}()
+
+	resp, err := p.fetchPage(timing.withTrace(ctx), validatedUrl)
 	if err != nil {
 		result.SetError(fmt.Sprintf("Failed to fetch URL: %v", err), 0)
-		return result
+		return result, nil, ""
 	}
+	resp.Body = timing.timeBody(resp.Body)
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		result.SetError(fmt.Sprintf("HTTP Error: %d - %s", resp.StatusCode, http.StatusText(resp.StatusCode)), resp.StatusCode)
-		return result
+		return result, nil, ""
+	}
+	if mediaType, ok := htmlMediaType(resp.Header.Get("Content-Type")); !ok {
+		result.SetError(fmt.Sprintf("The page is not an HTML document but %s", mediaType), 0)
+		return result, nil, mediaType
 	}
 
-	// Parse the HTML
-	doc, err := p.parseHTML(resp.Body)
+	// The page is read in full before it is parsed, so its download time does not include the parsing. Reading
+	// stops past PAGE_MAX_BYTES, so a huge page cannot exhaust the memory.
+	maxBytes := p.config.PageMaxBytes
+	if maxBytes <= 0 {
+		maxBytes = defaultPageMaxBytes
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxBytes+1))
 	if err != nil {
-		result.SetError(fmt.Sprintf("Failed to parse HTML: %v", err), 0)
-		return result
+		result.SetError(fmt.Sprintf("Failed to read the page: %v", err), 0)
+		return result, nil, ""
+	}
+	if int64(len(body)) > maxBytes {
+		result.SetError(fmt.Sprintf("The page is larger than %d bytes", maxBytes), 0)
+		return result, nil, ""
 	}
 
-	p.analyzeHTMLWithConcurrency(ctx, doc, validatedUrl, result)
+	internalLinks = p.analyzeDocument(ctx, bytes.NewReader(body), validatedUrl, result, progress, nil)
 
 	p.logger.Info("Page analysis completed")
 
-	return result
+	return result, internalLinks, ""
 }
 
-// analyzeHTMLWithConcurrency analyzes the HTML document concurrently
-func (p *PageAnalyzer) analyzeHTMLWithConcurrency(ctx context.Context, n *html.Node, baseURL string, result *models.AnalysisResult) {
-	externalLinks := make(chan string, 100)
-	linkResults := make(chan LinkCheckResult, 100)
+// htmlMediaType returns the media type of a Content-Type header and whether it is an HTML document. Responses
+// without a usable Content-Type are given the benefit of the doubt.
+func htmlMediaType(contentType string) (string, bool) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return contentType, true
+	}
+	return mediaType, mediaType == "text/html" || mediaType == "application/xhtml+xml"
+}
 
-	// Start link checker workers in goroutines because they will block on network I/O
-	numWorkers := p.config.NumOfWorkers
-	var wg sync.WaitGroup
+// AnalyzeHTML analyzes an HTML document that was not fetched by the analyzer, such as a saved snapshot.
+// Relative links are resolved against baseURL, and the links found are checked like those of a fetched page.
+func (p *PageAnalyzer) AnalyzeHTML(ctx context.Context, baseURL string, body io.Reader) *models.AnalysisResult {
+	startTime := time.Now()
+	ctx, span := startSpan(ctx, "analyze", trace.WithAttributes(semconv.URLFull(baseURL), attribute.String("analysis.source", metrics.SourceHTML)))
+	result := models.NewAnalysisResult(baseURL)
+	defer func() {
+		p.metrics.ObserveAnalysis(metrics.SourceHTML, result, time.Since(startTime))
+		endAnalysisSpan(span, result)
+	}()
 
-	// Start multiple link checker workers
-	for i := 0; i < numWorkers; i++ {
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			p.linkChecker(ctx, externalLinks, linkResults)
-		}()
+	validatedUrl, err := p.validator.ValidateURL(baseURL)
+	if err != nil {
+		result.SetError(invalidURLError(baseURL, err))
+		p.logger.Error("Invalid base URL", err)
+		return result
 	}
 
-	go func() {
-		p.analyzeHTML(ctx, n, baseURL, result, externalLinks)
-		close(externalLinks)
-	}()
+	p.logger.Info("Analyzing HTML document for", validatedUrl)
+
+	p.analyzeDocument(ctx, body, validatedUrl, result, newProgressReporter(ctx), nil)
+	return result
+}
 
-	// Wait till all link checkers to finish, then close results channel
+// analyzeDocument parses the HTML document, analyzes it and checks its links, and returns the internal links.
+// The links of a page of a static site build are looked up in site instead of being checked over HTTP.
+func (p *PageAnalyzer) analyzeDocument(ctx context.Context, body io.Reader, baseURL string, result *models.AnalysisResult, progress *progressReporter, site *staticSite) []string {
+	progress.stage(models.StageParsing)
+	doc, err := p.parseHTML(ctx, body)
+	if err != nil {
+		result.SetError(fmt.Sprintf("Failed to parse HTML: %v", err), 0)
+		return nil
+	}
+
+	progress.stage(models.StageCheckingLinks)
+	internalLinks := p.analyzeHTMLWithConcurrency(ctx, doc, baseURL, result, progress, site)
+	progress.stage(models.StageCompleted)
+
+	return internalLinks
+}
+
+// analyzeHTMLWithConcurrency analyzes the HTML document while the shared link check pool checks the links
+// found, and returns the internal links. The walker blocks on the bounded session queue while the checkers
+// are busy, so every discovered link is either checked or recorded as skipped with a reason.
+func (p *PageAnalyzer) analyzeHTMLWithConcurrency(ctx context.Context, n *html.Node, baseURL string, result *models.AnalysisResult, progress *progressReporter, site *staticSite) []string {
+	session := p.pool.register(ctx)
+	// The session drops its channel once it is closed, so hold on to it before the walker starts
+	results := session.results
+
+	links := &linkSink{session: session, progress: progress, occurrences: make(map[string]int), site: site}
 	go func() {
-		wg.Wait()
-		close(linkResults)
+		// The link checks run in the pool with the context of the session, so they are children of the analysis
+		// rather than of the walk queueing them, which they may outlast
+		walkCtx, span := startSpan(ctx, "analyzeHTML", trace.WithAttributes(semconv.URLFull(baseURL)))
+		p.analyzeHTML(walkCtx, n, baseURL, result, links)
+		span.SetAttributes(attribute.Int("links.queued", links.queued), attribute.Int("links.skipped", len(links.skipped)))
+		span.End()
+		session.finish()
 	}()
 
 	// Process results (this will block until all results are processed)
-	p.processLinkResults(linkResults, result)
+	p.processLinkResults(results, result, progress)
+
+	// Links that could not even be parsed or queued are recorded once the walker is done to avoid racing the result processing
+	for _, link := range links.invalid {
+		result.AddLink(link)
+	}
+	for _, link := range links.offline {
+		result.AddLink(link)
+	}
+	for _, link := range links.skipped {
+		result.AddSkippedLink(link)
+		p.metrics.ObserveSkippedLink(link.Reason)
+	}
+	for i := range result.Links {
+		result.Links[i].Occurrences = max(links.occurrences[normalizeURL(result.Links[i].URL)], 1)
+	}
+
+	return links.internal
 }
 
-func (p *PageAnalyzer) processLinkResults(results <-chan LinkCheckResult, analysisResult *models.AnalysisResult) {
+func (p *PageAnalyzer) processLinkResults(results <-chan LinkCheckResult, analysisResult *models.AnalysisResult, progress *progressReporter) {
 	for result := range results {
-		if !result.IsAccessible {
-			analysisResult.InaccessibleLinks++
+		if result.SkipReason != "" {
+			skipped := models.SkippedLink{
+				URL:        result.URL,
+				AnchorText: result.AnchorText,
+				IsExternal: result.IsExternal,
+				Reason:     result.SkipReason,
+			}
+			analysisResult.AddSkippedLink(skipped)
+			progress.linkSkipped(skipped, true)
+			p.metrics.ObserveSkippedLink(skipped.Reason)
+			continue
 		}
+
+		detail := p.linkDetail(result)
+		analysisResult.AddLink(detail)
+		p.metrics.ObserveLinkCheck(detail)
+		progress.linkChecked(detail)
 	}
 }
 
-func (p *PageAnalyzer) analyzeHTML(ctx context.Context, n *html.Node, baseURL string, result *models.AnalysisResult, externalLinks chan<- string) {
+// linkDetail converts a link check result into the per-link report entry
+func (p *PageAnalyzer) linkDetail(result LinkCheckResult) models.LinkDetail {
+	detail := models.LinkDetail{
+		URL:            result.URL,
+		AnchorText:     result.AnchorText,
+		IsExternal:     result.IsExternal,
+		IsAccessible:   result.IsAccessible,
+		StatusCode:     result.StatusCode,
+		Method:         result.Method,
+		ResponseTimeMs: result.ResponseTime.Milliseconds(),
+		Cached:         result.Cached,
+		CheckedAt:      result.CheckedAt,
+	}
+
+	if !result.Cached {
+		detail.Timing = result.Timing
+	}
+
+	if len(result.RedirectChain) > 0 {
+		detail.FinalURL = result.FinalURL
+		detail.RedirectChain = result.RedirectChain
+	}
+
+	if !result.IsAccessible {
+		detail.ErrorClass = classifyLinkError(result.Error)
+		switch {
+		case result.RedirectLoop:
+			detail.ErrorClass = models.LinkErrorRedirectLoop
+			detail.Error = "redirect loop detected"
+		case result.TooManyRedirects:
+			detail.ErrorClass = models.LinkErrorTooManyRedirects
+			detail.Error = fmt.Sprintf("stopped after %d redirects", len(result.RedirectChain))
+		case result.Error != nil:
+			detail.Error = result.Error.Error()
+		default:
+			detail.Error = fmt.Sprintf("HTTP %d %s", result.StatusCode, http.StatusText(result.StatusCode))
+		}
+	}
+
+	return detail
+}
+
+func (p *PageAnalyzer) analyzeHTML(ctx context.Context, n *html.Node, baseURL string, result *models.AnalysisResult, links *linkSink) {
 	// Analyze current node
 	switch n.Type {
 	case html.DocumentNode:
@@ -143,7 +390,7 @@ func (p *PageAnalyzer) analyzeHTML(ctx context.Context, n *html.Node, baseURL st
 		case "h1", "h2", "h3", "h4", "h5", "h6":
 			result.AddHeading(n.Data)
 		case "a":
-			p.analyzeLink(ctx, n, baseURL, result, externalLinks)
+			p.analyzeLink(ctx, n, baseURL, result, links)
 			p.checkSkipLink(n, result)
 		case "form":
 			p.analyzeForm(n, result)
@@ -178,7 +425,7 @@ func (p *PageAnalyzer) analyzeHTML(ctx context.Context, n *html.Node, baseURL st
 
 	// Recursively analyze child nodes
 	for c := n.FirstChild; c != nil; c = c.NextSibling {
-		p.analyzeHTML(ctx, c, baseURL, result, externalLinks)
+		p.analyzeHTML(ctx, c, baseURL, result, links)
 	}
 }
 
@@ -265,7 +512,7 @@ func (p *PageAnalyzer) checkFormInputs(n *html.Node, hasPassword, hasUsername, h
 }
 
 // analyzeForm checks if a form is a login form and extracts its action and method
-func (p *PageAnalyzer) analyzeLink(ctx context.Context, n *html.Node, baseURL string, result *models.AnalysisResult, externalLinks chan<- string) {
+func (p *PageAnalyzer) analyzeLink(ctx context.Context, n *html.Node, baseURL string, result *models.AnalysisResult, links *linkSink) {
 	var href string
 	for _, attr := range n.Attr {
 		if attr.Key == "href" {
@@ -283,9 +530,11 @@ func (p *PageAnalyzer) analyzeLink(ctx context.Context, n *html.Node, baseURL st
 		return
 	}
 
+	anchorText := p.anchorText(n)
+
 	parsedURL, err := url.Parse(href)
 	if err != nil {
-		result.InaccessibleLinks++
+		links.invalid = append(links.invalid, invalidLinkDetail(href, anchorText, err))
 		return
 	}
 
@@ -293,24 +542,107 @@ func (p *PageAnalyzer) analyzeLink(ctx context.Context, n *html.Node, baseURL st
 	if !parsedURL.IsAbs() {
 		baseParsed, err := url.Parse(baseURL)
 		if err != nil {
-			result.InaccessibleLinks++
+			links.invalid = append(links.invalid, invalidLinkDetail(href, anchorText, err))
 			return
 		}
 		parsedURL = baseParsed.ResolveReference(parsedURL)
 	}
 
+	target := linkTarget{URL: parsedURL.String(), AnchorText: anchorText}
+
 	// Check if it's internal or external
 	if p.validator.IsInternalLink(parsedURL.String(), baseURL) {
 		result.InternalLinks++
+		links.internal = append(links.internal, parsedURL.String())
 	} else {
 		result.ExternalLinks++
-		select {
-		case externalLinks <- parsedURL.String():
-			// Link sent successfully
-		default:
-			p.logger.WithField("url", parsedURL.String()).Warn("Link checking channel full, skipping link")
+		target.IsExternal = true
+	}
+
+	if links.site != nil {
+		links.lookUp(target)
+		return
+	}
+
+	if !p.shouldCheckLink(target.IsExternal) {
+		return
+	}
+
+	// Repeated links are only checked, or skipped, once per analysis
+	key := normalizeURL(target.URL)
+	links.occurrences[key]++
+	if links.occurrences[key] > 1 {
+		return
+	}
+
+	if !p.linkCheckAllowed(parsedURL.Hostname()) {
+		links.skip(target, models.SkipReasonDomainPolicy)
+		return
+	}
+
+	if p.config.MaxLinksPerPage > 0 && links.queued >= p.config.MaxLinksPerPage {
+		links.skip(target, models.SkipReasonLinkLimit)
+		return
+	}
+
+	// Blocks until the session queue has room so links are never dropped, unless the analysis is cancelled
+	if err := links.session.submit(target); err != nil {
+		links.skip(target, models.SkipReasonCancelled)
+		return
+	}
+	links.queued++
+	links.progress.linkFound()
+}
+
+// skip records a link that will not be checked
+func (s *linkSink) skip(target linkTarget, reason string) {
+	skipped := models.SkippedLink{
+		URL:        target.URL,
+		AnchorText: target.AnchorText,
+		IsExternal: target.IsExternal,
+		Reason:     reason,
+	}
+	s.skipped = append(s.skipped, skipped)
+	s.progress.linkSkipped(skipped, false)
+}
+
+// shouldCheckLink reports whether links of the given class are checked for accessibility under the configured scope
+func (p *PageAnalyzer) shouldCheckLink(isExternal bool) bool {
+	switch strings.ToLower(p.config.LinkCheckScope) {
+	case LinkCheckScopeAll:
+		return true
+	case LinkCheckScopeInternal:
+		return !isExternal
+	case LinkCheckScopeNone:
+		return false
+	default:
+		return isExternal
+	}
+}
+
+// anchorText returns the visible text of a link, falling back to its aria-label or title
+func (p *PageAnalyzer) anchorText(n *html.Node) string {
+	text := strings.Join(strings.Fields(p.getTextContent(n)), " ")
+	if text != "" {
+		return text
+	}
+	for _, attr := range n.Attr {
+		if attr.Key == "aria-label" || attr.Key == "title" {
+			return strings.TrimSpace(attr.Val)
 		}
 	}
+	return ""
+}
+
+// invalidLinkDetail builds the report entry for a link whose href could not be parsed
+func invalidLinkDetail(href, anchorText string, err error) models.LinkDetail {
+	return models.LinkDetail{
+		URL:          href,
+		AnchorText:   anchorText,
+		IsAccessible: false,
+		ErrorClass:   models.LinkErrorInvalidURL,
+		Error:        err.Error(),
+	}
 }
 
 func (p *PageAnalyzer) extractHTMLVersion(n *html.Node) string {
@@ -333,23 +665,47 @@ func (p *PageAnalyzer) extractHTMLVersion(n *html.Node) string {
 	return "Unknown"
 }
 
-func (pa *PageAnalyzer) parseHTML(body io.Reader) (*html.Node, error) {
-	doc, err := html.Parse(body)
+func (pa *PageAnalyzer) parseHTML(ctx context.Context, body io.Reader) (doc *html.Node, err error) {
+	_, span := startSpan(ctx, "parseHTML")
+	defer func() {
+		endSpan(span, err)
+	}()
+
+	doc, err = html.Parse(body)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse HTML: %w", err)
 	}
 	return doc, nil
 }
 
-func (p *PageAnalyzer) fetchPage(ctx context.Context, url string) (*http.Response, error) {
+func (p *PageAnalyzer) fetchPage(ctx context.Context, url string) (resp *http.Response, err error) {
+	ctx, span := startSpan(ctx, "fetchPage", trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(semconv.URLFull(url), semconv.HTTPRequestMethodGet))
+	defer func() {
+		if resp != nil {
+			span.SetAttributes(semconv.HTTPResponseStatusCode(resp.StatusCode))
+		}
+		endSpan(span, err)
+	}()
+
 	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
-	req.Header.Set("User-Agent", "WebPageAnalyzer/1.0")
+	req.Header.Set("User-Agent", p.userAgent(req.URL.Hostname()))
+	p.injectTraceContext(ctx, req)
+
+	client := p.client
+	if policy, ok := p.validator.Policy(req.URL.Hostname()); ok && policy.Timeout() > 0 {
+		withTimeout := *p.client
+		withTimeout.Timeout = policy.Timeout()
+		client = &withTimeout
+	}
 
-	resp, err := p.client.Do(req)
+	startTime := time.Now()
+	resp, err = client.Do(req)
+	p.metrics.ObservePageFetch(time.Since(startTime), err)
 	if err != nil {
 		p.logger.Error("Failed to fetch URL", url, err)
 		return nil, fmt.Errorf("failed to fetch page: %w", err)
@@ -358,52 +714,6 @@ func (p *PageAnalyzer) fetchPage(ctx context.Context, url string) (*http.Respons
 	return resp, nil
 }
 
-// linkChecker checks links concurrently and sends results to the results channel
-func (p *PageAnalyzer) linkChecker(ctx context.Context, links <-chan string, results chan<- LinkCheckResult) {
-	for linkURL := range links {
-		select {
-		case <-ctx.Done():
-			// Context cancelled, stop processing
-			return
-		default:
-			result := p.checkSingleLink(ctx, linkURL)
-			results <- result
-		}
-	}
-}
-
-func (p *PageAnalyzer) checkSingleLink(ctx context.Context, linkURL string) LinkCheckResult {
-	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
-	defer cancel()
-
-	req, err := http.NewRequestWithContext(ctx, "HEAD", linkURL, nil)
-	if err != nil {
-		return LinkCheckResult{
-			URL:          linkURL,
-			IsAccessible: false,
-			Error:        err,
-		}
-	}
-
-	req.Header.Set("User-Agent", "WebPageAnalyzer/1.0")
-
-	resp, err := p.client.Do(req)
-	if err != nil {
-		return LinkCheckResult{
-			URL:          linkURL,
-			IsAccessible: false,
-			Error:        err,
-		}
-	}
-	defer resp.Body.Close()
-
-	return LinkCheckResult{
-		URL:          linkURL,
-		IsAccessible: resp.StatusCode == http.StatusOK,
-		StatusCode:   resp.StatusCode,
-	}
-}
-
 // analyzeImage analyzes an img element and extracts relevant information
 func (p *PageAnalyzer) analyzeImage(n *html.Node, baseURL string, result *models.AnalysisResult) {
 	imgInfo := models.ImageInfo{}
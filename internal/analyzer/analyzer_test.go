@@ -3,14 +3,29 @@ package analyzer
 import (
 	"WebAppAnalyzer/config/env"
 	"WebAppAnalyzer/config/logger"
+	"WebAppAnalyzer/internal/metrics"
 	"WebAppAnalyzer/internal/models"
 	"WebAppAnalyzer/internal/validator"
 	"context"
 	"fmt"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
 	"io"
+	"net"
 	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"testing/fstest"
 	"time"
 )
 
@@ -78,6 +93,7 @@ func createTestAnalyzer() (*PageAnalyzer, *MockTransport) {
 		logger:    logger,
 		config:    config,
 	}
+	analyzer.pool = newLinkCheckPool(config.NumOfWorkers, analyzer.checkLink)
 
 	return analyzer, mockTransport
 }
@@ -664,3 +680,1376 @@ func BenchmarkAnalyzer(b *testing.B) {
 		analyzer.Analyze(ctx, "https://benchmark.com")
 	}
 }
+
+// TestCrawl tests following internal links across a site
+func TestCrawl(t *testing.T) {
+	analyzer, mockTransport := createTestAnalyzer()
+
+	pages := map[string]string{
+		"https://crawlsite.com":         `<a href="/about">About</a><a href="/contact#form">Contact</a><a href="https://external.com">External</a><a href="/brochure.pdf">Brochure</a><a href="/feed">Feed</a>`,
+		"https://crawlsite.com/about":   `<a href="/team">Team</a><a href="/">Home</a>`,
+		"https://crawlsite.com/contact": `<form><input type="email" name="email"><input type="password" name="password"></form>`,
+		"https://crawlsite.com/team":    `<h1>Team</h1>`,
+	}
+	for pageURL, body := range pages {
+		mockTransport.responses[pageURL] = &MockResponse{
+			StatusCode: 200,
+			Body:       fmt.Sprintf(`<!DOCTYPE html><html><head><title>%s</title></head><body>%s</body></html>`, pageURL, body),
+			Headers: map[string]string{
+				"Content-Type": "text/html",
+			},
+		}
+	}
+
+	mockTransport.responses["https://crawlsite.com/brochure.pdf"] = &MockResponse{StatusCode: 200, Body: "%PDF-1.4"}
+	mockTransport.responses["https://crawlsite.com/feed"] = &MockResponse{
+		StatusCode: 200,
+		Body:       "<rss></rss>",
+		Headers: map[string]string{
+			"Content-Type": "application/rss+xml; charset=utf-8",
+		},
+	}
+
+	testCases := []struct {
+		name            string
+		opts            models.CrawlOptions
+		expectedPages   int
+		budgetExhausted bool
+	}{
+		{
+			name:          "Depth one",
+			opts:          models.CrawlOptions{MaxDepth: 1, MaxPages: 10},
+			expectedPages: 3,
+		},
+		{
+			name:          "Depth two",
+			opts:          models.CrawlOptions{MaxDepth: 2, MaxPages: 10},
+			expectedPages: 4,
+		},
+		{
+			name:            "Page budget",
+			opts:            models.CrawlOptions{MaxDepth: 2, MaxPages: 2},
+			expectedPages:   2,
+			budgetExhausted: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			report := analyzer.Crawl(context.Background(), "https://crawlsite.com", tc.opts)
+
+			if report.Error != "" {
+				t.Errorf("Unexpected error: %s", report.Error)
+			}
+			if report.PagesAnalyzed != tc.expectedPages {
+				t.Errorf("Expected %d pages, got %d", tc.expectedPages, report.PagesAnalyzed)
+			}
+			if report.BudgetExhausted != tc.budgetExhausted {
+				t.Errorf("Expected budget exhausted to be %v, got %v", tc.budgetExhausted, report.BudgetExhausted)
+			}
+			if report.Pages[0].Depth != 0 || report.Pages[0].URL != "https://crawlsite.com" {
+				t.Errorf("Expected seed page first, got %s at depth %d", report.Pages[0].URL, report.Pages[0].Depth)
+			}
+			for _, page := range report.Pages {
+				if page.URL == "https://external.com" {
+					t.Error("External links should not be crawled")
+				}
+				if strings.HasSuffix(page.URL, ".pdf") || strings.HasSuffix(page.URL, "/feed") {
+					t.Errorf("Expected %s not to be counted as a page", page.URL)
+				}
+			}
+		})
+	}
+
+	report := analyzer.Crawl(context.Background(), "https://crawlsite.com", models.CrawlOptions{MaxDepth: 1, MaxPages: 10})
+	if len(report.NonHTMLPages) != 1 || report.NonHTMLPages[0].URL != "https://crawlsite.com/feed" ||
+		report.NonHTMLPages[0].ContentType != "application/rss+xml" {
+		t.Errorf("Expected the feed to be reported as a non HTML page, got %+v", report.NonHTMLPages)
+	}
+
+	if report.PagesWithLoginForm != 1 {
+		t.Errorf("Expected 1 page with login form, got %d", report.PagesWithLoginForm)
+	}
+
+	invalid := analyzer.Crawl(context.Background(), "not-a-url", models.CrawlOptions{})
+	if invalid.Error == "" {
+		t.Error("Expected error for invalid seed URL")
+	}
+}
+
+// TestLinkDetails tests the per-link report produced for checked links
+func TestLinkDetails(t *testing.T) {
+	analyzer, mockTransport := createTestAnalyzer()
+
+	mockTransport.responses["https://linkdetails.com"] = &MockResponse{
+		StatusCode: 200,
+		Body: `<!DOCTYPE html>
+<html>
+<head><title>Link Details</title></head>
+<body>
+    <a href="https://ok.com">Working   link</a>
+    <a href="https://missing.com" title="Missing page"></a>
+    <a href="https://nodns.com">No DNS</a>
+    <a href="https://slow.com">Slow</a>
+    <a href="http://%zz">Bad href</a>
+</body>
+</html>`,
+		Headers: map[string]string{
+			"Content-Type": "text/html",
+		},
+	}
+	mockTransport.responses["https://ok.com"] = &MockResponse{StatusCode: 200}
+	mockTransport.responses["https://missing.com"] = &MockResponse{StatusCode: 404}
+	mockTransport.responses["https://nodns.com"] = &MockResponse{
+		Error: &net.DNSError{Err: "no such host", Name: "nodns.com", IsNotFound: true},
+	}
+	mockTransport.responses["https://slow.com"] = &MockResponse{Error: context.DeadlineExceeded}
+
+	result := analyzer.Analyze(context.Background(), "https://linkdetails.com")
+
+	if result.Error != "" {
+		t.Fatalf("Unexpected error: %s", result.Error)
+	}
+
+	links := make(map[string]models.LinkDetail)
+	for _, link := range result.Links {
+		links[link.URL] = link
+	}
+
+	testCases := []struct {
+		url        string
+		anchorText string
+		accessible bool
+		statusCode int
+		errorClass string
+	}{
+		{url: "https://ok.com", anchorText: "Working link", accessible: true, statusCode: 200},
+		{url: "https://missing.com", anchorText: "Missing page", statusCode: 404, errorClass: models.LinkErrorHTTP},
+		{url: "https://nodns.com", anchorText: "No DNS", errorClass: models.LinkErrorDNS},
+		{url: "https://slow.com", anchorText: "Slow", errorClass: models.LinkErrorTimeout},
+		{url: "http://%zz", anchorText: "Bad href", errorClass: models.LinkErrorInvalidURL},
+	}
+
+	for _, tc := range testCases {
+		link, ok := links[tc.url]
+		if !ok {
+			t.Errorf("Expected link detail for %s", tc.url)
+			continue
+		}
+		if link.AnchorText != tc.anchorText {
+			t.Errorf("%s: expected anchor text '%s', got '%s'", tc.url, tc.anchorText, link.AnchorText)
+		}
+		if link.IsAccessible != tc.accessible {
+			t.Errorf("%s: expected accessible %v, got %v", tc.url, tc.accessible, link.IsAccessible)
+		}
+		if link.StatusCode != tc.statusCode {
+			t.Errorf("%s: expected status code %d, got %d", tc.url, tc.statusCode, link.StatusCode)
+		}
+		if link.ErrorClass != tc.errorClass {
+			t.Errorf("%s: expected error class '%s', got '%s'", tc.url, tc.errorClass, link.ErrorClass)
+		}
+	}
+
+	if result.InaccessibleLinks != 4 {
+		t.Errorf("Expected 4 inaccessible links, got %d", result.InaccessibleLinks)
+	}
+	// The bad href is neither internal nor external
+	if result.InaccessibleInternalLinks != 0 || result.InaccessibleExternalLinks != 3 {
+		t.Errorf("Expected 0 internal and 3 external inaccessible links, got %d and %d",
+			result.InaccessibleInternalLinks, result.InaccessibleExternalLinks)
+	}
+	if len(result.BrokenLinks()) != 4 {
+		t.Errorf("Expected 4 broken links, got %d", len(result.BrokenLinks()))
+	}
+}
+
+// TestLinkCheckScope tests which link classes are checked under each scope
+func TestLinkCheckScope(t *testing.T) {
+	analyzer, mockTransport := createTestAnalyzer()
+
+	mockTransport.responses["https://scope.com"] = &MockResponse{
+		StatusCode: 200,
+		Body: `<!DOCTYPE html>
+<html>
+<head><title>Scope</title></head>
+<body>
+    <a href="/working">Working internal</a>
+    <a href="/broken">Broken internal</a>
+    <a href="https://external-ok.com">Working external</a>
+    <a href="https://external-broken.com">Broken external</a>
+</body>
+</html>`,
+		Headers: map[string]string{
+			"Content-Type": "text/html",
+		},
+	}
+	mockTransport.responses["https://scope.com/working"] = &MockResponse{StatusCode: 200}
+	mockTransport.responses["https://external-ok.com"] = &MockResponse{StatusCode: 200}
+
+	testCases := []struct {
+		scope                string
+		expectedChecked      int
+		expectedInternalFail int
+		expectedExternalFail int
+	}{
+		{scope: "", expectedChecked: 2, expectedExternalFail: 1},
+		{scope: LinkCheckScopeExternal, expectedChecked: 2, expectedExternalFail: 1},
+		{scope: LinkCheckScopeInternal, expectedChecked: 2, expectedInternalFail: 1},
+		{scope: LinkCheckScopeAll, expectedChecked: 4, expectedInternalFail: 1, expectedExternalFail: 1},
+		{scope: LinkCheckScopeNone, expectedChecked: 0},
+	}
+
+	for _, tc := range testCases {
+		t.Run("scope "+tc.scope, func(t *testing.T) {
+			analyzer.config.LinkCheckScope = tc.scope
+			result := analyzer.Analyze(context.Background(), "https://scope.com")
+
+			if len(result.Links) != tc.expectedChecked {
+				t.Errorf("Expected %d checked links, got %d", tc.expectedChecked, len(result.Links))
+			}
+			if result.InaccessibleInternalLinks != tc.expectedInternalFail {
+				t.Errorf("Expected %d inaccessible internal links, got %d", tc.expectedInternalFail, result.InaccessibleInternalLinks)
+			}
+			if result.InaccessibleExternalLinks != tc.expectedExternalFail {
+				t.Errorf("Expected %d inaccessible external links, got %d", tc.expectedExternalFail, result.InaccessibleExternalLinks)
+			}
+			if result.InaccessibleLinks != tc.expectedInternalFail+tc.expectedExternalFail {
+				t.Errorf("Expected %d inaccessible links, got %d", tc.expectedInternalFail+tc.expectedExternalFail, result.InaccessibleLinks)
+			}
+			for _, link := range result.Links {
+				if link.IsExternal == strings.HasPrefix(link.URL, "https://scope.com") {
+					t.Errorf("Link %s has wrong class, is_external=%v", link.URL, link.IsExternal)
+				}
+			}
+		})
+	}
+}
+
+// TestAnalyzeHTML tests analyzing a snapshot that is not fetched, resolving its links against the base URL
+func TestAnalyzeHTML(t *testing.T) {
+	analyzer, mockTransport := createTestAnalyzer()
+	analyzer.config.LinkCheckScope = LinkCheckScopeAll
+	mockTransport.responses["https://snapshot.com/working"] = &MockResponse{StatusCode: 200}
+
+	snapshot := `<!DOCTYPE html>
+<html>
+<head><title>Snapshot</title></head>
+<body>
+    <h1>Snapshot</h1>
+    <a href="/working">Working</a>
+    <a href="/broken">Broken</a>
+</body>
+</html>`
+
+	result := analyzer.AnalyzeHTML(context.Background(), "https://snapshot.com", strings.NewReader(snapshot))
+
+	if !result.IsSuccessful() {
+		t.Fatalf("Expected no error, got %s", result.Error)
+	}
+	if result.PageTitle != "Snapshot" || result.HTMLVersion != "HTML5" || result.Headings["h1"] != 1 {
+		t.Errorf("Expected the snapshot to be analyzed, got %+v", result)
+	}
+	if len(result.Links) != 2 || result.InaccessibleInternalLinks != 1 {
+		t.Errorf("Expected 2 checked links with 1 broken, got %+v", result.Links)
+	}
+
+	result = analyzer.AnalyzeHTML(context.Background(), "not a url", strings.NewReader(snapshot))
+	if result.IsSuccessful() || result.HTTPStatusCode != 400 {
+		t.Errorf("Expected an invalid base URL to fail, got %+v", result)
+	}
+}
+
+// roundTripFunc adapts a function to http.RoundTripper for tests that depend on the request method
+type roundTripFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// TestCheckSingleLink tests HEAD fallback, redirect handling and status semantics of the link checker
+func TestCheckSingleLink(t *testing.T) {
+	analyzer, _ := createTestAnalyzer()
+	analyzer.config.LinkMaxRedirects = 3
+
+	respond := func(req *http.Request, statusCode int, location string) *http.Response {
+		resp := &http.Response{
+			StatusCode: statusCode,
+			Body:       io.NopCloser(strings.NewReader("")),
+			Header:     make(http.Header),
+			Request:    req,
+		}
+		if location != "" {
+			resp.Header.Set("Location", location)
+		}
+		return resp
+	}
+
+	analyzer.client.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		switch req.URL.Path {
+		case "/ok":
+			return respond(req, 200, ""), nil
+		case "/no-content":
+			return respond(req, 204, ""), nil
+		case "/no-head":
+			if req.Method == http.MethodHead {
+				return respond(req, 405, ""), nil
+			}
+			if req.Header.Get("Range") != "bytes=0-0" {
+				return respond(req, 400, ""), nil
+			}
+			return respond(req, 206, ""), nil
+		case "/empty-no-head":
+			if req.Method == http.MethodHead {
+				return respond(req, 405, ""), nil
+			}
+			return respond(req, 416, ""), nil
+		case "/moved":
+			return respond(req, 301, "/ok"), nil
+		case "/moved-broken":
+			return respond(req, 302, "https://links.com/gone"), nil
+		case "/loop-a":
+			return respond(req, 302, "/loop-b"), nil
+		case "/loop-b":
+			return respond(req, 302, "/loop-a"), nil
+		case "/chain":
+			return respond(req, 302, fmt.Sprintf("/chain?hop=%s1", req.URL.Query().Get("hop"))), nil
+		}
+		return respond(req, 404, ""), nil
+	})
+
+	testCases := []struct {
+		path             string
+		accessible       bool
+		statusCode       int
+		method           string
+		redirects        int
+		finalURL         string
+		redirectLoop     bool
+		tooManyRedirects bool
+	}{
+		{path: "/ok", accessible: true, statusCode: 200, method: "HEAD", finalURL: "https://links.com/ok"},
+		{path: "/no-content", accessible: true, statusCode: 204, method: "HEAD", finalURL: "https://links.com/no-content"},
+		{path: "/no-head", accessible: true, statusCode: 206, method: "GET", finalURL: "https://links.com/no-head"},
+		{path: "/empty-no-head", accessible: true, statusCode: 416, method: "GET", finalURL: "https://links.com/empty-no-head"},
+		{path: "/moved", accessible: true, statusCode: 200, method: "HEAD", redirects: 1, finalURL: "https://links.com/ok"},
+		{path: "/moved-broken", statusCode: 404, method: "HEAD", redirects: 1, finalURL: "https://links.com/gone"},
+		{path: "/loop-a", statusCode: 302, method: "HEAD", redirects: 2, finalURL: "https://links.com/loop-b", redirectLoop: true},
+		{path: "/chain", statusCode: 302, method: "HEAD", redirects: 3, finalURL: "https://links.com/chain?hop=11", tooManyRedirects: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.path, func(t *testing.T) {
+			result := analyzer.checkSingleLink(context.Background(), "https://links.com"+tc.path)
+
+			if result.IsAccessible != tc.accessible {
+				t.Errorf("Expected accessible %v, got %v", tc.accessible, result.IsAccessible)
+			}
+			if result.StatusCode != tc.statusCode {
+				t.Errorf("Expected status code %d, got %d", tc.statusCode, result.StatusCode)
+			}
+			if result.Method != tc.method {
+				t.Errorf("Expected method %s, got %s", tc.method, result.Method)
+			}
+			if len(result.RedirectChain) != tc.redirects {
+				t.Errorf("Expected %d redirects, got %d", tc.redirects, len(result.RedirectChain))
+			}
+			if result.FinalURL != tc.finalURL {
+				t.Errorf("Expected final URL %s, got %s", tc.finalURL, result.FinalURL)
+			}
+			if result.RedirectLoop != tc.redirectLoop {
+				t.Errorf("Expected redirect loop %v, got %v", tc.redirectLoop, result.RedirectLoop)
+			}
+			if result.TooManyRedirects != tc.tooManyRedirects {
+				t.Errorf("Expected too many redirects %v, got %v", tc.tooManyRedirects, result.TooManyRedirects)
+			}
+
+			detail := analyzer.linkDetail(result)
+			if tc.redirectLoop && detail.ErrorClass != models.LinkErrorRedirectLoop {
+				t.Errorf("Expected error class %s, got %s", models.LinkErrorRedirectLoop, detail.ErrorClass)
+			}
+			if tc.tooManyRedirects && detail.ErrorClass != models.LinkErrorTooManyRedirects {
+				t.Errorf("Expected error class %s, got %s", models.LinkErrorTooManyRedirects, detail.ErrorClass)
+			}
+		})
+	}
+}
+
+// TestLinksNeverDropped tests that every discovered link is checked even when there are more links than queue slots
+func TestLinksNeverDropped(t *testing.T) {
+	analyzer, mockTransport := createTestAnalyzer()
+
+	body := `<!DOCTYPE html><html><head><title>Many Links</title></head><body>`
+	for i := 0; i < 3*linkQueueSize; i++ {
+		body += fmt.Sprintf(`<a href="https://many%d.com">Link %d</a>`, i, i)
+	}
+	body += `</body></html>`
+	mockTransport.responses["https://manylinks.com"] = &MockResponse{StatusCode: 200, Body: body}
+
+	result := analyzer.Analyze(context.Background(), "https://manylinks.com")
+
+	if len(result.Links) != 3*linkQueueSize {
+		t.Errorf("Expected %d checked links, got %d", 3*linkQueueSize, len(result.Links))
+	}
+	if len(result.SkippedLinks) != 0 || !result.LinkCheckComplete {
+		t.Errorf("Expected complete link check, got %d skipped links", len(result.SkippedLinks))
+	}
+}
+
+// TestAnalysisProgress tests that the progress of an analysis is reported through the context
+func TestAnalysisProgress(t *testing.T) {
+	analyzer, mockTransport := createTestAnalyzer()
+	analyzer.config.MaxLinksPerPage = 3
+
+	body := `<!DOCTYPE html><html><head><title>Progress</title></head><body>`
+	for i := 0; i < 5; i++ {
+		body += fmt.Sprintf(`<a href="https://progress%d.com">Link %d</a>`, i, i)
+	}
+	body += `</body></html>`
+	mockTransport.responses["https://progress.com"] = &MockResponse{StatusCode: 200, Body: body}
+
+	var stages []string
+	var last models.AnalysisProgress
+	events := make(map[string]int)
+	ctx := WithProgress(context.Background(), func(event models.AnalysisEvent) {
+		if event.Type == models.EventStage {
+			stages = append(stages, event.Progress.Stage)
+		}
+		events[event.Type]++
+		last = event.Progress
+	})
+
+	analyzer.Analyze(ctx, "https://progress.com")
+
+	expectedStages := []string{models.StageFetching, models.StageParsing, models.StageCheckingLinks, models.StageCompleted}
+	if strings.Join(stages, ",") != strings.Join(expectedStages, ",") {
+		t.Errorf("Expected stages %v, got %v", expectedStages, stages)
+	}
+	if last.LinksFound != 5 || last.LinksChecked != 3 || last.LinksSkipped != 2 {
+		t.Errorf("Expected 5 found, 3 checked and 2 skipped links, got %+v", last)
+	}
+	if events[models.EventLink] != 3 || events[models.EventSkippedLink] != 2 {
+		t.Errorf("Expected 3 link and 2 skipped link events, got %v", events)
+	}
+}
+
+// TestLinkLimit tests that links beyond the per-page budget are reported as skipped
+func TestLinkLimit(t *testing.T) {
+	analyzer, mockTransport := createTestAnalyzer()
+	analyzer.config.MaxLinksPerPage = 3
+
+	body := `<!DOCTYPE html><html><head><title>Limit</title></head><body>`
+	for i := 0; i < 5; i++ {
+		body += fmt.Sprintf(`<a href="https://limit%d.com">Link %d</a>`, i, i)
+	}
+	body += `</body></html>`
+	mockTransport.responses["https://linklimit.com"] = &MockResponse{StatusCode: 200, Body: body}
+
+	result := analyzer.Analyze(context.Background(), "https://linklimit.com")
+
+	if len(result.Links) != 3 {
+		t.Errorf("Expected 3 checked links, got %d", len(result.Links))
+	}
+	if len(result.SkippedLinks) != 2 {
+		t.Fatalf("Expected 2 skipped links, got %d", len(result.SkippedLinks))
+	}
+	for _, link := range result.SkippedLinks {
+		if link.Reason != models.SkipReasonLinkLimit {
+			t.Errorf("Expected skip reason %s, got %s", models.SkipReasonLinkLimit, link.Reason)
+		}
+	}
+	if result.LinkCheckComplete {
+		t.Error("Expected link check to be reported incomplete")
+	}
+	if result.ExternalLinks != 5 {
+		t.Errorf("Expected 5 external links, got %d", result.ExternalLinks)
+	}
+}
+
+// TestCancelledLinkCheck tests that links left over after cancellation are reported instead of silently lost
+func TestCancelledLinkCheck(t *testing.T) {
+	analyzer, _ := createTestAnalyzer()
+
+	const totalLinks = 2 * linkQueueSize
+	body := `<!DOCTYPE html><html><head><title>Cancel</title></head><body>`
+	for i := 0; i < totalLinks; i++ {
+		body += fmt.Sprintf(`<a href="https://hang%d.com">Link %d</a>`, i, i)
+	}
+	body += `</body></html>`
+
+	analyzer.client.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if req.URL.Host == "cancel.com" {
+			return &http.Response{StatusCode: 200, Body: io.NopCloser(strings.NewReader(body)), Request: req}, nil
+		}
+		// Link checks hang until the analysis is cancelled
+		<-req.Context().Done()
+		return nil, req.Context().Err()
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	result := analyzer.Analyze(ctx, "https://cancel.com")
+
+	if len(result.Links)+len(result.SkippedLinks) != totalLinks {
+		t.Errorf("Expected %d links to be accounted for, got %d checked and %d skipped", totalLinks, len(result.Links), len(result.SkippedLinks))
+	}
+	if len(result.SkippedLinks) == 0 {
+		t.Error("Expected skipped links after cancellation")
+	}
+	// Checks interrupted by the cancellation are skipped rather than reported as broken
+	if len(result.Links) != 0 {
+		t.Errorf("Expected no checked links, got %d", len(result.Links))
+	}
+	for _, link := range result.SkippedLinks {
+		if link.Reason != models.SkipReasonCancelled {
+			t.Errorf("Expected skip reason %s, got %s", models.SkipReasonCancelled, link.Reason)
+		}
+	}
+}
+
+// TestLinkDeduplicationAndCache tests that repeated links are checked once and that results are reused across analyses
+func TestLinkDeduplicationAndCache(t *testing.T) {
+	analyzer, _ := createTestAnalyzer()
+	analyzer.linkCache = newLinkStatusCache(time.Minute, 100)
+
+	body := `<!DOCTYPE html><html><head><title>Repeated</title></head><body>`
+	for i := 0; i < 20; i++ {
+		body += `<a href="https://popular.com/page">Popular</a><a href="https://POPULAR.com/page#section">Popular section</a>`
+	}
+	body += `<a href="https://flaky.com">Flaky</a></body></html>`
+
+	var mu sync.Mutex
+	requests := make(map[string]int)
+	analyzer.client.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if req.URL.Host == "repeated.com" {
+			return &http.Response{StatusCode: 200, Body: io.NopCloser(strings.NewReader(body)), Request: req}, nil
+		}
+		mu.Lock()
+		requests[req.URL.Host]++
+		mu.Unlock()
+		if req.URL.Host == "flaky.com" {
+			return nil, &net.DNSError{Err: "server misbehaving", Name: "flaky.com", IsTemporary: true}
+		}
+		return &http.Response{StatusCode: 200, Body: io.NopCloser(strings.NewReader("")), Request: req}, nil
+	})
+
+	first := analyzer.Analyze(context.Background(), "https://repeated.com")
+
+	if len(first.Links) != 2 {
+		t.Fatalf("Expected 2 unique checked links, got %d", len(first.Links))
+	}
+	if requests["popular.com"] != 1 {
+		t.Errorf("Expected 1 request for the repeated link, got %d", requests["popular.com"])
+	}
+	for _, link := range first.Links {
+		if link.URL == "https://popular.com/page" && link.Occurrences != 40 {
+			t.Errorf("Expected 40 occurrences, got %d", link.Occurrences)
+		}
+		if link.Cached {
+			t.Errorf("Expected fresh result for %s on first analysis", link.URL)
+		}
+	}
+
+	second := analyzer.Analyze(context.Background(), "https://repeated.com")
+
+	if requests["popular.com"] != 1 {
+		t.Errorf("Expected cached result to be reused, got %d requests", requests["popular.com"])
+	}
+	if requests["flaky.com"] != 2 {
+		t.Errorf("Expected network errors not to be cached, got %d requests", requests["flaky.com"])
+	}
+	for _, link := range second.Links {
+		if link.URL == "https://popular.com/page" && !link.Cached {
+			t.Error("Expected cached result on second analysis")
+		}
+		if link.URL == "https://flaky.com" && link.Cached {
+			t.Error("Expected failed lookup not to be cached")
+		}
+	}
+}
+
+// TestLinkStatusCache tests expiry and eviction of the link status cache
+func TestLinkStatusCache(t *testing.T) {
+	if newLinkStatusCache(0, 10) != nil || newLinkStatusCache(time.Minute, 0) != nil {
+		t.Error("Expected cache to be disabled without TTL or size")
+	}
+
+	cache := newLinkStatusCache(time.Minute, 2)
+	cache.put("https://a.com", LinkCheckResult{URL: "https://a.com", StatusCode: 200, CheckedAt: time.Now()})
+	cache.put("https://b.com", LinkCheckResult{URL: "https://b.com", StatusCode: 200, CheckedAt: time.Now()})
+	cache.get("https://a.com")
+	cache.put("https://c.com", LinkCheckResult{URL: "https://c.com", StatusCode: 200, CheckedAt: time.Now()})
+
+	if _, ok := cache.get("https://b.com"); ok {
+		t.Error("Expected least recently used entry to be evicted")
+	}
+	if _, ok := cache.get("https://A.com/#top"); !ok {
+		t.Error("Expected normalized URL to hit the cache")
+	}
+
+	cache.put("https://old.com", LinkCheckResult{URL: "https://old.com", StatusCode: 200, CheckedAt: time.Now().Add(-2 * time.Minute)})
+	if _, ok := cache.get("https://old.com"); ok {
+		t.Error("Expected expired entry to be ignored")
+	}
+}
+
+// TestHostLimiter tests per-host concurrency caps and request rates
+func TestHostLimiter(t *testing.T) {
+	if newHostLimiter(0, 0) != nil {
+		t.Error("Expected limiter to be disabled without limits")
+	}
+
+	limiter := newHostLimiter(2, 0)
+	var mu sync.Mutex
+	inFlight, maxInFlight := 0, 0
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			release, err := limiter.acquire(context.Background(), "busy.com")
+			if err != nil {
+				t.Errorf("Unexpected error: %v", err)
+				return
+			}
+			mu.Lock()
+			inFlight++
+			maxInFlight = max(maxInFlight, inFlight)
+			mu.Unlock()
+			time.Sleep(10 * time.Millisecond)
+			mu.Lock()
+			inFlight--
+			mu.Unlock()
+			release()
+		}()
+	}
+	wg.Wait()
+	if maxInFlight > 2 {
+		t.Errorf("Expected at most 2 concurrent requests per host, got %d", maxInFlight)
+	}
+
+	limiter = newHostLimiter(0, 50)
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		release, err := limiter.acquire(context.Background(), "rated.com")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		release()
+	}
+	if elapsed := time.Since(start); elapsed < 80*time.Millisecond {
+		t.Errorf("Expected 5 requests at 50/s to take at least 80ms, took %v", elapsed)
+	}
+
+	// Other hosts are not held back by a busy host
+	limiter.backoff("rated.com", time.Hour)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := limiter.acquire(ctx, "other.com"); err != nil {
+		t.Errorf("Expected other host to be available, got %v", err)
+	}
+	if _, err := limiter.acquire(ctx, "rated.com"); err == nil {
+		t.Error("Expected backed off host to wait past the deadline")
+	}
+}
+
+// TestRetryAfter tests that throttled link checks are retried and reported honestly
+func TestRetryAfter(t *testing.T) {
+	analyzer, _ := createTestAnalyzer()
+	analyzer.config.LinkMaxRetries = 2
+	analyzer.config.MaxRetryAfterInSeconds = 5
+
+	var mu sync.Mutex
+	attempts := make(map[string]int)
+	analyzer.client.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		mu.Lock()
+		attempts[req.URL.Path]++
+		attempt := attempts[req.URL.Path]
+		mu.Unlock()
+
+		resp := &http.Response{StatusCode: 200, Header: make(http.Header), Body: io.NopCloser(strings.NewReader("")), Request: req}
+		switch req.URL.Path {
+		case "/recovers":
+			if attempt == 1 {
+				resp.StatusCode = 429
+				resp.Header.Set("Retry-After", "0")
+			}
+		case "/throttled":
+			resp.StatusCode = 429
+			resp.Header.Set("Retry-After", "0")
+		case "/too-long":
+			resp.StatusCode = 503
+			resp.Header.Set("Retry-After", "3600")
+		}
+		return resp, nil
+	})
+
+	recovered := analyzer.checkSingleLink(context.Background(), "https://throttle.com/recovers")
+	if !recovered.IsAccessible || attempts["/recovers"] != 2 {
+		t.Errorf("Expected link to recover on retry, accessible=%v attempts=%d", recovered.IsAccessible, attempts["/recovers"])
+	}
+
+	throttled := analyzer.checkSingleLink(context.Background(), "https://throttle.com/throttled")
+	if throttled.SkipReason != models.SkipReasonRateLimited {
+		t.Errorf("Expected skip reason %s, got '%s'", models.SkipReasonRateLimited, throttled.SkipReason)
+	}
+	if attempts["/throttled"] != 3 {
+		t.Errorf("Expected 3 attempts, got %d", attempts["/throttled"])
+	}
+
+	tooLong := analyzer.checkSingleLink(context.Background(), "https://throttle.com/too-long")
+	if tooLong.IsAccessible || tooLong.StatusCode != 503 || attempts["/too-long"] != 1 {
+		t.Errorf("Expected no retry beyond max Retry-After, status=%d attempts=%d", tooLong.StatusCode, attempts["/too-long"])
+	}
+}
+
+// TestRetryAfter_LongerThanTimeout tests that waiting for a Retry-After longer than the link check timeout does
+// not fail the link, the timeout only bounds each request
+func TestRetryAfter_LongerThanTimeout(t *testing.T) {
+	analyzer, _ := createTestAnalyzer()
+	analyzer.config.LinkMaxRetries = 1
+	analyzer.config.MaxRetryAfterInSeconds = 5
+	urlValidator, err := validator.NewURLValidatorWithPolicies(&env.Config{
+		DomainPolicies: `[{"pattern": "throttle.com", "timeout_seconds": 1}]`,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	analyzer.validator = urlValidator
+
+	var attempts atomic.Int32
+	analyzer.client.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		resp := &http.Response{StatusCode: 200, Header: make(http.Header), Body: io.NopCloser(strings.NewReader("")), Request: req}
+		if attempts.Add(1) == 1 {
+			resp.StatusCode = 429
+			resp.Header.Set("Retry-After", "2")
+		}
+		return resp, nil
+	})
+
+	result := analyzer.checkSingleLink(context.Background(), "https://throttle.com/slow-down")
+	if !result.IsAccessible || result.Error != nil || attempts.Load() != 2 {
+		t.Errorf("Expected the link to be accessible after the Retry-After wait, accessible=%v error=%v attempts=%d",
+			result.IsAccessible, result.Error, attempts.Load())
+	}
+}
+
+// TestLinkCheckPoolFairness tests that a small analysis is not stuck behind a large one on the shared pool
+func TestLinkCheckPoolFairness(t *testing.T) {
+	var order []string
+	var mu sync.Mutex
+	release := make(chan struct{})
+	pool := newLinkCheckPool(1, func(ctx context.Context, target linkTarget) LinkCheckResult {
+		<-release
+		mu.Lock()
+		order = append(order, target.URL)
+		mu.Unlock()
+		return LinkCheckResult{URL: target.URL, IsAccessible: true}
+	})
+	defer pool.close()
+
+	large := pool.register(context.Background())
+	small := pool.register(context.Background())
+	largeResults, smallResults := large.results, small.results
+
+	for i := 0; i < 20; i++ {
+		if err := large.submit(linkTarget{URL: fmt.Sprintf("https://large.com/%d", i)}); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+	for i := 0; i < 2; i++ {
+		if err := small.submit(linkTarget{URL: fmt.Sprintf("https://small.com/%d", i)}); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+	large.finish()
+	small.finish()
+	close(release)
+
+	for range largeResults {
+	}
+	for range smallResults {
+	}
+
+	// The small analysis gets every other worker turn, so it finishes within the first few checks
+	lastSmall := -1
+	for i, linkURL := range order {
+		if strings.HasPrefix(linkURL, "https://small.com") {
+			lastSmall = i
+		}
+	}
+	if lastSmall < 0 || lastSmall > 4 {
+		t.Errorf("Expected the small analysis to finish within the first 5 checks, got order %v", order)
+	}
+
+	stats := pool.stats()
+	if stats.CompletedChecks != 22 {
+		t.Errorf("Expected 22 completed checks, got %d", stats.CompletedChecks)
+	}
+	if stats.ActiveAnalyses != 0 {
+		t.Errorf("Expected no active analyses, got %d", stats.ActiveAnalyses)
+	}
+}
+
+// TestLinkCheckPoolCancel tests that cancelling an analysis removes its pending work from the shared pool
+func TestLinkCheckPoolCancel(t *testing.T) {
+	started := make(chan struct{}, 1)
+	pool := newLinkCheckPool(1, func(ctx context.Context, target linkTarget) LinkCheckResult {
+		started <- struct{}{}
+		<-ctx.Done()
+		return LinkCheckResult{URL: target.URL, Error: ctx.Err()}
+	})
+	defer pool.close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	session := pool.register(ctx)
+	results := session.results
+
+	for i := 0; i < 10; i++ {
+		if err := session.submit(linkTarget{URL: fmt.Sprintf("https://example.com/%d", i)}); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+	<-started
+
+	if stats := pool.stats(); stats.BusyWorkers != 1 || stats.QueuedLinks != 9 {
+		t.Errorf("Expected 1 busy worker and 9 queued links, got %d and %d", stats.BusyWorkers, stats.QueuedLinks)
+	}
+
+	cancel()
+	if err := session.submit(linkTarget{URL: "https://example.com/late"}); err == nil {
+		t.Error("Expected submit to fail after cancellation")
+	}
+	session.finish()
+
+	skipped := 0
+	count := 0
+	for result := range results {
+		count++
+		if result.SkipReason == models.SkipReasonCancelled {
+			skipped++
+		}
+	}
+
+	if count != 10 {
+		t.Errorf("Expected 10 results, got %d", count)
+	}
+	if skipped != 9 {
+		t.Errorf("Expected 9 cancelled links, got %d", skipped)
+	}
+	if stats := pool.stats(); stats.QueuedLinks != 0 || stats.CancelledChecks != 9 {
+		t.Errorf("Expected no queued links and 9 cancelled checks, got %d and %d", stats.QueuedLinks, stats.CancelledChecks)
+	}
+}
+
+// TestAnalyzeDirectory tests that a static site build is analyzed offline, with internal links looked up in its files
+func TestAnalyzeDirectory(t *testing.T) {
+	analyzer, _ := createTestAnalyzer()
+	analyzer.config.LinkCheckScope = LinkCheckScopeAll
+	analyzer.client.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		t.Errorf("Expected no request while analyzing offline, got %s", req.URL)
+		return nil, fmt.Errorf("offline")
+	})
+
+	root := t.TempDir()
+	files := map[string]string{
+		"index.html": `<!DOCTYPE html><html><head><title>Home</title></head><body>
+			<a href="/about/">About</a>
+			<a href="docs">Docs</a>
+			<a href="/contact">Contact</a>
+			<a href="/team/">Team</a>
+			<a href="https://external.com/page">External</a>
+		</body></html>`,
+		"about/index.html": `<html><head><title>About</title></head><body>
+			<a href="../">Home</a>
+			<a href="../team/">Team</a>
+			<a href="#history">History</a>
+		</body></html>`,
+		"docs.html":    `<html><head><title>Docs</title></head><body></body></html>`,
+		"contact.html": `<html><head><title>Contact</title></head><body><a href="/about">About</a></body></html>`,
+		"style.css":    `body {}`,
+		".git/x.html":  `<html></html>`,
+	}
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	report := analyzer.AnalyzeDirectory(context.Background(), root, "https://example.com", 0)
+
+	if report.Error != "" {
+		t.Fatalf("Expected no error, got %s", report.Error)
+	}
+	if report.PagesAnalyzed != 4 {
+		t.Fatalf("Expected the 4 HTML pages outside hidden directories, got %d", report.PagesAnalyzed)
+	}
+
+	pages := make(map[string]models.SitePage)
+	for _, page := range report.Pages {
+		pages[page.URL] = page
+	}
+	home, ok := pages["https://example.com/"]
+	if !ok || home.File != "index.html" || home.Result.PageTitle != "Home" {
+		t.Fatalf("Expected index.html to be served at the base URL, got %+v", report.Pages)
+	}
+	if home.Result.InternalLinks != 4 || home.Result.InaccessibleInternalLinks != 1 {
+		t.Errorf("Expected 4 internal links with 1 missing, got %+v", home.Result.Links)
+	}
+	if len(home.Result.SkippedLinks) != 1 || home.Result.SkippedLinks[0].Reason != models.SkipReasonOffline {
+		t.Errorf("Expected the external link to be skipped offline, got %+v", home.Result.SkippedLinks)
+	}
+	if about := pages["https://example.com/about/"]; about.Result == nil || about.Result.InaccessibleLinks != 1 {
+		t.Errorf("Expected the about page to have 1 missing link, got %+v", about)
+	}
+	if contact := pages["https://example.com/contact.html"]; contact.Result == nil || contact.Result.InaccessibleLinks != 0 {
+		t.Errorf("Expected /about to be served by about/index.html, got %+v", contact)
+	}
+
+	if len(report.MissingFiles) != 1 || report.MissingFiles[0].URL != "https://example.com/team/" || len(report.MissingFiles[0].LinkedFrom) != 2 {
+		t.Errorf("Expected /team/ to be missing and linked from 2 pages, got %+v", report.MissingFiles)
+	}
+
+	if limited := analyzer.AnalyzeDirectory(context.Background(), root, "https://example.com", 2); limited.PagesAnalyzed != 2 || !limited.BudgetExhausted {
+		t.Errorf("Expected the page limit to stop the analysis, got %d pages", limited.PagesAnalyzed)
+	}
+	if missing := analyzer.AnalyzeDirectory(context.Background(), filepath.Join(root, "missing"), "https://example.com", 0); missing.Error == "" {
+		t.Error("Expected an error for a missing directory")
+	}
+}
+
+// TestStaticSiteServes tests how links are mapped to the files of a site served below a base path
+func TestStaticSiteServes(t *testing.T) {
+	base, _ := url.Parse("https://example.com/docs/")
+	site := &staticSite{
+		files: fstest.MapFS{
+			"index.html":      {Data: []byte("home")},
+			"guide.html":      {Data: []byte("guide")},
+			"api/index.html":  {Data: []byte("api")},
+			"assets/logo.png": {Data: []byte("png")},
+		},
+		base: base,
+	}
+
+	tests := []struct {
+		link   string
+		found  bool
+		inSite bool
+	}{
+		{"https://example.com/docs/", true, true},
+		{"https://example.com/docs", true, true},
+		{"https://example.com/docs/guide", true, true},
+		{"https://example.com/docs/guide.html", true, true},
+		{"https://example.com/docs/api", true, true},
+		{"https://example.com/docs/api/", true, true},
+		{"https://example.com/docs/assets/logo.png", true, true},
+		{"https://example.com/docs/guide/", false, true},
+		{"https://example.com/docs/missing", false, true},
+		{"https://example.com/blog/", false, false},
+		{"https://example.com/docsx/", false, false},
+	}
+
+	for _, tt := range tests {
+		found, inSite := site.serves(tt.link)
+		if found != tt.found || inSite != tt.inSite {
+			t.Errorf("serves(%s) = %v, %v, expected %v, %v", tt.link, found, inSite, tt.found, tt.inSite)
+		}
+	}
+}
+
+// TestBlockedAddresses tests that the analyzer refuses to connect to loopback addresses unless they are allowlisted
+func TestBlockedAddresses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	config := &env.Config{LogLevel: "debug", NumOfWorkers: 1}
+	analyzer := NewPageAnalyzer(logger.NewLogger(*config), config)
+	defer analyzer.Close()
+
+	result := analyzer.checkSingleLink(context.Background(), server.URL+"/internal")
+	if result.IsAccessible || classifyLinkError(result.Error) != models.LinkErrorBlocked {
+		t.Errorf("Expected the loopback link to be blocked, got %+v", result)
+	}
+
+	config = &env.Config{LogLevel: "debug", NumOfWorkers: 1, SSRFAllowlist: "127.0.0.0/8, ::1"}
+	analyzer = NewPageAnalyzer(logger.NewLogger(*config), config)
+	defer analyzer.Close()
+
+	result = analyzer.checkSingleLink(context.Background(), server.URL+"/internal")
+	if !result.IsAccessible {
+		t.Errorf("Expected the allowlisted link to be accessible, got %+v", result)
+	}
+}
+
+// TestDomainPolicies tests that denied targets are refused, that links to denied hosts or hosts whose policy turns
+// link checks off are skipped, and that the user agent of a policy is sent
+func TestDomainPolicies(t *testing.T) {
+	analyzer, _ := createTestAnalyzer()
+	analyzer.config.LinkCheckScope = LinkCheckScopeAll
+	urlValidator, err := validator.NewURLValidatorWithPolicies(&env.Config{
+		DomainDenylist: "*.internal.example.com",
+		DomainPolicies: `[{"pattern": "docs.example.com", "user_agent": "DocsBot/1.0", "timeout_seconds": 5},
+			{"pattern": "*.partner.com", "check_links": false}]`,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	analyzer.validator = urlValidator
+
+	var mu sync.Mutex
+	userAgents := make(map[string]string)
+	analyzer.client.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		mu.Lock()
+		userAgents[req.URL.Host] = req.Header.Get("User-Agent")
+		mu.Unlock()
+
+		body := ""
+		if req.URL.Path == "/guide" {
+			body = `<html><head><title>Guide</title></head><body>
+    <a href="https://example.com/about">About</a>
+    <a href="https://db.internal.example.com/admin">Admin</a>
+    <a href="https://shop.partner.com/">Partner</a>
+    <a href="https://shop.partner.com/">Partner again</a>
+</body></html>`
+		}
+		return &http.Response{
+			StatusCode: 200,
+			Body:       io.NopCloser(strings.NewReader(body)),
+			Header:     http.Header{"Content-Type": []string{"text/html"}},
+			Request:    req,
+		}, nil
+	})
+
+	result := analyzer.Analyze(context.Background(), "https://docs.example.com/guide")
+	if !result.IsSuccessful() {
+		t.Fatalf("Expected the analysis to succeed, got %s", result.Error)
+	}
+	if len(result.Links) != 1 || result.Links[0].URL != "https://example.com/about" {
+		t.Errorf("Expected only the allowed link to be checked, got %+v", result.Links)
+	}
+	if len(result.SkippedLinks) != 2 {
+		t.Fatalf("Expected 2 skipped links, got %+v", result.SkippedLinks)
+	}
+	for _, skipped := range result.SkippedLinks {
+		if skipped.Reason != models.SkipReasonDomainPolicy {
+			t.Errorf("Expected %s to be skipped by the domain policy, got %s", skipped.URL, skipped.Reason)
+		}
+	}
+	if userAgents["docs.example.com"] != "DocsBot/1.0" || userAgents["example.com"] != defaultUserAgent {
+		t.Errorf("Expected the user agent of the policy for docs.example.com only, got %v", userAgents)
+	}
+
+	result = analyzer.Analyze(context.Background(), "https://db.internal.example.com")
+	if result.IsSuccessful() || !strings.Contains(result.Error, validator.ErrDomainDenied.Error()) {
+		t.Errorf("Expected a denied target to be refused, got %q", result.Error)
+	}
+}
+
+// TestAnalysisMetrics tests that an analysis records its outcome, its page fetch, its link checks and its skipped
+// links, and that analyses of sent HTML and of static sites are recorded by source
+func TestAnalysisMetrics(t *testing.T) {
+	analyzer, mockTransport := createTestAnalyzer()
+	analyzer.config.LinkCheckScope = LinkCheckScopeAll
+	analyzer.config.MaxLinksPerPage = 1
+	m := metrics.New(analyzer.PoolStats)
+	analyzer.SetMetrics(m)
+
+	mockTransport.responses["https://example.com"] = &MockResponse{
+		StatusCode: 200,
+		Body:       `<html><body><a href="/about">About</a><a href="/contact">Contact</a></body></html>`,
+	}
+	mockTransport.responses["https://example.com/about"] = &MockResponse{StatusCode: 200}
+
+	result := analyzer.Analyze(context.Background(), "https://example.com")
+	if !result.IsSuccessful() {
+		t.Fatalf("Expected the analysis to succeed, got %s", result.Error)
+	}
+	analyzer.AnalyzeHTML(context.Background(), "https://example.com", strings.NewReader(`<html><title>Snapshot</title></html>`))
+	site := t.TempDir()
+	if err := os.WriteFile(filepath.Join(site, "index.html"), []byte(`<html><title>Home</title></html>`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	analyzer.AnalyzeDirectory(context.Background(), site, "https://example.com", 0)
+
+	recorder := httptest.NewRecorder()
+	m.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body := recorder.Body.String()
+	for _, line := range []string{
+		`web_analyzer_analyses_total{outcome="success",source="fetch",status_code="200"} 1`,
+		`web_analyzer_analyses_total{outcome="success",source="html",status_code="200"} 1`,
+		`web_analyzer_analyses_total{outcome="success",source="static_site",status_code="200"} 1`,
+		`web_analyzer_page_fetch_duration_seconds_count{outcome="success"} 1`,
+		`web_analyzer_link_checks_total{cached="false",error_class="",outcome="accessible"} 1`,
+		`web_analyzer_links_skipped_total{reason="link_limit"} 1`,
+		`web_analyzer_link_check_pool_workers 5`,
+	} {
+		if !strings.Contains(body, line) {
+			t.Errorf("Expected the metrics to contain %q", line)
+		}
+	}
+}
+
+// TestTracing tests that the stages of an analysis are traced as one trace and that, once enabled, the trace
+// context but not the baggage is sent with the requests for the page and its links
+func TestTracing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	defer otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator())
+
+	analyzer, _ := createTestAnalyzer()
+	analyzer.config.LinkCheckScope = LinkCheckScopeAll
+	analyzer.config.TracingPropagateOutbound = true
+
+	var mu sync.Mutex
+	traceParents := make(map[string]string)
+	var baggageSent bool
+	analyzer.client.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		mu.Lock()
+		traceParents[req.URL.Path] = req.Header.Get("traceparent")
+		baggageSent = baggageSent || req.Header.Get("baggage") != ""
+		mu.Unlock()
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(strings.NewReader(`<html><body><a href="/about">About</a></body></html>`)),
+			Request:    req,
+		}, nil
+	})
+
+	member, _ := baggage.NewMember("user", "alice")
+	bag, _ := baggage.New(member)
+	result := analyzer.Analyze(baggage.ContextWithBaggage(context.Background(), bag), "https://example.com/")
+	if !result.IsSuccessful() {
+		t.Fatalf("Expected the analysis to succeed, got %s", result.Error)
+	}
+
+	spans := make(map[string]sdktrace.ReadOnlySpan)
+	for _, span := range recorder.Ended() {
+		spans[span.Name()] = span
+	}
+	root, ok := spans["analyze"]
+	if !ok {
+		t.Fatalf("Expected an analyze span, got %v", spans)
+	}
+	traceID := root.SpanContext().TraceID()
+	for _, name := range []string{"fetchPage", "parseHTML", "analyzeHTML", "checkSingleLink"} {
+		span, ok := spans[name]
+		if !ok {
+			t.Errorf("Expected a %s span", name)
+			continue
+		}
+		if span.Parent().SpanID() != root.SpanContext().SpanID() {
+			t.Errorf("Expected the %s span to be a child of the analyze span", name)
+		}
+	}
+
+	for _, path := range []string{"/", "/about"} {
+		if !strings.Contains(traceParents[path], traceID.String()) {
+			t.Errorf("Expected the request for %s to carry trace %s, got %q", path, traceID, traceParents[path])
+		}
+	}
+	if baggageSent {
+		t.Error("Expected no baggage to be sent to the analyzed site")
+	}
+
+	// Off by default, the analyzed site learns nothing of the trace
+	analyzer.config.TracingPropagateOutbound = false
+	traceParents = make(map[string]string)
+	analyzer.Analyze(context.Background(), "https://example.com/")
+	for path, traceParent := range traceParents {
+		if traceParent != "" {
+			t.Errorf("Expected no trace context on the request for %s, got %q", path, traceParent)
+		}
+	}
+}
+
+// TestTracingWithoutFetch tests that the analyses of sent HTML and of static sites have a root span the stages
+// of the pages and their link checks nest under
+func TestTracingWithoutFetch(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	analyzer, _ := createTestAnalyzer()
+	analyzer.config.LinkCheckScope = LinkCheckScopeAll
+	analyzer.client.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader("")), Request: req}, nil
+	})
+
+	// byParent maps the name of every span to the name of its parent
+	byParent := func() map[string]string {
+		ended := recorder.Ended()
+		names := make(map[trace.SpanID]string)
+		for _, span := range ended {
+			names[span.SpanContext().SpanID()] = span.Name()
+		}
+		parents := make(map[string]string)
+		for _, span := range ended {
+			parents[span.Name()] = names[span.Parent().SpanID()]
+		}
+		return parents
+	}
+
+	result := analyzer.AnalyzeHTML(context.Background(), "https://example.com/", strings.NewReader(`<html><body><a href="/about">About</a></body></html>`))
+	if !result.IsSuccessful() {
+		t.Fatalf("Expected the analysis to succeed, got %s", result.Error)
+	}
+	parents := byParent()
+	if parent, ok := parents["analyze"]; !ok || parent != "" {
+		t.Fatalf("Expected a root analyze span, got %v", parents)
+	}
+	for _, name := range []string{"parseHTML", "analyzeHTML", "checkSingleLink"} {
+		if parents[name] != "analyze" {
+			t.Errorf("Expected the %s span to be a child of the analyze span, got %v", name, parents)
+		}
+	}
+
+	recorder = tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	site := t.TempDir()
+	if err := os.WriteFile(filepath.Join(site, "index.html"), []byte(`<html><title>Home</title></html>`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	analyzer.AnalyzeDirectory(context.Background(), site, "https://example.com", 0)
+	parents = byParent()
+	if parent, ok := parents["analyze"]; !ok || parent != "" {
+		t.Fatalf("Expected a root analyze span, got %v", parents)
+	}
+	if parents["analyzeFile"] != "analyze" || parents["parseHTML"] != "analyzeFile" || parents["analyzeHTML"] != "analyzeFile" {
+		t.Errorf("Expected the pages to be analyzed under the analyze span, got %v", parents)
+	}
+}
+
+// TestFetchTiming tests that the fetch of the page and the check of each link are broken down into phases, and
+// that links answered from the cache have no breakdown
+func TestFetchTiming(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(20 * time.Millisecond)
+		if r.URL.Path != "/" {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(`<html><body><a href="/about">About</a>`))
+		w.(http.Flusher).Flush()
+		time.Sleep(20 * time.Millisecond)
+		w.Write([]byte(`</body></html>`))
+	}))
+	defer server.Close()
+
+	analyzer, _ := createTestAnalyzer()
+	analyzer.config.LinkCheckScope = LinkCheckScopeAll
+	analyzer.linkCache = newLinkStatusCache(time.Minute, 10)
+
+	// Requests for example.com reach the test server through localhost, so the host name is resolved
+	_, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+	transport := server.Client().Transport.(*http.Transport).Clone()
+	transport.DialContext = func(ctx context.Context, network, _ string) (net.Conn, error) {
+		return (&net.Dialer{}).DialContext(ctx, network, net.JoinHostPort("localhost", port))
+	}
+	analyzer.client.Transport = transport
+
+	result := analyzer.Analyze(context.Background(), "https://example.com/")
+	if !result.IsSuccessful() {
+		t.Fatalf("Expected the analysis to succeed, got %s", result.Error)
+	}
+
+	timing := result.FetchTiming
+	if timing == nil {
+		t.Fatal("Expected a breakdown of the page fetch")
+	}
+	if timing.TCPConnectMs <= 0 || timing.TLSHandshakeMs <= 0 || timing.ConnectionReused {
+		t.Errorf("Expected a new connection with a TLS handshake, got %+v", timing)
+	}
+	if timing.TimeToFirstByteMs < 20 || timing.ContentDownloadMs < 20 {
+		t.Errorf("Expected the server delays in the time to first byte and the download, got %+v", timing)
+	}
+	phases := timing.DNSLookupMs + timing.TCPConnectMs + timing.TLSHandshakeMs + timing.TimeToFirstByteMs + timing.ContentDownloadMs
+	if timing.TotalMs < phases {
+		t.Errorf("Expected the total to cover the phases, got %+v", timing)
+	}
+
+	if len(result.Links) != 1 || result.Links[0].Timing == nil {
+		t.Fatalf("Expected a breakdown of the link check, got %+v", result.Links)
+	}
+	if linkTiming := result.Links[0].Timing; linkTiming.TimeToFirstByteMs < 20 || linkTiming.TotalMs < linkTiming.TimeToFirstByteMs {
+		t.Errorf("Expected the server delay in the time to first byte of the link, got %+v", linkTiming)
+	}
+
+	result = analyzer.Analyze(context.Background(), "https://example.com/")
+	if len(result.Links) != 1 || !result.Links[0].Cached || result.Links[0].Timing != nil {
+		t.Errorf("Expected the cached link check to have no breakdown, got %+v", result.Links)
+	}
+}
+
+// eofBody records when it has been read to the end
+type eofBody struct {
+	io.Reader
+	eofAt time.Time
+}
+
+func (b *eofBody) Read(p []byte) (int, error) {
+	n, err := b.Reader.Read(p)
+	if err == io.EOF && b.eofAt.IsZero() {
+		b.eofAt = time.Now()
+	}
+	return n, err
+}
+
+func (b *eofBody) Close() error {
+	return nil
+}
+
+// TestFetchTiming_ExcludesParsing tests that the page is downloaded before it is parsed, so its download time
+// does not include the parsing
+func TestFetchTiming_ExcludesParsing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	analyzer, _ := createTestAnalyzer()
+	body := &eofBody{Reader: strings.NewReader(`<html><head><title>Example</title></head></html>`)}
+	analyzer.client.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Body: body, Request: req}, nil
+	})
+
+	result := analyzer.Analyze(context.Background(), "https://example.com/")
+	if !result.IsSuccessful() || result.PageTitle != "Example" {
+		t.Fatalf("Expected the analysis to succeed, got %+v", result)
+	}
+
+	for _, span := range recorder.Ended() {
+		if span.Name() != "parseHTML" {
+			continue
+		}
+		if body.eofAt.IsZero() || span.StartTime().Before(body.eofAt) {
+			t.Errorf("Expected the body to be read to the end before parsing, read at %v, parsed at %v", body.eofAt, span.StartTime())
+		}
+		return
+	}
+	t.Error("Expected a parseHTML span")
+}
+
+// TestAnalyze_PageTooLarge tests that pages larger than PAGE_MAX_BYTES are reported rather than read in full
+func TestAnalyze_PageTooLarge(t *testing.T) {
+	analyzer, _ := createTestAnalyzer()
+	analyzer.config.PageMaxBytes = 64
+	page := `<html><head><title>Example</title></head><body>` + strings.Repeat("<p>text</p>", 100) + `</body></html>`
+	analyzer.client.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(page)), Request: req}, nil
+	})
+
+	result := analyzer.Analyze(context.Background(), "https://example.com/")
+	if result.IsSuccessful() || !strings.Contains(result.Error, "larger than 64 bytes") {
+		t.Errorf("Expected the page to be rejected as too large, got %+v", result)
+	}
+
+	analyzer.config.PageMaxBytes = int64(len(page))
+	if result := analyzer.Analyze(context.Background(), "https://example.com/"); !result.IsSuccessful() {
+		t.Errorf("Expected a page of exactly the limit to be analyzed, got %s", result.Error)
+	}
+}
@@ -0,0 +1,153 @@
+package analyzer
+
+import (
+	"WebAppAnalyzer/internal/models"
+	"context"
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+)
+
+const (
+	defaultCrawlMaxDepth = 2
+	defaultCrawlMaxPages = 50
+)
+
+// nonHTMLExtensions are file extensions of links that are not followed by a crawl, as they are hardly ever pages
+var nonHTMLExtensions = map[string]bool{
+	".pdf": true, ".doc": true, ".docx": true, ".xls": true, ".xlsx": true, ".ppt": true, ".pptx": true,
+	".csv": true, ".txt": true, ".json": true, ".xml": true, ".rss": true,
+	".jpg": true, ".jpeg": true, ".png": true, ".gif": true, ".webp": true, ".svg": true, ".ico": true,
+	".bmp": true, ".tif": true, ".tiff": true, ".avif": true,
+	".mp3": true, ".mp4": true, ".m4a": true, ".wav": true, ".ogg": true, ".webm": true, ".mov": true, ".avi": true,
+	".zip": true, ".gz": true, ".tgz": true, ".tar": true, ".bz2": true, ".xz": true, ".rar": true, ".7z": true,
+	".exe": true, ".dmg": true, ".msi": true, ".apk": true, ".iso": true,
+	".css": true, ".js": true, ".woff": true, ".woff2": true, ".ttf": true, ".otf": true, ".eot": true,
+}
+
+// crawlTarget is a page waiting in the crawl frontier
+type crawlTarget struct {
+	url     string
+	depth   int
+	foundOn string
+}
+
+// Crawl analyzes the seed URL and follows the internal links it discovers, breadth first,
+// until the depth or page budget in opts is exhausted. Links to files that are hardly ever pages, such as PDFs
+// and images, are not followed, and responses other than HTML are reported apart from the pages.
+func (p *PageAnalyzer) Crawl(ctx context.Context, seed string, opts models.CrawlOptions) *models.SiteReport {
+	startTime := time.Now()
+	opts = p.crawlOptions(opts)
+	report := models.NewSiteReport(seed, opts)
+
+	validatedSeed, err := p.validator.ValidateURL(seed)
+	if err != nil {
+		report.Error, _ = invalidURLError(seed, err)
+		p.logger.Error("Invalid crawl seed URL", err)
+		return report
+	}
+	report.SeedURL = validatedSeed
+
+	p.logger.WithField("seed", validatedSeed).
+		WithField("max_depth", opts.MaxDepth).
+		WithField("max_pages", opts.MaxPages).
+		Info("Starting site crawl")
+
+	visited := map[string]bool{normalizeURL(validatedSeed): true}
+	frontier := []crawlTarget{{url: validatedSeed}}
+
+	for len(frontier) > 0 {
+		if ctx.Err() != nil {
+			report.Error = fmt.Sprintf("Crawl cancelled: %v", ctx.Err())
+			break
+		}
+		if report.PagesAnalyzed >= opts.MaxPages {
+			report.BudgetExhausted = true
+			break
+		}
+
+		target := frontier[0]
+		frontier = frontier[1:]
+
+		pageStart := time.Now()
+		result, internalLinks, notHTML := p.analyze(ctx, target.url)
+		result.AnalysisTime = time.Since(pageStart).String()
+
+		// Documents other than HTML pages do not use up the page budget
+		if notHTML != "" {
+			report.NonHTMLPages = append(report.NonHTMLPages, models.NonHTMLPage{
+				URL:         target.url,
+				FoundOn:     target.foundOn,
+				ContentType: notHTML,
+			})
+			if target.depth == 0 {
+				report.Error = result.Error
+			}
+			continue
+		}
+
+		report.AddPage(models.SitePage{
+			URL:     target.url,
+			Depth:   target.depth,
+			FoundOn: target.foundOn,
+			Result:  result,
+		})
+
+		if target.depth >= opts.MaxDepth {
+			continue
+		}
+
+		for _, link := range internalLinks {
+			key := normalizeURL(link)
+			if visited[key] || !p.validator.IsInternalLink(link, validatedSeed) || !mayBePage(key) {
+				continue
+			}
+			visited[key] = true
+			frontier = append(frontier, crawlTarget{
+				url:     key,
+				depth:   target.depth + 1,
+				foundOn: target.url,
+			})
+		}
+	}
+
+	report.CrawlTime = time.Since(startTime).String()
+
+	p.logger.WithField("seed", validatedSeed).
+		WithField("pages", report.PagesAnalyzed).
+		WithField("duration", time.Since(startTime)).
+		Info("Site crawl completed")
+
+	return report
+}
+
+// mayBePage reports whether a link may lead to an HTML page, going by the file extension of its path
+func mayBePage(link string) bool {
+	parsed, err := url.Parse(link)
+	if err != nil {
+		return false
+	}
+	return !nonHTMLExtensions[strings.ToLower(path.Ext(parsed.Path))]
+}
+
+// crawlOptions fills in unset options from the config and caps them at the configured limits
+func (p *PageAnalyzer) crawlOptions(opts models.CrawlOptions) models.CrawlOptions {
+	maxDepth := p.config.CrawlMaxDepth
+	if maxDepth <= 0 {
+		maxDepth = defaultCrawlMaxDepth
+	}
+	maxPages := p.config.CrawlMaxPages
+	if maxPages <= 0 {
+		maxPages = defaultCrawlMaxPages
+	}
+
+	if opts.MaxDepth <= 0 || opts.MaxDepth > maxDepth {
+		opts.MaxDepth = maxDepth
+	}
+	if opts.MaxPages <= 0 || opts.MaxPages > maxPages {
+		opts.MaxPages = maxPages
+	}
+	return opts
+}
@@ -0,0 +1,36 @@
+package analyzer
+
+import (
+	"WebAppAnalyzer/internal/validator"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+const defaultUserAgent = "WebPageAnalyzer/1.0"
+
+// invalidURLError returns the error message and status code of a URL that failed validation, naming the domain
+// list that refused it
+func invalidURLError(url string, err error) (string, int) {
+	if errors.Is(err, validator.ErrDomainDenied) || errors.Is(err, validator.ErrDomainNotAllowed) {
+		return fmt.Sprintf("%s: %s", err, url), http.StatusForbidden
+	}
+	return fmt.Sprintf("Invalid URL: %s", url), http.StatusBadRequest
+}
+
+// userAgent returns the User-Agent of requests to the host, the one of its domain policy when it sets one
+func (p *PageAnalyzer) userAgent(host string) string {
+	if policy, ok := p.validator.Policy(host); ok && policy.UserAgent != "" {
+		return policy.UserAgent
+	}
+	return defaultUserAgent
+}
+
+// linkCheckAllowed reports whether links to the host may be checked under the domain lists and policies
+func (p *PageAnalyzer) linkCheckAllowed(host string) bool {
+	if err := p.validator.CheckHost(host); err != nil {
+		return false
+	}
+	policy, ok := p.validator.Policy(host)
+	return !ok || policy.LinkChecksAllowed()
+}
@@ -0,0 +1,160 @@
+package analyzer
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// hostLimiterPruneSize is the number of tracked hosts above which idle hosts are forgotten
+const hostLimiterPruneSize = 1000
+
+// hostLimiter caps the number of concurrent requests and the request rate per host, so pages with many
+// links to the same domain do not get the analyzer throttled
+type hostLimiter struct {
+	mu            sync.Mutex
+	maxConcurrent int
+	interval      time.Duration
+	hosts         map[string]*hostState
+}
+
+type hostState struct {
+	slots       chan struct{}
+	nextAllowed time.Time
+	lastUsed    time.Time
+}
+
+// newHostLimiter creates a limiter, or returns nil when neither a concurrency nor a rate limit is configured
+func newHostLimiter(maxConcurrent int, requestsPerSecond float64) *hostLimiter {
+	if maxConcurrent <= 0 && requestsPerSecond <= 0 {
+		return nil
+	}
+
+	limiter := &hostLimiter{
+		maxConcurrent: maxConcurrent,
+		hosts:         make(map[string]*hostState),
+	}
+	if requestsPerSecond > 0 {
+		limiter.interval = time.Duration(float64(time.Second) / requestsPerSecond)
+	}
+	return limiter
+}
+
+// acquire waits until a request to the host is allowed and returns a function that releases the slot
+func (l *hostLimiter) acquire(ctx context.Context, host string) (func(), error) {
+	if l == nil {
+		return func() {}, nil
+	}
+
+	state := l.state(host)
+
+	if state.slots != nil {
+		select {
+		case state.slots <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	release := func() {
+		if state.slots != nil {
+			<-state.slots
+		}
+	}
+
+	for {
+		l.mu.Lock()
+		now := time.Now()
+		wait := state.nextAllowed.Sub(now)
+		if wait <= 0 {
+			state.nextAllowed = now.Add(l.interval)
+			state.lastUsed = now
+			l.mu.Unlock()
+			return release, nil
+		}
+		l.mu.Unlock()
+
+		if err := sleepContext(ctx, wait); err != nil {
+			release()
+			return nil, err
+		}
+	}
+}
+
+// backoff keeps every request to the host waiting for at least the given delay
+func (l *hostLimiter) backoff(host string, delay time.Duration) {
+	if l == nil {
+		return
+	}
+
+	state := l.state(host)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if until := time.Now().Add(delay); until.After(state.nextAllowed) {
+		state.nextAllowed = until
+	}
+}
+
+func (l *hostLimiter) state(host string) *hostState {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if state, ok := l.hosts[host]; ok {
+		return state
+	}
+
+	if len(l.hosts) >= hostLimiterPruneSize {
+		l.prune()
+	}
+
+	state := &hostState{lastUsed: time.Now()}
+	if l.maxConcurrent > 0 {
+		state.slots = make(chan struct{}, l.maxConcurrent)
+	}
+	l.hosts[host] = state
+	return state
+}
+
+// prune forgets hosts without requests in flight whose rate limit window has passed. Callers must hold l.mu.
+func (l *hostLimiter) prune() {
+	now := time.Now()
+	for host, state := range l.hosts {
+		if len(state.slots) == 0 && now.After(state.nextAllowed) && now.Sub(state.lastUsed) > time.Minute {
+			delete(l.hosts, host)
+		}
+	}
+}
+
+// retryableStatus reports whether the server asked us to slow down and try again later
+func retryableStatus(statusCode int) bool {
+	return statusCode == http.StatusTooManyRequests || statusCode == http.StatusServiceUnavailable
+}
+
+// retryDelay returns how long to wait before retrying, from the Retry-After header when present and
+// otherwise with an exponential backoff starting at one second
+func retryDelay(retryAfter string, attempt int) time.Duration {
+	if retryAfter != "" {
+		if seconds, err := strconv.Atoi(retryAfter); err == nil && seconds >= 0 {
+			return time.Duration(seconds) * time.Second
+		}
+		if date, err := http.ParseTime(retryAfter); err == nil {
+			return max(time.Until(date), 0)
+		}
+	}
+	return time.Second << attempt
+}
+
+// sleepContext waits for the given duration or until the context is done
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
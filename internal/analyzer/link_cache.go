@@ -0,0 +1,101 @@
+package analyzer
+
+import (
+	"container/list"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// linkStatusCache is a size bounded LRU cache of link check results shared by every analysis in the process.
+// Entries expire after the configured TTL.
+type linkStatusCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	maxSize int
+	entries map[string]*list.Element
+	order   *list.List
+}
+
+type linkCacheEntry struct {
+	key    string
+	result LinkCheckResult
+}
+
+// newLinkStatusCache creates a cache, or returns nil when caching is disabled by a non-positive TTL or size
+func newLinkStatusCache(ttl time.Duration, maxSize int) *linkStatusCache {
+	if ttl <= 0 || maxSize <= 0 {
+		return nil
+	}
+	return &linkStatusCache{
+		ttl:     ttl,
+		maxSize: maxSize,
+		entries: make(map[string]*list.Element),
+		order:   list.New(),
+	}
+}
+
+// get returns the cached result for the link if it is still fresh
+func (c *linkStatusCache) get(linkURL string) (LinkCheckResult, bool) {
+	if c == nil {
+		return LinkCheckResult{}, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := normalizeURL(linkURL)
+	element, ok := c.entries[key]
+	if !ok {
+		return LinkCheckResult{}, false
+	}
+
+	entry := element.Value.(*linkCacheEntry)
+	if time.Since(entry.result.CheckedAt) > c.ttl {
+		c.order.Remove(element)
+		delete(c.entries, key)
+		return LinkCheckResult{}, false
+	}
+
+	c.order.MoveToFront(element)
+	return entry.result, true
+}
+
+// put stores the result of a link check, evicting the least recently used entry when the cache is full
+func (c *linkStatusCache) put(linkURL string, result LinkCheckResult) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := normalizeURL(linkURL)
+	if element, ok := c.entries[key]; ok {
+		element.Value.(*linkCacheEntry).result = result
+		c.order.MoveToFront(element)
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(&linkCacheEntry{key: key, result: result})
+	for c.order.Len() > c.maxSize {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*linkCacheEntry).key)
+	}
+}
+
+// normalizeURL reduces a URL to the form used to detect links pointing at the same resource
+func normalizeURL(rawURL string) string {
+	parsedURL, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	parsedURL.Fragment = ""
+	parsedURL.Host = strings.ToLower(parsedURL.Host)
+	if parsedURL.Path == "" {
+		parsedURL.Path = "/"
+	}
+	return parsedURL.String()
+}
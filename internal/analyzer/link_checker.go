@@ -0,0 +1,271 @@
+package analyzer
+
+import (
+	"WebAppAnalyzer/internal/models"
+	"context"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+const (
+	defaultLinkMaxRedirects = 10
+	defaultMaxRetryAfter    = 30 * time.Second
+	defaultLinkCheckTimeout = 10 * time.Second
+)
+
+// checkLink checks a queued link and annotates the result with where it was found. It runs on the shared
+// link check pool workers.
+func (p *PageAnalyzer) checkLink(ctx context.Context, target linkTarget) LinkCheckResult {
+	result := p.checkSingleLink(ctx, target.URL)
+	if result.Error != nil && ctx.Err() != nil {
+		// The analysis was cancelled during the check, such as on shutdown, which says nothing about the link
+		result.Error = nil
+		result.SkipReason = models.SkipReasonCancelled
+	}
+	result.AnchorText = target.AnchorText
+	result.IsExternal = target.IsExternal
+	return result
+}
+
+// checkSingleLink checks whether a link is reachable, answering from the shared link status cache when a
+// fresh result is available. Only results that got an HTTP answer are cached, network errors are retried.
+func (p *PageAnalyzer) checkSingleLink(ctx context.Context, linkURL string) (result LinkCheckResult) {
+	ctx, span := startSpan(ctx, "checkSingleLink", trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(semconv.URLFull(linkURL)))
+	defer func() {
+		span.SetAttributes(
+			attribute.Bool("link.cached", result.Cached),
+			attribute.Bool("link.accessible", result.IsAccessible),
+			attribute.Int("link.redirects", len(result.RedirectChain)),
+		)
+		if result.StatusCode != 0 {
+			span.SetAttributes(semconv.HTTPResponseStatusCode(result.StatusCode))
+		}
+		if result.SkipReason != "" {
+			span.SetAttributes(attribute.String("link.skip_reason", result.SkipReason))
+		}
+		endSpan(span, result.Error)
+	}()
+
+	if cached, ok := p.linkCache.get(linkURL); ok {
+		cached.URL = linkURL
+		cached.Cached = true
+		return cached
+	}
+
+	result = p.probeLink(ctx, linkURL)
+	if result.Error == nil && result.SkipReason == "" {
+		p.linkCache.put(linkURL, result)
+	}
+	return result
+}
+
+// probeLink checks whether a link is reachable. Redirects are followed manually so the chain can be
+// reported, and servers refusing HEAD are retried with a ranged GET. A link is accessible when the final
+// response of the chain is 2xx.
+func (p *PageAnalyzer) probeLink(ctx context.Context, linkURL string) (result LinkCheckResult) {
+	// The timeout bounds each request on its own. The waits for Retry-After and the per-host limits in between
+	// have bounds of their own, so a throttled host is waited for rather than reported broken.
+	client := p.noRedirectClient()
+	client.Timeout = defaultLinkCheckTimeout
+	if parsedURL, err := url.Parse(linkURL); err == nil {
+		if policy, ok := p.validator.Policy(parsedURL.Hostname()); ok && policy.Timeout() > 0 {
+			client.Timeout = policy.Timeout()
+		}
+	}
+
+	result = LinkCheckResult{
+		URL:       linkURL,
+		FinalURL:  linkURL,
+		CheckedAt: time.Now(),
+	}
+
+	maxRedirects := p.config.LinkMaxRedirects
+	if maxRedirects <= 0 {
+		maxRedirects = defaultLinkMaxRedirects
+	}
+
+	visited := map[string]bool{linkURL: true}
+	current := linkURL
+	startTime := time.Now()
+	timing := newRequestTiming()
+	ctx = timing.withTrace(ctx)
+	defer func() {
+		result.ResponseTime = time.Since(startTime)
+		result.Timing = timing.result()
+	}()
+
+	for {
+		resp, method, err := p.requestLink(ctx, client, current)
+		if err != nil {
+			result.Error = err
+			return result
+		}
+		resp.Body.Close()
+		timing.bodyDone()
+
+		result.Method = method
+		result.StatusCode = resp.StatusCode
+		result.FinalURL = current
+
+		if resp.StatusCode == http.StatusTooManyRequests {
+			// Still throttled after honoring Retry-After, the link state is unknown rather than broken
+			result.SkipReason = models.SkipReasonRateLimited
+			return result
+		}
+
+		location := resp.Header.Get("Location")
+		if !isRedirectStatus(resp.StatusCode) || location == "" {
+			result.IsAccessible = linkAccessible(method, resp.StatusCode)
+			return result
+		}
+
+		next, err := resolveLocation(current, location)
+		if err != nil {
+			result.Error = err
+			return result
+		}
+
+		result.RedirectChain = append(result.RedirectChain, models.RedirectHop{
+			URL:        current,
+			StatusCode: resp.StatusCode,
+			Location:   next,
+		})
+
+		if visited[next] {
+			result.RedirectLoop = true
+			return result
+		}
+		if len(result.RedirectChain) >= maxRedirects {
+			result.TooManyRedirects = true
+			return result
+		}
+
+		visited[next] = true
+		current = next
+	}
+}
+
+// requestLink issues a HEAD request for the link and falls back to a ranged GET when the server refuses HEAD
+func (p *PageAnalyzer) requestLink(ctx context.Context, client *http.Client, linkURL string) (*http.Response, string, error) {
+	resp, err := p.doLinkRequest(ctx, client, http.MethodHead, linkURL)
+	if err != nil {
+		return nil, http.MethodHead, err
+	}
+	if !headRefused(resp.StatusCode) {
+		return resp, http.MethodHead, nil
+	}
+	resp.Body.Close()
+
+	resp, err = p.doLinkRequest(ctx, client, http.MethodGet, linkURL)
+	return resp, http.MethodGet, err
+}
+
+// doLinkRequest sends a link check request, retrying while the server answers 429 or 503 as long as the
+// number of retries and the requested Retry-After delay stay within the configured bounds
+func (p *PageAnalyzer) doLinkRequest(ctx context.Context, client *http.Client, method, linkURL string) (*http.Response, error) {
+	maxRetryAfter := time.Duration(p.config.MaxRetryAfterInSeconds) * time.Second
+	if maxRetryAfter <= 0 {
+		maxRetryAfter = defaultMaxRetryAfter
+	}
+
+	for attempt := 0; ; attempt++ {
+		resp, err := p.sendLinkRequest(ctx, client, method, linkURL)
+		if err != nil || !retryableStatus(resp.StatusCode) || attempt >= p.config.LinkMaxRetries {
+			return resp, err
+		}
+
+		delay := retryDelay(resp.Header.Get("Retry-After"), attempt)
+		if delay > maxRetryAfter {
+			return resp, nil
+		}
+		resp.Body.Close()
+
+		p.logger.WithField("url", linkURL).
+			WithField("status", resp.StatusCode).
+			WithField("retry_in", delay).
+			Debug("Link check throttled, retrying")
+
+		// Hold back every checker talking to this host, not only the one that was throttled
+		if parsedURL, err := url.Parse(linkURL); err == nil {
+			p.hostLimiter.backoff(parsedURL.Host, delay)
+		}
+		if err := sleepContext(ctx, delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// sendLinkRequest sends a single link check request once the per-host limits allow it
+func (p *PageAnalyzer) sendLinkRequest(ctx context.Context, client *http.Client, method, linkURL string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, linkURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("User-Agent", p.userAgent(req.URL.Hostname()))
+	p.injectTraceContext(ctx, req)
+	if method == http.MethodGet {
+		// Only the first byte is needed to know the resource exists
+		req.Header.Set("Range", "bytes=0-0")
+	}
+
+	release, err := p.hostLimiter.acquire(ctx, req.URL.Host)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	return client.Do(req)
+}
+
+// noRedirectClient returns a copy of the analyzer client that hands redirect responses back to the caller
+func (p *PageAnalyzer) noRedirectClient() *http.Client {
+	client := *p.client
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+	return &client
+}
+
+// headRefused reports whether the status code suggests the server does not support HEAD for this resource
+func headRefused(statusCode int) bool {
+	return statusCode == http.StatusMethodNotAllowed ||
+		statusCode == http.StatusForbidden ||
+		statusCode == http.StatusNotImplemented
+}
+
+// linkAccessible reports whether the final response shows the link works. A ranged GET of an empty resource is
+// answered with 416 since there is no first byte to send, which still means the resource exists.
+func linkAccessible(method string, statusCode int) bool {
+	if method == http.MethodGet && statusCode == http.StatusRequestedRangeNotSatisfiable {
+		return true
+	}
+	return statusCode >= 200 && statusCode < 300
+}
+
+func isRedirectStatus(statusCode int) bool {
+	switch statusCode {
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusSeeOther,
+		http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+		return true
+	}
+	return false
+}
+
+// resolveLocation resolves a Location header against the URL that returned it
+func resolveLocation(current, location string) (string, error) {
+	base, err := url.Parse(current)
+	if err != nil {
+		return "", err
+	}
+	next, err := url.Parse(location)
+	if err != nil {
+		return "", err
+	}
+	return base.ResolveReference(next).String(), nil
+}
@@ -0,0 +1,60 @@
+package analyzer
+
+import (
+	"WebAppAnalyzer/internal/models"
+	"WebAppAnalyzer/internal/netguard"
+	"WebAppAnalyzer/internal/validator"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net"
+	"os"
+)
+
+// classifyLinkError maps the error of a failed link check to one of the models.LinkError* classes.
+// A nil error means the request completed but the server answered with an unsuccessful status.
+func classifyLinkError(err error) string {
+	if err == nil {
+		return models.LinkErrorHTTP
+	}
+
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		if dnsErr.IsTimeout {
+			return models.LinkErrorTimeout
+		}
+		return models.LinkErrorDNS
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, os.ErrDeadlineExceeded) {
+		return models.LinkErrorTimeout
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return models.LinkErrorTimeout
+	}
+
+	var (
+		certVerifyErr  *tls.CertificateVerificationError
+		recordErr      tls.RecordHeaderError
+		unknownAuthErr x509.UnknownAuthorityError
+		hostnameErr    x509.HostnameError
+		certInvalidErr x509.CertificateInvalidError
+	)
+	if errors.As(err, &certVerifyErr) || errors.As(err, &recordErr) || errors.As(err, &unknownAuthErr) ||
+		errors.As(err, &hostnameErr) || errors.As(err, &certInvalidErr) {
+		return models.LinkErrorTLS
+	}
+
+	if errors.Is(err, netguard.ErrBlockedAddress) || errors.Is(err, validator.ErrDomainDenied) ||
+		errors.Is(err, validator.ErrDomainNotAllowed) {
+		return models.LinkErrorBlocked
+	}
+	var opErr *net.OpError
+	if errors.As(err, &opErr) {
+		return models.LinkErrorConnection
+	}
+
+	return models.LinkErrorOther
+}
@@ -0,0 +1,79 @@
+package analyzer
+
+import (
+	"WebAppAnalyzer/internal/models"
+	"context"
+	"sync"
+)
+
+type progressKey struct{}
+
+// ProgressFunc receives the events of an analysis as they happen. Calls are serialized.
+type ProgressFunc func(event models.AnalysisEvent)
+
+// WithProgress returns a context that reports the progress of the analyses run with it to fn
+func WithProgress(ctx context.Context, fn ProgressFunc) context.Context {
+	return context.WithValue(ctx, progressKey{}, fn)
+}
+
+// progressReporter tracks the progress of one analysis. It is shared by the page walker and the result
+// processing, so updates are made under a lock.
+type progressReporter struct {
+	mu       sync.Mutex
+	fn       ProgressFunc
+	progress models.AnalysisProgress
+}
+
+// newProgressReporter returns a reporter for the progress function of the context, or nil when there is none
+func newProgressReporter(ctx context.Context) *progressReporter {
+	fn, ok := ctx.Value(progressKey{}).(ProgressFunc)
+	if !ok || fn == nil {
+		return nil
+	}
+	return &progressReporter{fn: fn}
+}
+
+// report applies the change to the progress and emits the event with the new progress
+func (r *progressReporter) report(event models.AnalysisEvent, change func(progress *models.AnalysisProgress)) {
+	if r == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	change(&r.progress)
+	event.Progress = r.progress
+	r.fn(event)
+}
+
+// stage moves the analysis to the given stage
+func (r *progressReporter) stage(stage string) {
+	r.report(models.AnalysisEvent{Type: models.EventStage}, func(progress *models.AnalysisProgress) {
+		progress.Stage = stage
+	})
+}
+
+// linkFound counts a link queued for checking
+func (r *progressReporter) linkFound() {
+	r.report(models.AnalysisEvent{Type: models.EventProgress}, func(progress *models.AnalysisProgress) {
+		progress.LinksFound++
+	})
+}
+
+// linkChecked reports the outcome of a link check
+func (r *progressReporter) linkChecked(link models.LinkDetail) {
+	r.report(models.AnalysisEvent{Type: models.EventLink, Link: &link}, func(progress *models.AnalysisProgress) {
+		progress.LinksChecked++
+	})
+}
+
+// linkSkipped reports a link that was not checked. Links skipped before they were queued are counted as found too.
+func (r *progressReporter) linkSkipped(link models.SkippedLink, queued bool) {
+	r.report(models.AnalysisEvent{Type: models.EventSkippedLink, SkippedLink: &link}, func(progress *models.AnalysisProgress) {
+		if !queued {
+			progress.LinksFound++
+		}
+		progress.LinksSkipped++
+	})
+}
@@ -0,0 +1,230 @@
+package analyzer
+
+import (
+	"WebAppAnalyzer/internal/metrics"
+	"WebAppAnalyzer/internal/models"
+	"context"
+	"errors"
+	"fmt"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"io/fs"
+	"net/url"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+// staticSite is a static site build on disk, served at a base URL
+type staticSite struct {
+	files fs.FS
+	base  *url.URL
+}
+
+// AnalyzeDirectory analyzes every HTML page of a static site build directory, such as a Hugo or Next.js export,
+// without network access. Each page is analyzed as if it was served at baseURL, internal links are looked up in
+// the files of the directory, and the pages are aggregated into a site report listing the links to missing files.
+// External links cannot be checked offline and are reported as skipped. maxPages limits the pages analyzed when
+// it is positive.
+func (p *PageAnalyzer) AnalyzeDirectory(ctx context.Context, root, baseURL string, maxPages int) *models.SiteReport {
+	startTime := time.Now()
+	ctx, span := startSpan(ctx, "analyze", trace.WithAttributes(semconv.URLFull(baseURL),
+		attribute.String("analysis.source", metrics.SourceStaticSite), attribute.String("directory", root)))
+	report := models.NewSiteReport(baseURL, models.CrawlOptions{MaxPages: maxPages})
+	report.Directory = root
+	defer func() {
+		span.SetAttributes(attribute.Int("pages.analyzed", report.PagesAnalyzed), attribute.Int("files.missing", len(report.MissingFiles)))
+		var err error
+		if report.Error != "" {
+			err = errors.New(report.Error)
+		}
+		endSpan(span, err)
+	}()
+
+	validatedURL, err := p.validator.ValidateURL(baseURL)
+	if err != nil {
+		report.Error, _ = invalidURLError(baseURL, err)
+		p.logger.Error("Invalid base URL", err)
+		return report
+	}
+	base, _ := url.Parse(validatedURL)
+	if !strings.HasSuffix(base.Path, "/") {
+		base.Path += "/"
+	}
+	report.SeedURL = base.String()
+
+	if info, err := os.Stat(root); err != nil || !info.IsDir() {
+		report.Error = fmt.Sprintf("Not a directory: %s", root)
+		return report
+	}
+	site := &staticSite{files: os.DirFS(root), base: base}
+
+	pages, err := site.pages()
+	if err != nil {
+		report.Error = fmt.Sprintf("Failed to list the HTML files: %v", err)
+		return report
+	}
+	if len(pages) == 0 {
+		report.Error = fmt.Sprintf("No HTML files found in %s", root)
+		return report
+	}
+
+	p.logger.WithField("directory", root).
+		WithField("base_url", report.SeedURL).
+		WithField("pages", len(pages)).
+		Info("Starting static site analysis")
+
+	// linkedFrom maps the URL of every missing file to the pages linking to it
+	linkedFrom := make(map[string][]string)
+	for _, name := range pages {
+		if ctx.Err() != nil {
+			report.Error = fmt.Sprintf("Analysis cancelled: %v", ctx.Err())
+			break
+		}
+		if maxPages > 0 && report.PagesAnalyzed >= maxPages {
+			report.BudgetExhausted = true
+			break
+		}
+
+		pageURL := site.pageURL(name)
+		result := p.analyzeFile(ctx, site, name, pageURL)
+		for _, link := range result.Links {
+			if link.ErrorClass == models.LinkErrorMissingFile {
+				linkedFrom[link.URL] = append(linkedFrom[link.URL], pageURL)
+			}
+		}
+
+		report.AddPage(models.SitePage{
+			URL:    pageURL,
+			File:   name,
+			Result: result,
+		})
+	}
+
+	for missingURL, pageURLs := range linkedFrom {
+		report.MissingFiles = append(report.MissingFiles, models.MissingFile{URL: missingURL, LinkedFrom: pageURLs})
+	}
+	sort.Slice(report.MissingFiles, func(i, j int) bool {
+		return report.MissingFiles[i].URL < report.MissingFiles[j].URL
+	})
+
+	report.CrawlTime = time.Since(startTime).String()
+
+	p.logger.WithField("directory", root).
+		WithField("pages", report.PagesAnalyzed).
+		WithField("missing_files", len(report.MissingFiles)).
+		WithField("duration", time.Since(startTime)).
+		Info("Static site analysis completed")
+
+	return report
+}
+
+// analyzeFile analyzes a page of the site, served at pageURL
+func (p *PageAnalyzer) analyzeFile(ctx context.Context, site *staticSite, name, pageURL string) *models.AnalysisResult {
+	pageStart := time.Now()
+	ctx, span := startSpan(ctx, "analyzeFile", trace.WithAttributes(semconv.URLFull(pageURL), attribute.String("file", name)))
+	result := models.NewAnalysisResult(pageURL)
+	defer func() {
+		result.AnalysisTime = time.Since(pageStart).String()
+		p.metrics.ObserveAnalysis(metrics.SourceStaticSite, result, time.Since(pageStart))
+		endAnalysisSpan(span, result)
+	}()
+
+	file, err := site.files.Open(name)
+	if err != nil {
+		result.SetError(fmt.Sprintf("Failed to read %s: %v", name, err), 0)
+		return result
+	}
+	defer file.Close()
+
+	p.analyzeDocument(ctx, file, pageURL, result, newProgressReporter(ctx), site)
+	return result
+}
+
+// pages lists the HTML files of the site in lexical order, leaving out hidden directories such as .git
+func (s *staticSite) pages() ([]string, error) {
+	pages := make([]string, 0)
+	err := fs.WalkDir(s.files, ".", func(name string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if name != "." && strings.HasPrefix(entry.Name(), ".") {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if ext := strings.ToLower(path.Ext(name)); ext == ".html" || ext == ".htm" {
+			pages = append(pages, name)
+		}
+		return nil
+	})
+	return pages, err
+}
+
+// pageURL returns the URL a file is served at, directory indexes being served at the directory URL
+func (s *staticSite) pageURL(name string) string {
+	if path.Base(name) == "index.html" {
+		name = strings.TrimSuffix(name, "index.html")
+	}
+	return s.base.ResolveReference(&url.URL{Path: name}).String()
+}
+
+// serves reports whether a file of the site serves an internal link, trying the path itself, the path with an
+// .html extension and the directory index like static hosts do. inSite is false for links outside the base path.
+func (s *staticSite) serves(linkURL string) (found, inSite bool) {
+	link, err := url.Parse(linkURL)
+	if err != nil || !strings.HasPrefix(link.Path+"/", s.base.Path) {
+		return false, false
+	}
+
+	name := strings.TrimPrefix(path.Clean(strings.TrimPrefix(link.Path, strings.TrimSuffix(s.base.Path, "/"))), "/")
+	if name == "." {
+		name = ""
+	}
+	candidates := []string{path.Join(name, "index.html")}
+	if name != "" && !strings.HasSuffix(link.Path, "/") {
+		candidates = append([]string{name, name + ".html"}, candidates...)
+	}
+
+	for _, candidate := range candidates {
+		if info, err := fs.Stat(s.files, candidate); err == nil && !info.IsDir() {
+			return true, true
+		}
+	}
+	return false, true
+}
+
+// lookUp records an internal link of a static site build, as broken when no file of the build serves it. External
+// links and links outside the site cannot be checked offline and are skipped.
+func (l *linkSink) lookUp(target linkTarget) {
+	key := normalizeURL(target.URL)
+	l.occurrences[key]++
+	if l.occurrences[key] > 1 {
+		return
+	}
+
+	found, inSite := l.site.serves(target.URL)
+	if target.IsExternal || !inSite {
+		l.skip(target, models.SkipReasonOffline)
+		return
+	}
+
+	detail := models.LinkDetail{
+		URL:          target.URL,
+		AnchorText:   target.AnchorText,
+		IsAccessible: found,
+		CheckedAt:    time.Now(),
+	}
+	if !found {
+		detail.ErrorClass = models.LinkErrorMissingFile
+		detail.Error = "no file in the build directory serves this link"
+	}
+
+	l.progress.linkFound()
+	l.offline = append(l.offline, detail)
+	l.progress.linkChecked(detail)
+}
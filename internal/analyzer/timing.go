@@ -0,0 +1,151 @@
+package analyzer
+
+import (
+	"WebAppAnalyzer/internal/models"
+	"context"
+	"crypto/tls"
+	"io"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+// requestTiming measures the phases of the requests sent with its client trace. The transport may report them
+// from other goroutines, such as the one dialing, so the marks are guarded.
+type requestTiming struct {
+	mu sync.Mutex
+
+	start        time.Time
+	dnsStart     time.Time
+	connectStart time.Time
+	tlsStart     time.Time
+	wroteRequest time.Time
+	firstByte    time.Time
+	end          time.Time
+
+	breakdown models.TimingBreakdown
+}
+
+func newRequestTiming() *requestTiming {
+	return &requestTiming{start: time.Now()}
+}
+
+// withTrace returns a context that reports the phases of the requests sent with it to the timing
+func (t *requestTiming) withTrace(ctx context.Context) context.Context {
+	return httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.breakdown.ConnectionReused = info.Reused
+		},
+		DNSStart: func(httptrace.DNSStartInfo) {
+			t.mark(&t.dnsStart)
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			t.add(&t.breakdown.DNSLookupMs, &t.dnsStart)
+		},
+		ConnectStart: func(string, string) {
+			t.mark(&t.connectStart)
+		},
+		ConnectDone: func(string, string, error) {
+			t.add(&t.breakdown.TCPConnectMs, &t.connectStart)
+		},
+		TLSHandshakeStart: func() {
+			t.mark(&t.tlsStart)
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			t.add(&t.breakdown.TLSHandshakeMs, &t.tlsStart)
+		},
+		WroteRequest: func(httptrace.WroteRequestInfo) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.wroteRequest = time.Now()
+		},
+		GotFirstResponseByte: func() {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.firstByte = time.Now()
+			if !t.wroteRequest.IsZero() {
+				t.breakdown.TimeToFirstByteMs += milliseconds(t.firstByte.Sub(t.wroteRequest))
+				t.wroteRequest = time.Time{}
+			}
+		},
+	})
+}
+
+// mark records the start of a phase unless it is already under way, such as when the addresses of a host are
+// dialed in parallel
+func (t *requestTiming) mark(start *time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if start.IsZero() {
+		*start = time.Now()
+	}
+}
+
+// add adds the time since the start of a phase to its total. Only the first of duplicate done events counts.
+func (t *requestTiming) add(total *float64, start *time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if start.IsZero() {
+		return
+	}
+	*total += milliseconds(time.Since(*start))
+	*start = time.Time{}
+}
+
+// bodyDone records the end of the download of the last response body
+func (t *requestTiming) bodyDone() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.firstByte.IsZero() {
+		return
+	}
+	t.end = time.Now()
+	t.breakdown.ContentDownloadMs += milliseconds(t.end.Sub(t.firstByte))
+	t.firstByte = time.Time{}
+}
+
+// timeBody returns the body with its download recorded once it has been read to the end or closed
+func (t *requestTiming) timeBody(body io.ReadCloser) io.ReadCloser {
+	return &timedBody{ReadCloser: body, done: t.bodyDone}
+}
+
+// result returns the breakdown of the phases measured so far. The total runs until the end of the last body
+// download, or until now when no body was downloaded.
+func (t *requestTiming) result() *models.TimingBreakdown {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	end := t.end
+	if end.IsZero() {
+		end = time.Now()
+	}
+	breakdown := t.breakdown
+	breakdown.TotalMs = milliseconds(end.Sub(t.start))
+	return &breakdown
+}
+
+// timedBody reports the end of the download of a response body
+type timedBody struct {
+	io.ReadCloser
+	once sync.Once
+	done func()
+}
+
+func (b *timedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if err == io.EOF {
+		b.once.Do(b.done)
+	}
+	return n, err
+}
+
+func (b *timedBody) Close() error {
+	b.once.Do(b.done)
+	return b.ReadCloser.Close()
+}
+
+// milliseconds converts a duration to milliseconds with microsecond precision
+func milliseconds(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
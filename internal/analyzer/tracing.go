@@ -0,0 +1,52 @@
+package analyzer
+
+import (
+	"WebAppAnalyzer/internal/models"
+	"context"
+	"errors"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"net/http"
+)
+
+const tracerName = "WebAppAnalyzer/internal/analyzer"
+
+// startSpan starts a span of the analysis pipeline with the global tracer provider, so spans are only exported
+// once tracing is set up and cost next to nothing otherwise. The provider is looked up on every span to follow
+// the one registered last.
+func startSpan(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, name, opts...)
+}
+
+// injectTraceContext adds the trace context of ctx to the headers of an outbound request, so the servers of
+// analyzed pages and links can join the trace. They are third parties, so it is only sent when
+// TRACING_PROPAGATE_OUTBOUND is set, and never with the baggage of the incoming request.
+func (p *PageAnalyzer) injectTraceContext(ctx context.Context, req *http.Request) {
+	if !p.config.TracingPropagateOutbound {
+		return
+	}
+	propagation.TraceContext{}.Inject(ctx, propagation.HeaderCarrier(req.Header))
+}
+
+// endAnalysisSpan ends the root span of the analysis of a page with the number of links checked, failed when the
+// analysis failed
+func endAnalysisSpan(span trace.Span, result *models.AnalysisResult) {
+	span.SetAttributes(attribute.Int("links.checked", len(result.Links)))
+	var err error
+	if !result.IsSuccessful() {
+		err = errors.New(result.Error)
+	}
+	endSpan(span, err)
+}
+
+// endSpan marks the span as failed with err, if any, and ends it
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,259 @@
+package analyzer
+
+import (
+	"WebAppAnalyzer/internal/models"
+	"context"
+	"errors"
+	"sync"
+)
+
+var errPoolClosed = errors.New("link check pool is closed")
+
+// linkCheckPool is the server wide set of link checker workers shared by every analysis. Each analysis
+// registers a session with its own bounded queue and the workers take links from the sessions in turn,
+// so one page with thousands of links cannot starve the others.
+type linkCheckPool struct {
+	mu       sync.Mutex
+	cond     *sync.Cond
+	check    func(ctx context.Context, target linkTarget) LinkCheckResult
+	workers  int
+	sessions []*poolSession
+	next     int
+	busy     int
+	closed   bool
+	wg       sync.WaitGroup
+
+	completed uint64
+	cancelled uint64
+}
+
+// poolSession is the link checking work of a single analysis
+type poolSession struct {
+	pool     *linkCheckPool
+	ctx      context.Context
+	pending  []linkTarget
+	inFlight int
+	finished bool
+	results  chan LinkCheckResult
+	stop     func() bool
+}
+
+// newLinkCheckPool starts the given number of workers running check for every submitted link
+func newLinkCheckPool(workers int, check func(ctx context.Context, target linkTarget) LinkCheckResult) *linkCheckPool {
+	if workers <= 0 {
+		workers = defaultNumOfWorkers
+	}
+
+	pool := &linkCheckPool{
+		check:   check,
+		workers: workers,
+	}
+	pool.cond = sync.NewCond(&pool.mu)
+
+	for i := 0; i < workers; i++ {
+		pool.wg.Add(1)
+		go pool.work()
+	}
+
+	return pool
+}
+
+// register creates a session for one analysis. Results are delivered on the session results channel, which is
+// closed once finish has been called and every submitted link has been answered.
+func (p *linkCheckPool) register(ctx context.Context) *poolSession {
+	session := &poolSession{
+		pool:    p,
+		ctx:     ctx,
+		results: make(chan LinkCheckResult, linkQueueSize),
+	}
+	session.stop = context.AfterFunc(ctx, session.cancel)
+
+	p.mu.Lock()
+	p.sessions = append(p.sessions, session)
+	p.mu.Unlock()
+
+	return session
+}
+
+// submit queues a link for checking, blocking while the session queue is full. It fails when the analysis is
+// cancelled or the pool is shutting down.
+func (s *poolSession) submit(target linkTarget) error {
+	p := s.pool
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for len(s.pending) >= linkQueueSize && s.ctx.Err() == nil && !p.closed {
+		p.cond.Wait()
+	}
+	if err := s.ctx.Err(); err != nil {
+		return err
+	}
+	if p.closed {
+		return errPoolClosed
+	}
+
+	s.pending = append(s.pending, target)
+	p.cond.Broadcast()
+	return nil
+}
+
+// finish tells the pool no more links will be submitted for this session
+func (s *poolSession) finish() {
+	p := s.pool
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	s.finished = true
+	s.closeIfDone()
+}
+
+// cancel removes the pending work of a cancelled session and reports it as skipped
+func (s *poolSession) cancel() {
+	p := s.pool
+
+	p.mu.Lock()
+	dropped := s.pending
+	results := s.results
+	s.pending = nil
+	// Dropped links count as in flight until their skipped results are delivered
+	s.inFlight += len(dropped)
+	p.cancelled += uint64(len(dropped))
+	p.cond.Broadcast()
+	p.mu.Unlock()
+
+	for _, target := range dropped {
+		results <- LinkCheckResult{
+			URL:        target.URL,
+			AnchorText: target.AnchorText,
+			IsExternal: target.IsExternal,
+			SkipReason: models.SkipReasonCancelled,
+		}
+	}
+
+	p.mu.Lock()
+	s.inFlight -= len(dropped)
+	s.closeIfDone()
+	p.mu.Unlock()
+}
+
+// closeIfDone closes the results channel once the session has no more work. Callers must hold the pool lock.
+func (s *poolSession) closeIfDone() {
+	if !s.finished || len(s.pending) > 0 || s.inFlight > 0 || s.results == nil {
+		return
+	}
+
+	close(s.results)
+	s.results = nil
+	s.stop()
+	s.pool.removeSession(s)
+}
+
+// removeSession drops a session from the round robin. Callers must hold the pool lock.
+func (p *linkCheckPool) removeSession(session *poolSession) {
+	for i, s := range p.sessions {
+		if s == session {
+			p.sessions = append(p.sessions[:i], p.sessions[i+1:]...)
+			if p.next > i {
+				p.next--
+			}
+			break
+		}
+	}
+	p.cond.Broadcast()
+}
+
+// work takes links from the sessions in round robin order until the pool is closed and drained
+func (p *linkCheckPool) work() {
+	defer p.wg.Done()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for {
+		session := p.nextSession()
+		if session == nil {
+			if p.closed {
+				return
+			}
+			p.cond.Wait()
+			continue
+		}
+
+		target := session.pending[0]
+		session.pending = session.pending[1:]
+		session.inFlight++
+		p.busy++
+		results := session.results
+		// A slot was freed in the session queue
+		p.cond.Broadcast()
+		p.mu.Unlock()
+
+		var result LinkCheckResult
+		skipped := session.ctx.Err() != nil
+		if skipped {
+			result = LinkCheckResult{
+				URL:        target.URL,
+				AnchorText: target.AnchorText,
+				IsExternal: target.IsExternal,
+				SkipReason: models.SkipReasonCancelled,
+			}
+		} else {
+			result = p.check(session.ctx, target)
+		}
+		results <- result
+
+		p.mu.Lock()
+		p.busy--
+		if skipped {
+			p.cancelled++
+		} else {
+			p.completed++
+		}
+		session.inFlight--
+		session.closeIfDone()
+	}
+}
+
+// nextSession picks the next session with pending work, rotating between sessions. Callers must hold the pool lock.
+func (p *linkCheckPool) nextSession() *poolSession {
+	for i := 0; i < len(p.sessions); i++ {
+		index := (p.next + i) % len(p.sessions)
+		if len(p.sessions[index].pending) > 0 {
+			p.next = (index + 1) % len(p.sessions)
+			return p.sessions[index]
+		}
+	}
+	return nil
+}
+
+// close stops accepting new links and waits for the workers to finish the work already queued
+func (p *linkCheckPool) close() {
+	p.mu.Lock()
+	p.closed = true
+	p.cond.Broadcast()
+	p.mu.Unlock()
+
+	p.wg.Wait()
+}
+
+// stats returns a snapshot of the pool utilization and queue depth
+func (p *linkCheckPool) stats() models.LinkCheckPoolStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	queued := 0
+	for _, s := range p.sessions {
+		queued += len(s.pending)
+	}
+
+	return models.LinkCheckPoolStats{
+		Workers:         p.workers,
+		BusyWorkers:     p.busy,
+		ActiveAnalyses:  len(p.sessions),
+		QueuedLinks:     queued,
+		CompletedChecks: p.completed,
+		CancelledChecks: p.cancelled,
+	}
+}
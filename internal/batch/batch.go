@@ -0,0 +1,109 @@
+package batch
+
+import (
+	"WebAppAnalyzer/internal/models"
+	"bufio"
+	"context"
+	"encoding/csv"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+const defaultParallelism = 4
+
+// Analyzer runs the analysis of a single page
+type Analyzer interface {
+	Analyze(ctx context.Context, url string) *models.AnalysisResult
+}
+
+// Run analyzes the URLs with at most parallelism analyses at a time. Each result is passed to emit as soon as
+// it is ready, calls to emit are serialized. URLs not started before the context is cancelled are counted as
+// not analyzed in the returned summary.
+func Run(ctx context.Context, analyzer Analyzer, urls []string, parallelism int, emit func(item models.BatchItem)) *models.BatchSummary {
+	if parallelism <= 0 {
+		parallelism = defaultParallelism
+	}
+
+	startTime := time.Now()
+	summary := models.NewBatchSummary(len(urls))
+
+	indexes := make(chan int)
+	items := make(chan models.BatchItem)
+
+	go func() {
+		defer close(indexes)
+		for i := range urls {
+			select {
+			case indexes <- i:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for i := 0; i < min(parallelism, len(urls)); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range indexes {
+				analysisStart := time.Now()
+				result := analyzer.Analyze(ctx, urls[index])
+				result.AnalysisTime = time.Since(analysisStart).String()
+
+				items <- models.BatchItem{
+					Type:   models.BatchLineResult,
+					Index:  index,
+					URL:    urls[index],
+					Result: result,
+				}
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(items)
+	}()
+
+	for item := range items {
+		summary.Add(item.Result)
+		emit(item)
+	}
+
+	summary.NotAnalyzed = summary.TotalURLs - summary.Analyzed
+	summary.BatchTime = time.Since(startTime).String()
+
+	return summary
+}
+
+// ParseURLList reads URLs from a newline separated list or a CSV file, taking the first column of every
+// record. Blank lines, lines starting with # and a leading "url" header are ignored.
+func ParseURLList(r io.Reader) ([]string, error) {
+	reader := csv.NewReader(bufio.NewReader(r))
+	reader.FieldsPerRecord = -1
+	reader.Comment = '#'
+	reader.TrimLeadingSpace = true
+
+	urls := make([]string, 0)
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		url := strings.TrimSpace(record[0])
+		if url == "" || (len(urls) == 0 && strings.EqualFold(url, "url")) {
+			continue
+		}
+		urls = append(urls, url)
+	}
+
+	return urls, nil
+}
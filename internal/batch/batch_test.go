@@ -0,0 +1,125 @@
+package batch
+
+import (
+	"WebAppAnalyzer/internal/models"
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// analyzerFunc adapts a function to the Analyzer interface
+type analyzerFunc func(ctx context.Context, url string) *models.AnalysisResult
+
+func (f analyzerFunc) Analyze(ctx context.Context, url string) *models.AnalysisResult {
+	return f(ctx, url)
+}
+
+// TestRun tests that every URL is analyzed with bounded parallelism and counted in the summary
+func TestRun(t *testing.T) {
+	var mu sync.Mutex
+	running, maxRunning := 0, 0
+
+	analyzer := analyzerFunc(func(ctx context.Context, url string) *models.AnalysisResult {
+		mu.Lock()
+		running++
+		maxRunning = max(maxRunning, running)
+		mu.Unlock()
+
+		time.Sleep(10 * time.Millisecond)
+
+		mu.Lock()
+		running--
+		mu.Unlock()
+
+		result := models.NewAnalysisResult(url)
+		result.InternalLinks = 2
+		if strings.Contains(url, "broken") {
+			result.SetError("HTTP Error: 404 - Not Found", 404)
+		}
+		return result
+	})
+
+	urls := make([]string, 0)
+	for i := 0; i < 10; i++ {
+		urls = append(urls, fmt.Sprintf("https://page%d.com", i))
+	}
+	urls = append(urls, "https://broken.com")
+
+	seen := make(map[int]string)
+	summary := Run(context.Background(), analyzer, urls, 3, func(item models.BatchItem) {
+		seen[item.Index] = item.URL
+	})
+
+	if len(seen) != len(urls) {
+		t.Errorf("Expected %d results, got %d", len(urls), len(seen))
+	}
+	for index, url := range seen {
+		if urls[index] != url {
+			t.Errorf("Expected URL %s at index %d, got %s", urls[index], index, url)
+		}
+	}
+	if maxRunning > 3 {
+		t.Errorf("Expected at most 3 concurrent analyses, got %d", maxRunning)
+	}
+	if summary.Analyzed != 11 || summary.Succeeded != 10 || summary.Failed != 1 || summary.NotAnalyzed != 0 {
+		t.Errorf("Unexpected summary %+v", summary)
+	}
+	if summary.TotalInternalLinks != 22 {
+		t.Errorf("Expected 22 internal links, got %d", summary.TotalInternalLinks)
+	}
+}
+
+// TestRunCancelled tests that URLs not started before cancellation are reported as not analyzed
+func TestRunCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	analyzer := analyzerFunc(func(ctx context.Context, url string) *models.AnalysisResult {
+		cancel()
+		return models.NewAnalysisResult(url)
+	})
+
+	summary := Run(ctx, analyzer, []string{"https://a.com", "https://b.com", "https://c.com"}, 1, func(item models.BatchItem) {})
+
+	if summary.Analyzed == 0 || summary.Analyzed+summary.NotAnalyzed != 3 || summary.NotAnalyzed == 0 {
+		t.Errorf("Expected the batch to stop after cancellation, got %+v", summary)
+	}
+}
+
+// TestParseURLList tests reading URLs from newline separated lists and CSV files
+func TestParseURLList(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []string
+	}{
+		{
+			name:     "Newline list",
+			input:    "https://a.com\n\nhttps://b.com\r\n# comment\nhttps://c.com",
+			expected: []string{"https://a.com", "https://b.com", "https://c.com"},
+		},
+		{
+			name:     "CSV with header",
+			input:    "url,owner\nhttps://a.com,marketing\n\"https://b.com\",sales\n",
+			expected: []string{"https://a.com", "https://b.com"},
+		},
+		{
+			name:     "Empty",
+			input:    "",
+			expected: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			urls, err := ParseURLList(strings.NewReader(tt.input))
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if strings.Join(urls, ",") != strings.Join(tt.expected, ",") {
+				t.Errorf("Expected %v, got %v", tt.expected, urls)
+			}
+		})
+	}
+}
@@ -0,0 +1,406 @@
+package cli
+
+import (
+	"WebAppAnalyzer/config/env"
+	"WebAppAnalyzer/internal/batch"
+	"WebAppAnalyzer/internal/models"
+	"WebAppAnalyzer/internal/monitor"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// Exit codes of the analyze and batch commands
+const (
+	ExitOK = 0
+	// ExitViolations means at least one page violated a threshold, so CI jobs can fail on it
+	ExitViolations = 1
+	// ExitError means the command could not run, because of bad arguments, an unreadable URL list or an interrupt
+	ExitError = 2
+)
+
+// Output formats
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+const usage = `Usage: web-analyzer <command> [flags] [arguments]
+
+Commands:
+  serve                   Start the web server (the default without a command)
+  analyze <url>           Analyze a web page and print a summary
+  analyze -file <path> -base <url>
+                          Analyze a local HTML file, or stdin when the path is -, as if it was served at the base URL
+  batch <file>            Analyze the URLs listed in a file, or in stdin when the file is -
+  site <dir> -base <url>  Analyze every HTML page of a static site build offline, as if it was served at the base URL
+
+Flags of analyze and batch:
+  -format text|json       Print a human-readable summary or JSON (NDJSON for batch)
+  -max-broken-links N     Fail when a page has more than N inaccessible links
+  -fail-on-error          Fail when a page cannot be fetched or analyzed (default true)
+  -fail-on-missing-title  Fail when a page has no title
+  -fail-on-login-form     Fail when a page has a login form
+  -timeout duration       Give up after this long, such as 2m
+  -parallelism N          Number of pages analyzed at a time (batch only)
+  -max-pages N            Number of pages analyzed at most (site only)
+
+The exit code is 0 when every page passes, 1 when a threshold is violated and 2 when the command cannot run.
+`
+
+// Analyzer runs the analysis of a single page, fetched from its URL or read from a local document, and of the
+// pages of a static site build
+type Analyzer interface {
+	Analyze(ctx context.Context, url string) *models.AnalysisResult
+	AnalyzeHTML(ctx context.Context, baseURL string, body io.Reader) *models.AnalysisResult
+	AnalyzeDirectory(ctx context.Context, root, baseURL string, maxPages int) *models.SiteReport
+}
+
+// CLI runs the analyze and batch commands against an analyzer and prints the results
+type CLI struct {
+	analyzer Analyzer
+	config   *env.Config
+	stdin    io.Reader
+	stdout   io.Writer
+	stderr   io.Writer
+}
+
+// options are the flags shared by the analyze and batch commands
+type options struct {
+	format             string
+	maxBrokenLinks     int
+	failOnError        bool
+	failOnMissingTitle bool
+	failOnLoginForm    bool
+	timeout            time.Duration
+	parallelism        int
+	// file is the local HTML document of the analyze command, and base the URL it or the site is served at
+	file     string
+	base     string
+	maxPages int
+}
+
+// New creates a CLI that reads URL lists from stdin and writes results to stdout and errors to stderr
+func New(analyzer Analyzer, c *env.Config, stdin io.Reader, stdout, stderr io.Writer) *CLI {
+	return &CLI{
+		analyzer: analyzer,
+		config:   c,
+		stdin:    stdin,
+		stdout:   stdout,
+		stderr:   stderr,
+	}
+}
+
+// Usage prints the commands and flags
+func Usage(w io.Writer) {
+	fmt.Fprint(w, usage)
+}
+
+// Run runs the command named by the first argument and returns the exit code
+func (c *CLI) Run(ctx context.Context, args []string) int {
+	if len(args) == 0 {
+		Usage(c.stderr)
+		return ExitError
+	}
+
+	switch args[0] {
+	case "analyze":
+		return c.analyze(ctx, args[1:])
+	case "batch":
+		return c.batch(ctx, args[1:])
+	case "site":
+		return c.site(ctx, args[1:])
+	case "help", "-h", "-help", "--help":
+		Usage(c.stdout)
+		return ExitOK
+	default:
+		fmt.Fprintf(c.stderr, "Unknown command %q\n\n", args[0])
+		Usage(c.stderr)
+		return ExitError
+	}
+}
+
+// analyze analyzes a single page
+func (c *CLI) analyze(ctx context.Context, args []string) int {
+	opts, positional, code := c.parseFlags("analyze", args)
+	if code >= 0 {
+		return code
+	}
+	if opts.file != "" && opts.base == "" && len(positional) == 1 {
+		opts.base = positional[0]
+		positional = positional[:0]
+	}
+	if opts.file != "" && (opts.base == "" || len(positional) > 0) {
+		fmt.Fprintln(c.stderr, "analyze -file takes the URL the document is served at as -base")
+		return ExitError
+	}
+	if opts.file == "" && len(positional) != 1 {
+		fmt.Fprintln(c.stderr, "analyze takes exactly one URL")
+		return ExitError
+	}
+
+	ctx, cancel := opts.context(ctx)
+	defer cancel()
+
+	var url string
+	var result *models.AnalysisResult
+	startTime := time.Now()
+	if opts.file != "" {
+		document, err := c.open(opts.file)
+		if err != nil {
+			fmt.Fprintln(c.stderr, "Failed to read the HTML document:", err)
+			return ExitError
+		}
+		defer document.Close()
+
+		url = opts.base
+		result = c.analyzer.AnalyzeHTML(ctx, url, document)
+	} else {
+		url = positional[0]
+		result = c.analyzer.Analyze(ctx, url)
+	}
+	result.AnalysisTime = time.Since(startTime).String()
+
+	report := newPageReport(models.BatchItem{Type: models.BatchLineResult, URL: url, Result: result}, opts.rules())
+	if err := c.writeAnalysis(opts.format, report); err != nil {
+		fmt.Fprintln(c.stderr, "Failed to write the result:", err)
+		return ExitError
+	}
+
+	if ctx.Err() != nil {
+		fmt.Fprintln(c.stderr, "The analysis was interrupted:", ctx.Err())
+		return ExitError
+	}
+	if len(report.Violations) > 0 {
+		return ExitViolations
+	}
+	return ExitOK
+}
+
+// batch analyzes every URL of a list, printing each result as soon as it is ready
+func (c *CLI) batch(ctx context.Context, args []string) int {
+	opts, positional, code := c.parseFlags("batch", args)
+	if code >= 0 {
+		return code
+	}
+	if len(positional) != 1 {
+		fmt.Fprintln(c.stderr, "batch takes exactly one file of URLs, or - to read them from stdin")
+		return ExitError
+	}
+
+	urls, err := c.readURLList(positional[0])
+	if err != nil {
+		fmt.Fprintln(c.stderr, "Failed to read the URL list:", err)
+		return ExitError
+	}
+	if len(urls) == 0 {
+		fmt.Fprintln(c.stderr, "The URL list is empty")
+		return ExitError
+	}
+
+	ctx, cancel := opts.context(ctx)
+	defer cancel()
+
+	rules := opts.rules()
+	failedPages := 0
+	var writeErr error
+	summary := batch.Run(ctx, c.analyzer, urls, opts.parallelism, func(item models.BatchItem) {
+		report := newPageReport(item, rules)
+		if len(report.Violations) > 0 {
+			failedPages++
+		}
+		if writeErr == nil {
+			writeErr = c.writeBatchItem(opts.format, report)
+		}
+	})
+	if writeErr == nil {
+		writeErr = c.writeBatchSummary(opts.format, summary, failedPages)
+	}
+	if writeErr != nil {
+		fmt.Fprintln(c.stderr, "Failed to write the results:", writeErr)
+		return ExitError
+	}
+
+	if ctx.Err() != nil {
+		fmt.Fprintf(c.stderr, "The batch was interrupted, %d of %d URLs were not analyzed: %v\n",
+			summary.NotAnalyzed, summary.TotalURLs, ctx.Err())
+		return ExitError
+	}
+	if failedPages > 0 {
+		return ExitViolations
+	}
+	return ExitOK
+}
+
+// site analyzes the pages of a static site build directory offline
+func (c *CLI) site(ctx context.Context, args []string) int {
+	opts, positional, code := c.parseFlags("site", args)
+	if code >= 0 {
+		return code
+	}
+	if len(positional) != 1 || opts.base == "" {
+		fmt.Fprintln(c.stderr, "site takes exactly one directory and the URL it is served at as -base")
+		return ExitError
+	}
+
+	ctx, cancel := opts.context(ctx)
+	defer cancel()
+
+	report := c.analyzer.AnalyzeDirectory(ctx, positional[0], opts.base, opts.maxPages)
+	if report.Error != "" && report.PagesAnalyzed == 0 {
+		fmt.Fprintln(c.stderr, "Failed to analyze the site:", report.Error)
+		return ExitError
+	}
+
+	siteReport := newSiteReport(report, opts.rules())
+	if err := c.writeSite(opts.format, siteReport); err != nil {
+		fmt.Fprintln(c.stderr, "Failed to write the report:", err)
+		return ExitError
+	}
+
+	if ctx.Err() != nil {
+		fmt.Fprintln(c.stderr, "The analysis was interrupted:", ctx.Err())
+		return ExitError
+	}
+	if len(siteReport.Violations) > 0 {
+		return ExitViolations
+	}
+	return ExitOK
+}
+
+// parseFlags parses the flags of a command, which may come before or after its arguments. The returned code is
+// negative when the command should go on, and the exit code otherwise.
+func (c *CLI) parseFlags(command string, args []string) (options, []string, int) {
+	opts := options{parallelism: c.config.BatchParallelism}
+
+	fs := flag.NewFlagSet(command, flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+	fs.Usage = func() { Usage(c.stderr) }
+	fs.StringVar(&opts.format, "format", FormatText, "output format, text or json")
+	fs.IntVar(&opts.maxBrokenLinks, "max-broken-links", -1, "fail when a page has more inaccessible links")
+	fs.BoolVar(&opts.failOnError, "fail-on-error", true, "fail when a page cannot be analyzed")
+	fs.BoolVar(&opts.failOnMissingTitle, "fail-on-missing-title", false, "fail when a page has no title")
+	fs.BoolVar(&opts.failOnLoginForm, "fail-on-login-form", false, "fail when a page has a login form")
+	fs.DurationVar(&opts.timeout, "timeout", 0, "give up after this long")
+	switch command {
+	case "analyze":
+		fs.StringVar(&opts.file, "file", "", "local HTML document to analyze instead of fetching the URL")
+		fs.StringVar(&opts.base, "base", "", "URL the local HTML document is served at")
+	case "batch":
+		fs.IntVar(&opts.parallelism, "parallelism", opts.parallelism, "pages analyzed at a time")
+	case "site":
+		fs.StringVar(&opts.base, "base", "", "URL the site is served at")
+		fs.IntVar(&opts.maxPages, "max-pages", 0, "pages analyzed at most")
+	}
+
+	positional := make([]string, 0)
+	for {
+		if err := fs.Parse(args); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				return opts, nil, ExitOK
+			}
+			return opts, nil, ExitError
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			break
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+
+	if opts.format != FormatText && opts.format != FormatJSON {
+		fmt.Fprintf(c.stderr, "Unknown format %q, use text or json\n", opts.format)
+		return opts, nil, ExitError
+	}
+	return opts, positional, -1
+}
+
+// readURLList reads the URLs of a batch from a file, or from stdin when the name is -
+func (c *CLI) readURLList(name string) ([]string, error) {
+	file, err := c.open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return batch.ParseURLList(file)
+}
+
+// open opens a file to read, or stdin when the name is -
+func (c *CLI) open(name string) (io.ReadCloser, error) {
+	if name == "-" {
+		return io.NopCloser(c.stdin), nil
+	}
+	return os.Open(name)
+}
+
+// rules translates the threshold flags to the rules monitors use, so both judge pages the same way
+func (o options) rules() []models.MonitorRule {
+	rules := make([]models.MonitorRule, 0)
+	if o.failOnError {
+		rules = append(rules, models.MonitorRule{Type: models.RuleAnalysisFailed})
+	}
+	if o.maxBrokenLinks >= 0 {
+		rules = append(rules, models.MonitorRule{Type: models.RuleMaxBrokenLinks, Threshold: o.maxBrokenLinks})
+	}
+	if o.failOnMissingTitle {
+		rules = append(rules, models.MonitorRule{Type: models.RuleTitleMissing})
+	}
+	if o.failOnLoginForm {
+		rules = append(rules, models.MonitorRule{Type: models.RuleLoginFormPresent})
+	}
+	return rules
+}
+
+func (o options) context(ctx context.Context) (context.Context, context.CancelFunc) {
+	if o.timeout > 0 {
+		return context.WithTimeout(ctx, o.timeout)
+	}
+	return context.WithCancel(ctx)
+}
+
+// pageReport is the result of one page with the thresholds it violated
+type pageReport struct {
+	models.BatchItem
+	Violations []Violation `json:"violations"`
+}
+
+// Violation is a threshold a page did not meet
+type Violation struct {
+	Rule    string `json:"rule" example:"max_broken_links"`
+	Message string `json:"message" example:"3 links are inaccessible, more than the threshold of 0: https://example.com/missing"`
+}
+
+// siteReport is the report of a static site build with the pages that violated a threshold
+type siteReport struct {
+	*models.SiteReport
+	Violations []pageReport `json:"violations"`
+}
+
+func newSiteReport(report *models.SiteReport, rules []models.MonitorRule) siteReport {
+	violations := make([]pageReport, 0)
+	for i, page := range report.Pages {
+		checked := newPageReport(models.BatchItem{Type: models.BatchLineResult, Index: i, URL: page.URL, Result: page.Result}, rules)
+		if len(checked.Violations) > 0 {
+			// The result is already part of the site report
+			checked.Result = nil
+			violations = append(violations, checked)
+		}
+	}
+	return siteReport{SiteReport: report, Violations: violations}
+}
+
+func newPageReport(item models.BatchItem, rules []models.MonitorRule) pageReport {
+	alerts := monitor.Evaluate(models.Monitor{URL: item.URL, Rules: rules}, nil, item.Result)
+
+	violations := make([]Violation, 0, len(alerts))
+	for _, alert := range alerts {
+		violations = append(violations, Violation{Rule: alert.Rule, Message: alert.Message})
+	}
+	return pageReport{BatchItem: item, Violations: violations}
+}
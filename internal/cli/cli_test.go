@@ -0,0 +1,241 @@
+package cli
+
+import (
+	"WebAppAnalyzer/config/env"
+	"WebAppAnalyzer/internal/models"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// testAnalyzer returns a page with a title and one broken link, or a failure for URLs containing "down". Local
+// documents only have a title when they contain a title element.
+type testAnalyzer struct{}
+
+func (testAnalyzer) Analyze(ctx context.Context, url string) *models.AnalysisResult {
+	result := models.NewAnalysisResult(url)
+	if strings.Contains(url, "down") {
+		result.SetError("HTTP Error: 503 - Service Unavailable", 503)
+		return result
+	}
+	result.PageTitle = "Example Domain"
+	result.Headings["h1"] = 1
+	result.InternalLinks = 2
+	result.InaccessibleLinks = 1
+	result.Links = append(result.Links,
+		models.LinkDetail{URL: url + "/about", IsAccessible: true},
+		models.LinkDetail{URL: url + "/missing", Error: "HTTP 404 Not Found"},
+	)
+	return result
+}
+
+func (a testAnalyzer) AnalyzeHTML(ctx context.Context, baseURL string, body io.Reader) *models.AnalysisResult {
+	document, _ := io.ReadAll(body)
+	result := a.Analyze(ctx, baseURL)
+	if !strings.Contains(string(document), "<title>") {
+		result.PageTitle = ""
+	}
+	return result
+}
+
+func (a testAnalyzer) AnalyzeDirectory(ctx context.Context, root, baseURL string, maxPages int) *models.SiteReport {
+	report := models.NewSiteReport(baseURL, models.CrawlOptions{MaxPages: maxPages})
+	report.Directory = root
+	if _, err := os.Stat(root); err != nil {
+		report.Error = "Not a directory: " + root
+		return report
+	}
+	for _, page := range []string{"", "about/"} {
+		report.AddPage(models.SitePage{URL: baseURL + "/" + page, Result: a.Analyze(ctx, baseURL+"/"+page)})
+	}
+	report.MissingFiles = []models.MissingFile{{URL: baseURL + "/team/", LinkedFrom: []string{baseURL + "/"}}}
+	return report
+}
+
+func runCLI(stdin string, args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	code := New(testAnalyzer{}, &env.Config{}, strings.NewReader(stdin), &stdout, &stderr).Run(context.Background(), args)
+	return code, stdout.String(), stderr.String()
+}
+
+// TestAnalyze tests the text summary of a page and the exit code when a threshold is violated
+func TestAnalyze(t *testing.T) {
+	code, stdout, _ := runCLI("", "analyze", "https://example.com")
+	if code != ExitOK {
+		t.Fatalf("Expected exit code %d without thresholds, got %d", ExitOK, code)
+	}
+	for _, expected := range []string{"Status:", "PASS", "Example Domain", "h1: 1", "2 internal, 0 external, 1 inaccessible", "https://example.com/missing"} {
+		if !strings.Contains(stdout, expected) {
+			t.Errorf("Expected the summary to contain %q, got:\n%s", expected, stdout)
+		}
+	}
+
+	// Flags may follow the URL
+	code, stdout, _ = runCLI("", "analyze", "https://example.com", "-max-broken-links", "0")
+	if code != ExitViolations {
+		t.Errorf("Expected exit code %d with a broken link over the threshold, got %d", ExitViolations, code)
+	}
+	if !strings.Contains(stdout, "FAIL") || !strings.Contains(stdout, models.RuleMaxBrokenLinks) {
+		t.Errorf("Expected the violation in the summary, got:\n%s", stdout)
+	}
+
+	if code, _, _ := runCLI("", "analyze", "-max-broken-links", "1", "https://example.com"); code != ExitOK {
+		t.Errorf("Expected exit code %d within the threshold, got %d", ExitOK, code)
+	}
+	if code, _, _ := runCLI("", "analyze", "https://down.example.com"); code != ExitViolations {
+		t.Errorf("Expected exit code %d for a failed analysis, got %d", ExitViolations, code)
+	}
+	if code, _, _ := runCLI("", "analyze", "-fail-on-error=false", "https://down.example.com"); code != ExitOK {
+		t.Errorf("Expected a failed analysis to pass with -fail-on-error=false, got %d", code)
+	}
+}
+
+// TestAnalyze_JSON tests that the JSON output holds the result and the violations
+func TestAnalyze_JSON(t *testing.T) {
+	code, stdout, _ := runCLI("", "analyze", "-format", "json", "-fail-on-login-form", "-max-broken-links", "0", "https://example.com")
+	if code != ExitViolations {
+		t.Fatalf("Expected exit code %d, got %d", ExitViolations, code)
+	}
+
+	var report pageReport
+	if err := json.Unmarshal([]byte(stdout), &report); err != nil {
+		t.Fatalf("Expected JSON output, got %v:\n%s", err, stdout)
+	}
+	if report.Result == nil || report.Result.PageTitle != "Example Domain" {
+		t.Errorf("Expected the analysis result, got %+v", report.Result)
+	}
+	if len(report.Violations) != 1 || report.Violations[0].Rule != models.RuleMaxBrokenLinks {
+		t.Errorf("Expected only the broken links violation, got %+v", report.Violations)
+	}
+}
+
+// TestAnalyze_File tests the analysis of local HTML documents from a file and from stdin
+func TestAnalyze_File(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "index.html")
+	if err := os.WriteFile(path, []byte("<html><head><title>Home</title></head></html>"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	code, stdout, _ := runCLI("", "analyze", "--file", path, "--base", "https://example.com", "-fail-on-missing-title")
+	if code != ExitOK {
+		t.Errorf("Expected exit code %d, got %d", ExitOK, code)
+	}
+	if !strings.Contains(stdout, "https://example.com") {
+		t.Errorf("Expected the base URL in the summary, got:\n%s", stdout)
+	}
+
+	// The base URL may also be given as the argument
+	code, _, _ = runCLI("<html><body></body></html>", "analyze", "-file", "-", "-fail-on-missing-title", "https://example.com")
+	if code != ExitViolations {
+		t.Errorf("Expected exit code %d for a document without a title, got %d", ExitViolations, code)
+	}
+
+	for _, args := range [][]string{
+		{"analyze", "-file", path},
+		{"analyze", "-file", path, "-base", "https://example.com", "https://example.org"},
+		{"analyze", "-file", filepath.Join(t.TempDir(), "missing.html"), "-base", "https://example.com"},
+	} {
+		if code, _, _ := runCLI("", args...); code != ExitError {
+			t.Errorf("Expected exit code %d for %v, got %d", ExitError, args, code)
+		}
+	}
+}
+
+// TestSite tests the report of a static site build and the pages violating thresholds
+func TestSite(t *testing.T) {
+	root := t.TempDir()
+
+	code, stdout, _ := runCLI("", "site", root, "-base", "https://example.com")
+	if code != ExitOK {
+		t.Errorf("Expected exit code %d, got %d", ExitOK, code)
+	}
+	for _, expected := range []string{"2 analyzed, 0 failed", "https://example.com/team/  linked from https://example.com/"} {
+		if !strings.Contains(stdout, expected) {
+			t.Errorf("Expected the report to contain %q, got:\n%s", expected, stdout)
+		}
+	}
+
+	code, stdout, _ = runCLI("", "site", "-format", "json", "-max-broken-links", "0", "-base", "https://example.com", root)
+	if code != ExitViolations {
+		t.Errorf("Expected exit code %d with broken links, got %d", ExitViolations, code)
+	}
+	var report siteReport
+	if err := json.Unmarshal([]byte(stdout), &report); err != nil {
+		t.Fatalf("Expected JSON output, got %v:\n%s", err, stdout)
+	}
+	if report.PagesAnalyzed != 2 || len(report.Violations) != 2 || len(report.MissingFiles) != 1 {
+		t.Errorf("Expected 2 pages violating the threshold, got %+v", report)
+	}
+
+	for _, args := range [][]string{
+		{"site", root},
+		{"site", "-base", "https://example.com"},
+		{"site", filepath.Join(root, "missing"), "-base", "https://example.com"},
+	} {
+		if code, _, _ := runCLI("", args...); code != ExitError {
+			t.Errorf("Expected exit code %d for %v, got %d", ExitError, args, code)
+		}
+	}
+}
+
+// TestBatch tests a batch read from a file and from stdin
+func TestBatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "urls.txt")
+	if err := os.WriteFile(path, []byte("https://example.com\nhttps://example.org\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	code, stdout, _ := runCLI("", "batch", path)
+	if code != ExitOK {
+		t.Errorf("Expected exit code %d, got %d", ExitOK, code)
+	}
+	if !strings.Contains(stdout, "Analyzed 2 of 2 URLs: 2 succeeded, 0 failed, 0 violated a threshold") {
+		t.Errorf("Expected the batch summary, got:\n%s", stdout)
+	}
+
+	code, stdout, _ = runCLI("url\nhttps://example.com\nhttps://down.example.com\n", "batch", "-format", "json", "-parallelism", "1", "-")
+	if code != ExitViolations {
+		t.Errorf("Expected exit code %d with a failed page, got %d", ExitViolations, code)
+	}
+
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected 2 results and a summary, got:\n%s", stdout)
+	}
+	var summary batchSummaryReport
+	if err := json.Unmarshal([]byte(lines[2]), &summary); err != nil {
+		t.Fatalf("Expected a JSON summary, got %v", err)
+	}
+	if summary.Type != models.BatchLineSummary || summary.Failed != 1 || summary.PagesWithViolations != 1 {
+		t.Errorf("Expected 1 failed page in the summary, got %+v", summary.BatchSummary)
+	}
+}
+
+// TestRun_Usage tests that bad commands and arguments exit with the usage error code
+func TestRun_Usage(t *testing.T) {
+	tests := [][]string{
+		{},
+		{"unknown"},
+		{"analyze"},
+		{"analyze", "https://example.com", "https://example.org"},
+		{"analyze", "-format", "xml", "https://example.com"},
+		{"analyze", "-unknown-flag", "https://example.com"},
+		{"batch"},
+		{"batch", filepath.Join(t.TempDir(), "missing.txt")},
+	}
+
+	for _, args := range tests {
+		if code, _, _ := runCLI("", args...); code != ExitError {
+			t.Errorf("Expected exit code %d for %v, got %d", ExitError, args, code)
+		}
+	}
+
+	if code, stdout, _ := runCLI("", "help"); code != ExitOK || !strings.Contains(stdout, "Usage:") {
+		t.Errorf("Expected the usage with exit code %d, got %d", ExitOK, code)
+	}
+}
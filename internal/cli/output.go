@@ -0,0 +1,189 @@
+package cli
+
+import (
+	"WebAppAnalyzer/internal/models"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// batchSummaryReport is the last line of the batch output, the batch summary with the number of pages that
+// violated a threshold
+type batchSummaryReport struct {
+	*models.BatchSummary
+	PagesWithViolations int `json:"pages_with_violations"`
+}
+
+// writeAnalysis prints the result of the analyze command, an indented JSON object or a detailed summary
+func (c *CLI) writeAnalysis(format string, report pageReport) error {
+	if format == FormatJSON {
+		encoder := json.NewEncoder(c.stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	}
+
+	result := report.Result
+	w := tabwriter.NewWriter(c.stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "URL:\t%s\n", report.URL)
+	fmt.Fprintf(w, "Status:\t%s\n", passOrFail(report))
+	if !result.IsSuccessful() {
+		fmt.Fprintf(w, "Error:\t%s\n", result.Error)
+	} else {
+		fmt.Fprintf(w, "HTTP status:\t%d\n", result.HTTPStatusCode)
+		fmt.Fprintf(w, "Title:\t%s\n", result.PageTitle)
+		fmt.Fprintf(w, "HTML version:\t%s\n", result.HTMLVersion)
+		fmt.Fprintf(w, "Headings:\t%s\n", headingCounts(result.Headings))
+		fmt.Fprintf(w, "Links:\t%d internal, %d external, %d inaccessible\n",
+			result.InternalLinks, result.ExternalLinks, result.InaccessibleLinks)
+		if !result.LinkCheckComplete {
+			fmt.Fprintf(w, "Skipped links:\t%d\n", len(result.SkippedLinks))
+		}
+		fmt.Fprintf(w, "Login form:\t%s\n", yesOrNo(result.HasLoginForm))
+	}
+	fmt.Fprintf(w, "Analysis time:\t%s\n", result.AnalysisTime)
+	if timing := result.FetchTiming; timing != nil {
+		fmt.Fprintf(w, "Fetch timing:\tDNS %.1fms, connect %.1fms, TLS %.1fms, first byte %.1fms, download %.1fms\n",
+			timing.DNSLookupMs, timing.TCPConnectMs, timing.TLSHandshakeMs, timing.TimeToFirstByteMs, timing.ContentDownloadMs)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	if broken := result.BrokenLinks(); len(broken) > 0 {
+		fmt.Fprintln(c.stdout, "\nInaccessible links:")
+		for _, link := range broken {
+			fmt.Fprintf(c.stdout, "  %s  %s\n", link.URL, link.Error)
+		}
+	}
+
+	if len(report.Violations) > 0 {
+		fmt.Fprintln(c.stdout, "\nViolations:")
+		c.writeViolations(report.Violations)
+	}
+	return nil
+}
+
+// writeBatchItem prints the result of one URL of a batch, an NDJSON line or a one line summary
+func (c *CLI) writeBatchItem(format string, report pageReport) error {
+	if format == FormatJSON {
+		return json.NewEncoder(c.stdout).Encode(report)
+	}
+
+	result := report.Result
+	details := fmt.Sprintf("%d links, %d inaccessible", result.InternalLinks+result.ExternalLinks, result.InaccessibleLinks)
+	if !result.IsSuccessful() {
+		details = result.Error
+	}
+	if _, err := fmt.Fprintf(c.stdout, "%s  %s  %s  %s\n", passOrFail(report), report.URL, details, result.AnalysisTime); err != nil {
+		return err
+	}
+	c.writeViolations(report.Violations)
+	return nil
+}
+
+// writeBatchSummary prints the totals of a batch after all of its results
+func (c *CLI) writeBatchSummary(format string, summary *models.BatchSummary, pagesWithViolations int) error {
+	if format == FormatJSON {
+		return json.NewEncoder(c.stdout).Encode(batchSummaryReport{
+			BatchSummary:        summary,
+			PagesWithViolations: pagesWithViolations,
+		})
+	}
+
+	fmt.Fprintf(c.stdout, "\nAnalyzed %d of %d URLs: %d succeeded, %d failed, %d violated a threshold\n",
+		summary.Analyzed, summary.TotalURLs, summary.Succeeded, summary.Failed, pagesWithViolations)
+	fmt.Fprintf(c.stdout, "Links: %d internal, %d external, %d inaccessible\n",
+		summary.TotalInternalLinks, summary.TotalExternalLinks, summary.TotalInaccessibleLinks)
+	fmt.Fprintf(c.stdout, "Pages with a login form: %d\n", summary.PagesWithLoginForm)
+	_, err := fmt.Fprintf(c.stdout, "Batch time: %s\n", summary.BatchTime)
+	return err
+}
+
+// writeSite prints the report of a static site build, an indented JSON object or a summary with the missing
+// files and the pages that violated a threshold
+func (c *CLI) writeSite(format string, report siteReport) error {
+	if format == FormatJSON {
+		encoder := json.NewEncoder(c.stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	}
+
+	status := "PASS"
+	if len(report.Violations) > 0 {
+		status = "FAIL"
+	}
+	w := tabwriter.NewWriter(c.stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "Directory:\t%s\n", report.Directory)
+	fmt.Fprintf(w, "Base URL:\t%s\n", report.SeedURL)
+	fmt.Fprintf(w, "Status:\t%s\n", status)
+	if report.Error != "" {
+		fmt.Fprintf(w, "Error:\t%s\n", report.Error)
+	}
+	fmt.Fprintf(w, "Pages:\t%d analyzed, %d failed\n", report.PagesAnalyzed, report.PagesFailed)
+	if report.BudgetExhausted {
+		fmt.Fprintf(w, "Page limit:\t%d reached, the remaining pages were not analyzed\n", report.MaxPages)
+	}
+	fmt.Fprintf(w, "Links:\t%d internal, %d external, %d inaccessible\n",
+		report.TotalInternalLinks, report.TotalExternalLinks, report.TotalInaccessibleLinks)
+	fmt.Fprintf(w, "Missing files:\t%d\n", len(report.MissingFiles))
+	fmt.Fprintf(w, "Analysis time:\t%s\n", report.CrawlTime)
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	if len(report.MissingFiles) > 0 {
+		fmt.Fprintln(c.stdout, "\nMissing files:")
+		for _, missing := range report.MissingFiles {
+			fmt.Fprintf(c.stdout, "  %s  linked from %s\n", missing.URL, strings.Join(missing.LinkedFrom, ", "))
+		}
+	}
+
+	if len(report.Violations) > 0 {
+		fmt.Fprintln(c.stdout, "\nViolations:")
+		for _, page := range report.Violations {
+			fmt.Fprintf(c.stdout, "%s  %s\n", passOrFail(page), page.URL)
+			c.writeViolations(page.Violations)
+		}
+	}
+	return nil
+}
+
+func (c *CLI) writeViolations(violations []Violation) {
+	for _, violation := range violations {
+		fmt.Fprintf(c.stdout, "  - %s: %s\n", violation.Rule, violation.Message)
+	}
+}
+
+func passOrFail(report pageReport) string {
+	if len(report.Violations) > 0 {
+		return "FAIL"
+	}
+	return "PASS"
+}
+
+func yesOrNo(value bool) string {
+	if value {
+		return "yes"
+	}
+	return "no"
+}
+
+// headingCounts lists the heading counts by level, such as "h1: 1, h2: 4"
+func headingCounts(headings map[string]int) string {
+	levels := make([]string, 0, len(headings))
+	for level := range headings {
+		levels = append(levels, level)
+	}
+	sort.Strings(levels)
+
+	counts := make([]string, 0, len(levels))
+	for _, level := range levels {
+		counts = append(counts, fmt.Sprintf("%s: %d", level, headings[level]))
+	}
+	if len(counts) == 0 {
+		return "none"
+	}
+	return strings.Join(counts, ", ")
+}
@@ -0,0 +1,230 @@
+package diff
+
+import (
+	"WebAppAnalyzer/internal/models"
+	"sort"
+	"strings"
+)
+
+// Compare returns what changed from the base analysis to the target analysis, usually two analyses of the same
+// page taken before and after a deploy. List sections are matched by a key per item (the link URL, image source,
+// meta tag name and property, ...), and items sharing a key are matched in order of appearance.
+func Compare(base, target *models.AnalysisResult) *models.AnalysisDiff {
+	diff := &models.AnalysisDiff{
+		Base:     side(base),
+		Target:   side(target),
+		Fields:   fieldChanges(base, target),
+		Headings: headingChanges(base.Headings, target.Headings),
+		Links:    linkChanges(base.Links, target.Links),
+	}
+
+	diff.Images.Added, diff.Images.Removed, diff.Images.Changed = diffItems(base.Images, target.Images,
+		func(image models.ImageInfo) string { return image.Src },
+		same[models.ImageInfo],
+		func(before, after models.ImageInfo) models.ImageChange {
+			return models.ImageChange{Before: before, After: after}
+		})
+	diff.MetaTags.Added, diff.MetaTags.Removed, diff.MetaTags.Changed = diffItems(base.MetaTags, target.MetaTags,
+		func(tag models.MetaTag) string { return tag.Name + "|" + tag.Property },
+		same[models.MetaTag],
+		func(before, after models.MetaTag) models.MetaTagChange {
+			return models.MetaTagChange{Before: before, After: after}
+		})
+	diff.Scripts.Added, diff.Scripts.Removed, diff.Scripts.Changed = diffItems(base.Scripts, target.Scripts,
+		func(script models.ScriptInfo) string { return script.Src },
+		same[models.ScriptInfo],
+		func(before, after models.ScriptInfo) models.ScriptChange {
+			return models.ScriptChange{Before: before, After: after}
+		})
+	diff.Stylesheets.Added, diff.Stylesheets.Removed, diff.Stylesheets.Changed = diffItems(base.Stylesheets, target.Stylesheets,
+		func(stylesheet models.StylesheetInfo) string { return stylesheet.Href },
+		same[models.StylesheetInfo],
+		func(before, after models.StylesheetInfo) models.StylesheetChange {
+			return models.StylesheetChange{Before: before, After: after}
+		})
+	diff.Forms.Added, diff.Forms.Removed, diff.Forms.Changed = diffItems(base.Forms, target.Forms,
+		func(form models.FormInfo) string { return form.Action + "|" + strings.ToUpper(form.Method) },
+		same[models.FormInfo],
+		func(before, after models.FormInfo) models.FormChange {
+			return models.FormChange{Before: before, After: after}
+		})
+
+	diff.Summary = summarize(diff)
+	return diff
+}
+
+func side(result *models.AnalysisResult) models.DiffSide {
+	return models.DiffSide{ID: result.ID, URL: result.URL, Timestamp: result.Timestamp}
+}
+
+// fieldChanges compares the single valued parts of the results: page details, counts, text content and accessibility flags
+func fieldChanges(base, target *models.AnalysisResult) []models.FieldChange {
+	fields := []models.FieldChange{
+		{Field: "html_version", Before: base.HTMLVersion, After: target.HTMLVersion},
+		{Field: "page_title", Before: base.PageTitle, After: target.PageTitle},
+		{Field: "http_status_code", Before: base.HTTPStatusCode, After: target.HTTPStatusCode},
+		{Field: "error", Before: base.Error, After: target.Error},
+		{Field: "has_login_form", Before: base.HasLoginForm, After: target.HasLoginForm},
+		{Field: "link_check_complete", Before: base.LinkCheckComplete, After: target.LinkCheckComplete},
+		{Field: "internal_links", Before: base.InternalLinks, After: target.InternalLinks},
+		{Field: "external_links", Before: base.ExternalLinks, After: target.ExternalLinks},
+		{Field: "inaccessible_links", Before: base.InaccessibleLinks, After: target.InaccessibleLinks},
+		{Field: "inaccessible_internal_links", Before: base.InaccessibleInternalLinks, After: target.InaccessibleInternalLinks},
+		{Field: "inaccessible_external_links", Before: base.InaccessibleExternalLinks, After: target.InaccessibleExternalLinks},
+		{Field: "images", Before: len(base.Images), After: len(target.Images)},
+		{Field: "meta_tags", Before: len(base.MetaTags), After: len(target.MetaTags)},
+		{Field: "scripts", Before: len(base.Scripts), After: len(target.Scripts)},
+		{Field: "stylesheets", Before: len(base.Stylesheets), After: len(target.Stylesheets)},
+		{Field: "forms", Before: len(base.Forms), After: len(target.Forms)},
+		{Field: "tables", Before: base.Tables, After: target.Tables},
+		{Field: "lists", Before: base.Lists, After: target.Lists},
+		{Field: "buttons", Before: base.Buttons, After: target.Buttons},
+		{Field: "inputs", Before: base.Inputs, After: target.Inputs},
+		{Field: "text_content.word_count", Before: base.TextContent.WordCount, After: target.TextContent.WordCount},
+		{Field: "text_content.char_count", Before: base.TextContent.CharCount, After: target.TextContent.CharCount},
+		{Field: "text_content.paragraphs", Before: base.TextContent.Paragraphs, After: target.TextContent.Paragraphs},
+		{Field: "text_content.has_main_content", Before: base.TextContent.HasMainContent, After: target.TextContent.HasMainContent},
+		{Field: "accessibility.has_alt_text", Before: base.Accessibility.HasAltText, After: target.Accessibility.HasAltText},
+		{Field: "accessibility.has_aria_labels", Before: base.Accessibility.HasARIALabels, After: target.Accessibility.HasARIALabels},
+		{Field: "accessibility.has_semantic_html", Before: base.Accessibility.HasSemanticHTML, After: target.Accessibility.HasSemanticHTML},
+		{Field: "accessibility.has_skip_links", Before: base.Accessibility.HasSkipLinks, After: target.Accessibility.HasSkipLinks},
+	}
+
+	changes := make([]models.FieldChange, 0)
+	for _, field := range fields {
+		if field.Before != field.After {
+			changes = append(changes, field)
+		}
+	}
+	return changes
+}
+
+func headingChanges(base, target map[string]int) []models.HeadingChange {
+	levels := make([]string, 0, len(base)+len(target))
+	for level := range base {
+		levels = append(levels, level)
+	}
+	for level := range target {
+		if _, ok := base[level]; !ok {
+			levels = append(levels, level)
+		}
+	}
+	sort.Strings(levels)
+
+	changes := make([]models.HeadingChange, 0)
+	for _, level := range levels {
+		if base[level] != target[level] {
+			changes = append(changes, models.HeadingChange{Level: level, Before: base[level], After: target[level]})
+		}
+	}
+	return changes
+}
+
+func linkChanges(base, target []models.LinkDetail) models.LinkChanges {
+	var changes models.LinkChanges
+	changes.Added, changes.Removed, changes.Changed = diffItems(base, target,
+		func(link models.LinkDetail) string { return link.URL },
+		sameLinkOutcome,
+		func(before, after models.LinkDetail) models.LinkChange {
+			return models.LinkChange{URL: after.URL, Before: before, After: after}
+		})
+
+	wasBroken := make(map[string]bool, len(base))
+	for _, link := range base {
+		wasBroken[link.URL] = !link.IsAccessible
+	}
+
+	changes.NewlyBroken = make([]models.LinkDetail, 0)
+	changes.Fixed = make([]models.LinkDetail, 0)
+	for _, link := range target {
+		broken, found := wasBroken[link.URL]
+		switch {
+		case !link.IsAccessible && !broken:
+			changes.NewlyBroken = append(changes.NewlyBroken, link)
+		case link.IsAccessible && found && broken:
+			changes.Fixed = append(changes.Fixed, link)
+		}
+	}
+	return changes
+}
+
+// sameLinkOutcome ignores the timing and caching details that differ between any two checks of a link
+func sameLinkOutcome(a, b models.LinkDetail) bool {
+	return a.AnchorText == b.AnchorText &&
+		a.IsExternal == b.IsExternal &&
+		a.IsAccessible == b.IsAccessible &&
+		a.StatusCode == b.StatusCode &&
+		a.ErrorClass == b.ErrorClass &&
+		a.FinalURL == b.FinalURL
+}
+
+func same[T comparable](a, b T) bool {
+	return a == b
+}
+
+// diffItems matches the items of two lists by key and returns the items only in after, the items only in before,
+// and the matched pairs that are not equal. Items sharing a key are matched in order of appearance.
+func diffItems[T any, C any](before, after []T, key func(T) string, equal func(a, b T) bool, change func(before, after T) C) ([]T, []T, []C) {
+	unmatched := make(map[string][]int, len(before))
+	for i, item := range before {
+		k := key(item)
+		unmatched[k] = append(unmatched[k], i)
+	}
+
+	added := make([]T, 0)
+	changed := make([]C, 0)
+	matched := make([]bool, len(before))
+	for _, item := range after {
+		k := key(item)
+		candidates := unmatched[k]
+		if len(candidates) == 0 {
+			added = append(added, item)
+			continue
+		}
+		i := candidates[0]
+		unmatched[k] = candidates[1:]
+		matched[i] = true
+		if !equal(before[i], item) {
+			changed = append(changed, change(before[i], item))
+		}
+	}
+
+	removed := make([]T, 0)
+	for i, item := range before {
+		if !matched[i] {
+			removed = append(removed, item)
+		}
+	}
+	return added, removed, changed
+}
+
+func summarize(diff *models.AnalysisDiff) models.DiffSummary {
+	summary := models.DiffSummary{
+		NewlyBrokenLinks: len(diff.Links.NewlyBroken),
+		FixedLinks:       len(diff.Links.Fixed),
+		RemovedMetaTags:  len(diff.MetaTags.Removed),
+		ChangedHeadings:  len(diff.Headings),
+		AddedLinks:       len(diff.Links.Added),
+		RemovedLinks:     len(diff.Links.Removed),
+	}
+	for _, script := range diff.Scripts.Added {
+		if script.IsExternal {
+			summary.AddedExternalScripts++
+		}
+	}
+	for _, field := range diff.Fields {
+		if strings.HasPrefix(field.Field, "accessibility.") {
+			summary.ChangedAccessibility++
+		}
+	}
+
+	summary.Changes = len(diff.Fields) + len(diff.Headings) +
+		len(diff.Links.Added) + len(diff.Links.Removed) + len(diff.Links.Changed) +
+		len(diff.Images.Added) + len(diff.Images.Removed) + len(diff.Images.Changed) +
+		len(diff.MetaTags.Added) + len(diff.MetaTags.Removed) + len(diff.MetaTags.Changed) +
+		len(diff.Scripts.Added) + len(diff.Scripts.Removed) + len(diff.Scripts.Changed) +
+		len(diff.Stylesheets.Added) + len(diff.Stylesheets.Removed) + len(diff.Stylesheets.Changed) +
+		len(diff.Forms.Added) + len(diff.Forms.Removed) + len(diff.Forms.Changed)
+	summary.HasChanges = summary.Changes > 0
+	return summary
+}
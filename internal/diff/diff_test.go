@@ -0,0 +1,140 @@
+package diff
+
+import (
+	"WebAppAnalyzer/internal/models"
+	"testing"
+)
+
+func createBaseResult() *models.AnalysisResult {
+	result := models.NewAnalysisResult("https://example.com")
+	result.PageTitle = "Example"
+	result.Headings["h1"] = 1
+	result.Headings["h2"] = 3
+	result.AddLink(models.LinkDetail{URL: "https://example.com/about", IsAccessible: true, StatusCode: 200})
+	result.AddLink(models.LinkDetail{URL: "https://example.com/old", IsAccessible: false, StatusCode: 404})
+	result.AddLink(models.LinkDetail{URL: "https://example.com/gone", IsAccessible: true, StatusCode: 200})
+	result.MetaTags = []models.MetaTag{
+		{Name: "description", Content: "An example"},
+		{Property: "og:title", Content: "Example"},
+	}
+	result.Scripts = []models.ScriptInfo{{Src: "/app.js"}}
+	result.Accessibility.HasAltText = true
+	return result
+}
+
+// TestCompare tests that the changes of every section are reported
+func TestCompare(t *testing.T) {
+	base := createBaseResult()
+
+	target := createBaseResult()
+	target.PageTitle = "Example v2"
+	target.Headings["h2"] = 4
+	target.Headings["h3"] = 1
+	target.Links = nil
+	target.InaccessibleLinks = 0
+	target.InaccessibleInternalLinks = 0
+	target.AddLink(models.LinkDetail{URL: "https://example.com/about", IsAccessible: false, StatusCode: 500, ResponseTimeMs: 30})
+	target.AddLink(models.LinkDetail{URL: "https://example.com/old", IsAccessible: true, StatusCode: 200})
+	target.AddLink(models.LinkDetail{URL: "https://example.com/new", IsAccessible: false, StatusCode: 404})
+	target.MetaTags = []models.MetaTag{{Property: "og:title", Content: "Example v2"}}
+	target.Scripts = append(target.Scripts, models.ScriptInfo{Src: "https://tracker.example.net/t.js", IsExternal: true})
+	target.Accessibility.HasAltText = false
+
+	diff := Compare(base, target)
+
+	fields := make(map[string]models.FieldChange)
+	for _, field := range diff.Fields {
+		fields[field.Field] = field
+	}
+	for _, name := range []string{"page_title", "inaccessible_links", "inaccessible_internal_links", "scripts", "meta_tags", "accessibility.has_alt_text"} {
+		if _, ok := fields[name]; !ok {
+			t.Errorf("Expected a change of %s, got %+v", name, diff.Fields)
+		}
+	}
+	if len(diff.Fields) != 6 {
+		t.Errorf("Expected 6 field changes, got %+v", diff.Fields)
+	}
+
+	if len(diff.Headings) != 2 || diff.Headings[0] != (models.HeadingChange{Level: "h2", Before: 3, After: 4}) ||
+		diff.Headings[1] != (models.HeadingChange{Level: "h3", Before: 0, After: 1}) {
+		t.Errorf("Expected h2 and h3 heading changes, got %+v", diff.Headings)
+	}
+
+	links := diff.Links
+	if len(links.Added) != 1 || links.Added[0].URL != "https://example.com/new" {
+		t.Errorf("Expected the new link to be added, got %+v", links.Added)
+	}
+	if len(links.Removed) != 1 || links.Removed[0].URL != "https://example.com/gone" {
+		t.Errorf("Expected the gone link to be removed, got %+v", links.Removed)
+	}
+	if len(links.Changed) != 2 {
+		t.Errorf("Expected 2 changed links, got %+v", links.Changed)
+	}
+	if len(links.NewlyBroken) != 2 {
+		t.Errorf("Expected the about and new links to be newly broken, got %+v", links.NewlyBroken)
+	}
+	if len(links.Fixed) != 1 || links.Fixed[0].URL != "https://example.com/old" {
+		t.Errorf("Expected the old link to be fixed, got %+v", links.Fixed)
+	}
+
+	if len(diff.MetaTags.Removed) != 1 || diff.MetaTags.Removed[0].Name != "description" {
+		t.Errorf("Expected the description meta tag to be removed, got %+v", diff.MetaTags.Removed)
+	}
+	if len(diff.MetaTags.Changed) != 1 || diff.MetaTags.Changed[0].After.Content != "Example v2" {
+		t.Errorf("Expected the og:title meta tag to change, got %+v", diff.MetaTags.Changed)
+	}
+
+	expected := models.DiffSummary{
+		HasChanges:           true,
+		Changes:              6 + 2 + 4 + 2 + 1,
+		NewlyBrokenLinks:     2,
+		FixedLinks:           1,
+		AddedExternalScripts: 1,
+		RemovedMetaTags:      1,
+		ChangedHeadings:      2,
+		ChangedAccessibility: 1,
+		AddedLinks:           1,
+		RemovedLinks:         1,
+	}
+	if diff.Summary != expected {
+		t.Errorf("Expected summary %+v, got %+v", expected, diff.Summary)
+	}
+}
+
+// TestCompareUnchanged tests that only timing details differing is not a change
+func TestCompareUnchanged(t *testing.T) {
+	base := createBaseResult()
+	target := createBaseResult()
+	target.Links[0].ResponseTimeMs = 500
+	target.Links[0].Cached = true
+	target.AnalysisTime = "3s"
+
+	diff := Compare(base, target)
+
+	if diff.Summary.HasChanges {
+		t.Errorf("Expected no changes, got %+v", diff)
+	}
+	if diff.Links.Added == nil || diff.Images.Changed == nil || diff.Fields == nil {
+		t.Error("Expected empty lists rather than nil so they encode as []")
+	}
+}
+
+// TestDiffItemsDuplicateKeys tests that items sharing a key are matched in order
+func TestDiffItemsDuplicateKeys(t *testing.T) {
+	before := []models.ImageInfo{{Src: "a.png", Alt: "first"}, {Src: "a.png", Alt: "second"}}
+	after := []models.ImageInfo{{Src: "a.png", Alt: "first"}, {Src: "a.png", Alt: "changed"}, {Src: "a.png"}}
+
+	added, removed, changed := diffItems(before, after,
+		func(image models.ImageInfo) string { return image.Src },
+		same[models.ImageInfo],
+		func(before, after models.ImageInfo) models.ImageChange {
+			return models.ImageChange{Before: before, After: after}
+		})
+
+	if len(added) != 1 || len(removed) != 0 || len(changed) != 1 {
+		t.Fatalf("Expected 1 added and 1 changed image, got %v %v %v", added, removed, changed)
+	}
+	if changed[0].Before.Alt != "second" || changed[0].After.Alt != "changed" {
+		t.Errorf("Expected the second images to be matched, got %+v", changed[0])
+	}
+}
@@ -58,76 +58,2225 @@ const docTemplate = `{
                     }
                 }
             }
+        },
+        "/analyze/html": {
+            "post": {
+                "description": "Analyzes an HTML document sent as the raw request body or uploaded as file, such as a page of a static site built before deployment. Relative links are resolved against the base URL, and the links found are checked like those of a fetched page.",
+                "consumes": [
+                    "text/html",
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Analysis"
+                ],
+                "summary": "Analyze uploaded HTML",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "URL the document will be served at, used to resolve relative links",
+                        "name": "base_url",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "HTML document, when not sent as the request body",
+                        "name": "file",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.AnalysisResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.APIError"
+                        }
+                    },
+                    "413": {
+                        "description": "The document is too large",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/batch": {
+            "post": {
+                "description": "Analyzes a JSON array of URLs, or an uploaded CSV or newline separated list, with bounded parallelism. Results are streamed as newline delimited JSON in completion order, one result line per URL followed by a summary line.",
+                "consumes": [
+                    "application/json",
+                    "text/plain",
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/x-ndjson"
+                ],
+                "tags": [
+                    "Analysis"
+                ],
+                "summary": "Analyze a batch of web pages",
+                "parameters": [
+                    {
+                        "description": "URLs to analyze",
+                        "name": "urls",
+                        "in": "body",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    {
+                        "type": "file",
+                        "description": "CSV or newline separated list of URLs",
+                        "name": "file",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "One line per URL, then a models.BatchSummary line",
+                        "schema": {
+                            "$ref": "#/definitions/models.BatchItem"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.APIError"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/compare": {
+            "post": {
+                "description": "Returns a structured diff across every section of two analyses, usually of the same page before and after a deploy. Each side is a posted analysis result, the ID of a stored analysis, an HTML snapshot analyzed with the given URL as its base, or a URL analyzed on the spot.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Analysis"
+                ],
+                "summary": "Compare two analyses",
+                "parameters": [
+                    {
+                        "description": "The base and target analyses",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CompareRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.AnalysisDiff"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Stored analysis not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.APIError"
+                        }
+                    },
+                    "413": {
+                        "description": "The request is too large",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.APIError"
+                        }
+                    },
+                    "503": {
+                        "description": "History is disabled",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/crawl": {
+            "get": {
+                "description": "Analyzes the seed URL and follows its internal links up to the given depth and page budget",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Analysis"
+                ],
+                "summary": "Crawl a site",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Seed URL to start crawling from",
+                        "name": "url",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum link depth to follow from the seed page",
+                        "name": "depth",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of pages to analyze",
+                        "name": "max_pages",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SiteReport"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.APIError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Analyzes the seed URL and follows its internal links up to the given depth and page budget",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Analysis"
+                ],
+                "summary": "Crawl a site",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Seed URL to start crawling from",
+                        "name": "url",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum link depth to follow from the seed page",
+                        "name": "depth",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of pages to analyze",
+                        "name": "max_pages",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SiteReport"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/history": {
+            "get": {
+                "description": "Lists stored analyses newest first, optionally filtered by URL and date range",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "History"
+                ],
+                "summary": "List past analyses",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Only analyses of this exact URL",
+                        "name": "url",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only analyses at or after this time (RFC 3339 or YYYY-MM-DD)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only analyses at or before this time (RFC 3339 or YYYY-MM-DD, inclusive)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of entries to return (default 50, max 500)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of matching entries to skip",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.HistoryPage"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.APIError"
+                        }
+                    },
+                    "503": {
+                        "description": "History is disabled",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/history/{id}": {
+            "get": {
+                "description": "Returns the full result of a stored analysis",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "History"
+                ],
+                "summary": "Get a past analysis",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Analysis ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.AnalysisResult"
+                        }
+                    },
+                    "404": {
+                        "description": "Analysis not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.APIError"
+                        }
+                    },
+                    "503": {
+                        "description": "History is disabled",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.APIError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Removes a stored analysis from the history",
+                "tags": [
+                    "History"
+                ],
+                "summary": "Delete a past analysis",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Analysis ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Deleted"
+                    },
+                    "404": {
+                        "description": "Analysis not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.APIError"
+                        }
+                    },
+                    "503": {
+                        "description": "History is disabled",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/jobs": {
+            "post": {
+                "description": "Queues the analysis of a web page and returns immediately. Poll the job to follow its progress and get the result, or give a callback URL to have the signed result posted to it once the analysis has finished.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Jobs"
+                ],
+                "summary": "Create an analysis job",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "URL of the web page to analyze",
+                        "name": "url",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "URL the result is posted to once the analysis has finished",
+                        "name": "callback_url",
+                        "in": "query"
+                    },
+                    {
+                        "description": "URL of the web page to analyze and callback URL, when not given as query parameters",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.JobRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/models.Job"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.APIError"
+                        }
+                    },
+                    "503": {
+                        "description": "Job queue is full",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/jobs/{id}": {
+            "get": {
+                "description": "Returns the status and progress of an analysis job, and its result once it has finished",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Jobs"
+                ],
+                "summary": "Get an analysis job",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Job ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Job"
+                        }
+                    },
+                    "404": {
+                        "description": "Job not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.APIError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Cancels a queued or running analysis job. A running job keeps the partial result gathered so far.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Jobs"
+                ],
+                "summary": "Cancel an analysis job",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Job ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Job"
+                        }
+                    },
+                    "404": {
+                        "description": "Job not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.APIError"
+                        }
+                    },
+                    "409": {
+                        "description": "Job has already finished",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/jobs/{id}/events": {
+            "get": {
+                "description": "Streams the phases of an analysis job and every link check result as Server-Sent Events. The event name is the event type, and the stream ends with a done event carrying the finished job.",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "Jobs"
+                ],
+                "summary": "Stream the progress of an analysis job",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Job ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.AnalysisEvent"
+                        }
+                    },
+                    "404": {
+                        "description": "Job not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/monitors": {
+            "get": {
+                "description": "Lists the registered monitors with their next and last runs and the alerts of the last run",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Monitors"
+                ],
+                "summary": "List monitors",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Monitor"
+                            }
+                        }
+                    },
+                    "503": {
+                        "description": "Monitoring is disabled",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.APIError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Registers a URL to be re-analyzed on a cron schedule. Every new result is checked against the rules, and the alerts raised are posted to the webhook. Without rules, new broken links and failed analyses raise alerts.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Monitors"
+                ],
+                "summary": "Create a monitor",
+                "parameters": [
+                    {
+                        "description": "The monitor",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.MonitorRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.Monitor"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.APIError"
+                        }
+                    },
+                    "503": {
+                        "description": "Monitoring is disabled",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/monitors/{id}": {
+            "get": {
+                "description": "Returns a monitor with its next and last runs and the alerts of the last run",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Monitors"
+                ],
+                "summary": "Get a monitor",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Monitor ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Monitor"
+                        }
+                    },
+                    "404": {
+                        "description": "Monitor not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.APIError"
+                        }
+                    },
+                    "503": {
+                        "description": "Monitoring is disabled",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.APIError"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Replaces the URL, schedule, rules and webhook of a monitor. Pointing it at another URL starts a new baseline for the new_broken_links rule.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Monitors"
+                ],
+                "summary": "Update a monitor",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Monitor ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "The monitor",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.MonitorRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Monitor"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Monitor not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.APIError"
+                        }
+                    },
+                    "503": {
+                        "description": "Monitoring is disabled",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.APIError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Stops and removes a monitor. Its past results stay in the history.",
+                "tags": [
+                    "Monitors"
+                ],
+                "summary": "Delete a monitor",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Monitor ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Deleted"
+                    },
+                    "404": {
+                        "description": "Monitor not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.APIError"
+                        }
+                    },
+                    "503": {
+                        "description": "Monitoring is disabled",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/monitors/{id}/run": {
+            "post": {
+                "description": "Starts a run of the monitor outside its schedule. Poll the monitor to see the result of the run.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Monitors"
+                ],
+                "summary": "Run a monitor now",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Monitor ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/models.Monitor"
+                        }
+                    },
+                    "404": {
+                        "description": "Monitor not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.APIError"
+                        }
+                    },
+                    "503": {
+                        "description": "Monitoring is disabled",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/deliveries": {
+            "get": {
+                "description": "Lists the recent deliveries of analysis callbacks and monitor alerts, newest first, with the outcome of every attempt",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "List webhook deliveries",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Only deliveries with this status (pending, delivered or failed)",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only deliveries about this job or monitor",
+                        "name": "resource_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of deliveries to return (default 50)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.WebhookDelivery"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/deliveries/{id}": {
+            "get": {
+                "description": "Returns a delivery of an analysis callback or monitor alert with the outcome of every attempt",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Get a webhook delivery",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Delivery ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.WebhookDelivery"
+                        }
+                    },
+                    "404": {
+                        "description": "Delivery not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.APIError"
+                        }
+                    }
+                }
+            }
         }
     },
     "definitions": {
         "handlers.APIError": {
             "type": "object",
             "properties": {
-                "code": {
-                    "type": "integer"
+                "code": {
+                    "type": "integer"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.AccessibilityInfo": {
+            "type": "object",
+            "properties": {
+                "has_alt_text": {
+                    "type": "boolean"
+                },
+                "has_aria_labels": {
+                    "type": "boolean"
+                },
+                "has_semantic_html": {
+                    "type": "boolean"
+                },
+                "has_skip_links": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "models.Alert": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string",
+                    "example": "2 links became inaccessible: https://example.com/about, https://example.com/team"
+                },
+                "monitor_id": {
+                    "type": "string",
+                    "example": "5f2b8c1d9e3a4b6c"
+                },
+                "result_id": {
+                    "type": "string",
+                    "example": "0000018c2a5f3e1a9b1c2d3e"
+                },
+                "rule": {
+                    "type": "string",
+                    "example": "new_broken_links"
+                },
+                "triggered_at": {
+                    "type": "string",
+                    "example": "2023-01-01T12:00:00Z"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://example.com"
+                }
+            }
+        },
+        "models.AnalysisDiff": {
+            "type": "object",
+            "properties": {
+                "base": {
+                    "$ref": "#/definitions/models.DiffSide"
+                },
+                "fields": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.FieldChange"
+                    }
+                },
+                "forms": {
+                    "$ref": "#/definitions/models.FormChanges"
+                },
+                "headings": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.HeadingChange"
+                    }
+                },
+                "images": {
+                    "$ref": "#/definitions/models.ImageChanges"
+                },
+                "links": {
+                    "$ref": "#/definitions/models.LinkChanges"
+                },
+                "meta_tags": {
+                    "$ref": "#/definitions/models.MetaTagChanges"
+                },
+                "scripts": {
+                    "$ref": "#/definitions/models.ScriptChanges"
+                },
+                "stylesheets": {
+                    "$ref": "#/definitions/models.StylesheetChanges"
+                },
+                "summary": {
+                    "$ref": "#/definitions/models.DiffSummary"
+                },
+                "target": {
+                    "$ref": "#/definitions/models.DiffSide"
+                }
+            }
+        },
+        "models.AnalysisEvent": {
+            "type": "object",
+            "properties": {
+                "job": {
+                    "$ref": "#/definitions/models.Job"
+                },
+                "link": {
+                    "$ref": "#/definitions/models.LinkDetail"
+                },
+                "progress": {
+                    "$ref": "#/definitions/models.AnalysisProgress"
+                },
+                "skipped_link": {
+                    "$ref": "#/definitions/models.SkippedLink"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "link"
+                }
+            }
+        },
+        "models.AnalysisProgress": {
+            "type": "object",
+            "properties": {
+                "links_checked": {
+                    "type": "integer",
+                    "example": 25
+                },
+                "links_found": {
+                    "type": "integer",
+                    "example": 40
+                },
+                "links_skipped": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "stage": {
+                    "type": "string",
+                    "example": "checking_links"
+                }
+            }
+        },
+        "models.AnalysisResult": {
+            "type": "object",
+            "properties": {
+                "accessibility": {
+                    "$ref": "#/definitions/models.AccessibilityInfo"
+                },
+                "analysis_time": {
+                    "description": "Changed from time.Duration to string",
+                    "type": "string",
+                    "example": "1.234s"
+                },
+                "buttons": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "error": {
+                    "type": "string",
+                    "example": "Failed to fetch page"
+                },
+                "external_links": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "fetch_timing": {
+                    "description": "FetchTiming breaks down the fetch of the page, it is absent for documents that were not fetched",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.TimingBreakdown"
+                        }
+                    ]
+                },
+                "forms": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.FormInfo"
+                    }
+                },
+                "has_login_form": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "headings": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                },
+                "html_version": {
+                    "type": "string",
+                    "example": "HTML5"
+                },
+                "http_status_code": {
+                    "type": "integer",
+                    "example": 200
+                },
+                "id": {
+                    "type": "string",
+                    "example": "0000018c2a5f3e1a9b1c2d3e"
+                },
+                "images": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ImageInfo"
+                    }
+                },
+                "inaccessible_external_links": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "inaccessible_internal_links": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "inaccessible_links": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "inputs": {
+                    "type": "integer",
+                    "example": 8
+                },
+                "internal_links": {
+                    "type": "integer",
+                    "example": 5
+                },
+                "link_check_complete": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "links": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.LinkDetail"
+                    }
+                },
+                "lists": {
+                    "type": "integer",
+                    "example": 5
+                },
+                "meta_tags": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.MetaTag"
+                    }
+                },
+                "page_title": {
+                    "type": "string",
+                    "example": "Example Page"
+                },
+                "scripts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ScriptInfo"
+                    }
+                },
+                "skipped_links": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.SkippedLink"
+                    }
+                },
+                "stylesheets": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.StylesheetInfo"
+                    }
+                },
+                "tables": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "text_content": {
+                    "$ref": "#/definitions/models.TextContentInfo"
+                },
+                "timestamp": {
+                    "type": "string",
+                    "example": "2023-01-01T12:00:00Z"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://example.com"
+                }
+            }
+        },
+        "models.BatchItem": {
+            "type": "object",
+            "properties": {
+                "index": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "result": {
+                    "$ref": "#/definitions/models.AnalysisResult"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "result"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://example.com"
+                }
+            }
+        },
+        "models.CompareRequest": {
+            "type": "object",
+            "properties": {
+                "base": {
+                    "$ref": "#/definitions/models.CompareSource"
+                },
+                "target": {
+                    "$ref": "#/definitions/models.CompareSource"
+                }
+            }
+        },
+        "models.CompareSource": {
+            "type": "object",
+            "properties": {
+                "html": {
+                    "type": "string",
+                    "example": "\u003chtml\u003e\u003chead\u003e\u003ctitle\u003eExample\u003c/title\u003e\u003c/head\u003e\u003c/html\u003e"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "0000018c2a5f3e1a9b1c2d3e"
+                },
+                "result": {
+                    "$ref": "#/definitions/models.AnalysisResult"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://example.com"
+                }
+            }
+        },
+        "models.DiffSide": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string",
+                    "example": "0000018c2a5f3e1a9b1c2d3e"
+                },
+                "timestamp": {
+                    "type": "string",
+                    "example": "2023-01-01T12:00:00Z"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://example.com"
+                }
+            }
+        },
+        "models.DiffSummary": {
+            "type": "object",
+            "properties": {
+                "added_external_scripts": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "added_links": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "changed_accessibility": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "changed_headings": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "changes": {
+                    "type": "integer",
+                    "example": 4
+                },
+                "fixed_links": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "has_changes": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "newly_broken_links": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "removed_links": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "removed_meta_tags": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "models.FieldChange": {
+            "type": "object",
+            "properties": {
+                "after": {},
+                "before": {},
+                "field": {
+                    "type": "string",
+                    "example": "page_title"
+                }
+            }
+        },
+        "models.FormChange": {
+            "type": "object",
+            "properties": {
+                "after": {
+                    "$ref": "#/definitions/models.FormInfo"
+                },
+                "before": {
+                    "$ref": "#/definitions/models.FormInfo"
+                }
+            }
+        },
+        "models.FormChanges": {
+            "type": "object",
+            "properties": {
+                "added": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.FormInfo"
+                    }
+                },
+                "changed": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.FormChange"
+                    }
+                },
+                "removed": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.FormInfo"
+                    }
+                }
+            }
+        },
+        "models.FormInfo": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string"
+                },
+                "has_login": {
+                    "type": "boolean"
+                },
+                "input_count": {
+                    "type": "integer"
+                },
+                "method": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.HeadingChange": {
+            "type": "object",
+            "properties": {
+                "after": {
+                    "type": "integer",
+                    "example": 4
+                },
+                "before": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "level": {
+                    "type": "string",
+                    "example": "h2"
+                }
+            }
+        },
+        "models.HistoryEntry": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string",
+                    "example": "Failed to fetch page"
+                },
+                "external_links": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "has_login_form": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "http_status_code": {
+                    "type": "integer",
+                    "example": 200
+                },
+                "id": {
+                    "type": "string",
+                    "example": "0000018c2a5f3e1a9b1c2d3e"
+                },
+                "inaccessible_links": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "internal_links": {
+                    "type": "integer",
+                    "example": 5
+                },
+                "page_title": {
+                    "type": "string",
+                    "example": "Example Page"
+                },
+                "timestamp": {
+                    "type": "string",
+                    "example": "2023-01-01T12:00:00Z"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://example.com"
+                }
+            }
+        },
+        "models.HistoryPage": {
+            "type": "object",
+            "properties": {
+                "entries": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.HistoryEntry"
+                    }
+                },
+                "limit": {
+                    "type": "integer",
+                    "example": 50
+                },
+                "offset": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "total": {
+                    "type": "integer",
+                    "example": 42
+                }
+            }
+        },
+        "models.ImageChange": {
+            "type": "object",
+            "properties": {
+                "after": {
+                    "$ref": "#/definitions/models.ImageInfo"
+                },
+                "before": {
+                    "$ref": "#/definitions/models.ImageInfo"
+                }
+            }
+        },
+        "models.ImageChanges": {
+            "type": "object",
+            "properties": {
+                "added": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ImageInfo"
+                    }
+                },
+                "changed": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ImageChange"
+                    }
+                },
+                "removed": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ImageInfo"
+                    }
+                }
+            }
+        },
+        "models.ImageInfo": {
+            "type": "object",
+            "properties": {
+                "alt": {
+                    "type": "string"
+                },
+                "height": {
+                    "type": "string"
+                },
+                "is_external": {
+                    "type": "boolean"
+                },
+                "src": {
+                    "type": "string"
+                },
+                "width": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Job": {
+            "type": "object",
+            "properties": {
+                "callback_delivery_id": {
+                    "description": "CallbackDeliveryID is the webhook delivery of the result to CallbackURL, set once the job has finished",
+                    "type": "string",
+                    "example": "3c9a7f1e2b4d6a80"
+                },
+                "callback_url": {
+                    "type": "string",
+                    "example": "https://ci.example.com/hooks/analysis"
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2023-01-01T12:00:00Z"
+                },
+                "error": {
+                    "type": "string",
+                    "example": "HTTP Error: 404 - Not Found"
+                },
+                "finished_at": {
+                    "type": "string",
+                    "example": "2023-01-01T12:00:05Z"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "9f86d081884c7d65"
+                },
+                "progress": {
+                    "$ref": "#/definitions/models.AnalysisProgress"
+                },
+                "result": {
+                    "$ref": "#/definitions/models.AnalysisResult"
+                },
+                "started_at": {
+                    "type": "string",
+                    "example": "2023-01-01T12:00:01Z"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "running"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://example.com"
+                }
+            }
+        },
+        "models.JobRequest": {
+            "type": "object",
+            "properties": {
+                "callback_url": {
+                    "description": "CallbackURL receives the signed AnalysisResult once the analysis has finished",
+                    "type": "string",
+                    "example": "https://ci.example.com/hooks/analysis"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://example.com"
+                }
+            }
+        },
+        "models.LinkChange": {
+            "type": "object",
+            "properties": {
+                "after": {
+                    "$ref": "#/definitions/models.LinkDetail"
+                },
+                "before": {
+                    "$ref": "#/definitions/models.LinkDetail"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://example.com/about"
+                }
+            }
+        },
+        "models.LinkChanges": {
+            "type": "object",
+            "properties": {
+                "added": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.LinkDetail"
+                    }
+                },
+                "changed": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.LinkChange"
+                    }
+                },
+                "fixed": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.LinkDetail"
+                    }
+                },
+                "newly_broken": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.LinkDetail"
+                    }
+                },
+                "removed": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.LinkDetail"
+                    }
+                }
+            }
+        },
+        "models.LinkDetail": {
+            "type": "object",
+            "properties": {
+                "anchor_text": {
+                    "type": "string",
+                    "example": "About us"
+                },
+                "cached": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "checked_at": {
+                    "type": "string",
+                    "example": "2023-01-01T12:00:00Z"
+                },
+                "error": {
+                    "type": "string",
+                    "example": "HTTP 404 Not Found"
+                },
+                "error_class": {
+                    "type": "string",
+                    "example": "http"
+                },
+                "final_url": {
+                    "type": "string",
+                    "example": "https://example.com/about/"
+                },
+                "is_accessible": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "is_external": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "method": {
+                    "type": "string",
+                    "example": "HEAD"
+                },
+                "occurrences": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "redirect_chain": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.RedirectHop"
+                    }
+                },
+                "response_time_ms": {
+                    "type": "integer",
+                    "example": 120
+                },
+                "status_code": {
+                    "type": "integer",
+                    "example": 404
+                },
+                "timing": {
+                    "description": "Timing breaks down the check, it is absent for links answered from the cache",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.TimingBreakdown"
+                        }
+                    ]
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://example.com/about"
+                }
+            }
+        },
+        "models.MetaTag": {
+            "type": "object",
+            "properties": {
+                "content": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "property": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.MetaTagChange": {
+            "type": "object",
+            "properties": {
+                "after": {
+                    "$ref": "#/definitions/models.MetaTag"
+                },
+                "before": {
+                    "$ref": "#/definitions/models.MetaTag"
+                }
+            }
+        },
+        "models.MetaTagChanges": {
+            "type": "object",
+            "properties": {
+                "added": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.MetaTag"
+                    }
+                },
+                "changed": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.MetaTagChange"
+                    }
+                },
+                "removed": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.MetaTag"
+                    }
+                }
+            }
+        },
+        "models.MissingFile": {
+            "type": "object",
+            "properties": {
+                "linked_from": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://example.com/team/"
+                }
+            }
+        },
+        "models.Monitor": {
+            "type": "object",
+            "properties": {
+                "baseline_result_id": {
+                    "type": "string",
+                    "example": "0000018c2a5f3e1a9b1c2d3e"
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2023-01-01T12:00:00Z"
+                },
+                "enabled": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "id": {
+                    "type": "string",
+                    "example": "5f2b8c1d9e3a4b6c"
+                },
+                "last_alerts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Alert"
+                    }
+                },
+                "last_error": {
+                    "type": "string",
+                    "example": "HTTP Error: 500 - Internal Server Error"
+                },
+                "last_result_id": {
+                    "type": "string",
+                    "example": "0000018c2a5f3e1a9b1c2d3e"
+                },
+                "last_run_at": {
+                    "type": "string",
+                    "example": "2023-01-01T12:00:00Z"
+                },
+                "next_run_at": {
+                    "type": "string",
+                    "example": "2023-01-01T12:15:00Z"
+                },
+                "rules": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.MonitorRule"
+                    }
+                },
+                "schedule": {
+                    "type": "string",
+                    "example": "*/15 * * * *"
+                },
+                "updated_at": {
+                    "type": "string",
+                    "example": "2023-01-01T12:00:00Z"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://example.com"
+                },
+                "webhook_url": {
+                    "type": "string",
+                    "example": "https://hooks.example.com/alerts"
+                }
+            }
+        },
+        "models.MonitorRequest": {
+            "type": "object",
+            "properties": {
+                "enabled": {
+                    "description": "Enabled defaults to true",
+                    "type": "boolean",
+                    "example": true
                 },
-                "error": {
+                "rules": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.MonitorRule"
+                    }
+                },
+                "schedule": {
+                    "description": "Schedule is a five field cron expression or a descriptor such as \"@hourly\" or \"@every 30m\"",
+                    "type": "string",
+                    "example": "*/15 * * * *"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://example.com"
+                },
+                "webhook_url": {
+                    "type": "string",
+                    "example": "https://hooks.example.com/alerts"
+                }
+            }
+        },
+        "models.MonitorRule": {
+            "type": "object",
+            "properties": {
+                "threshold": {
+                    "type": "integer",
+                    "example": 5
+                },
+                "type": {
+                    "type": "string",
+                    "example": "max_broken_links"
+                }
+            }
+        },
+        "models.NonHTMLPage": {
+            "type": "object",
+            "properties": {
+                "content_type": {
+                    "type": "string",
+                    "example": "application/pdf"
+                },
+                "found_on": {
+                    "type": "string",
+                    "example": "https://example.com"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://example.com/brochure"
+                }
+            }
+        },
+        "models.RedirectHop": {
+            "type": "object",
+            "properties": {
+                "location": {
+                    "type": "string",
+                    "example": "https://example.com/about/"
+                },
+                "status_code": {
+                    "type": "integer",
+                    "example": 301
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://example.com/about"
+                }
+            }
+        },
+        "models.ScriptChange": {
+            "type": "object",
+            "properties": {
+                "after": {
+                    "$ref": "#/definitions/models.ScriptInfo"
+                },
+                "before": {
+                    "$ref": "#/definitions/models.ScriptInfo"
+                }
+            }
+        },
+        "models.ScriptChanges": {
+            "type": "object",
+            "properties": {
+                "added": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ScriptInfo"
+                    }
+                },
+                "changed": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ScriptChange"
+                    }
+                },
+                "removed": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ScriptInfo"
+                    }
+                }
+            }
+        },
+        "models.ScriptInfo": {
+            "type": "object",
+            "properties": {
+                "is_external": {
+                    "type": "boolean"
+                },
+                "src": {
                     "type": "string"
                 },
-                "message": {
+                "type": {
                     "type": "string"
                 }
             }
         },
-        "models.AnalysisResult": {
+        "models.SitePage": {
             "type": "object",
             "properties": {
-                "analysis_time": {
-                    "description": "Changed from time.Duration to string",
+                "depth": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "file": {
                     "type": "string",
-                    "example": "1.234s"
+                    "example": "about/index.html"
+                },
+                "found_on": {
+                    "type": "string",
+                    "example": "https://example.com"
+                },
+                "result": {
+                    "$ref": "#/definitions/models.AnalysisResult"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://example.com/about"
+                }
+            }
+        },
+        "models.SiteReport": {
+            "type": "object",
+            "properties": {
+                "budget_exhausted": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "crawl_time": {
+                    "type": "string",
+                    "example": "12.5s"
+                },
+                "directory": {
+                    "description": "Directory and MissingFiles are only reported for static site builds analyzed from disk",
+                    "type": "string",
+                    "example": "dist"
                 },
                 "error": {
                     "type": "string",
-                    "example": "Failed to fetch page"
+                    "example": "Invalid URL"
                 },
-                "external_links": {
+                "max_depth": {
                     "type": "integer",
                     "example": 2
                 },
-                "has_login_form": {
+                "max_pages": {
+                    "type": "integer",
+                    "example": 50
+                },
+                "missing_files": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.MissingFile"
+                    }
+                },
+                "non_html_pages": {
+                    "description": "NonHTMLPages are crawled links that turned out not to be HTML documents, which do not count as pages",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.NonHTMLPage"
+                    }
+                },
+                "pages": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.SitePage"
+                    }
+                },
+                "pages_analyzed": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "pages_failed": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "pages_with_login_form": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "seed_url": {
+                    "type": "string",
+                    "example": "https://example.com"
+                },
+                "timestamp": {
+                    "type": "string",
+                    "example": "2023-01-01T12:00:00Z"
+                },
+                "total_external_links": {
+                    "type": "integer",
+                    "example": 30
+                },
+                "total_inaccessible_links": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "total_internal_links": {
+                    "type": "integer",
+                    "example": 120
+                }
+            }
+        },
+        "models.SkippedLink": {
+            "type": "object",
+            "properties": {
+                "anchor_text": {
+                    "type": "string",
+                    "example": "About us"
+                },
+                "is_external": {
                     "type": "boolean",
                     "example": true
                 },
-                "headings": {
-                    "type": "object",
-                    "additionalProperties": {
-                        "type": "integer"
+                "reason": {
+                    "type": "string",
+                    "example": "link_limit"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://example.com/about"
+                }
+            }
+        },
+        "models.StylesheetChange": {
+            "type": "object",
+            "properties": {
+                "after": {
+                    "$ref": "#/definitions/models.StylesheetInfo"
+                },
+                "before": {
+                    "$ref": "#/definitions/models.StylesheetInfo"
+                }
+            }
+        },
+        "models.StylesheetChanges": {
+            "type": "object",
+            "properties": {
+                "added": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.StylesheetInfo"
                     }
                 },
-                "html_version": {
+                "changed": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.StylesheetChange"
+                    }
+                },
+                "removed": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.StylesheetInfo"
+                    }
+                }
+            }
+        },
+        "models.StylesheetInfo": {
+            "type": "object",
+            "properties": {
+                "href": {
+                    "type": "string"
+                },
+                "is_external": {
+                    "type": "boolean"
+                },
+                "media": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.TextContentInfo": {
+            "type": "object",
+            "properties": {
+                "char_count": {
+                    "type": "integer"
+                },
+                "has_main_content": {
+                    "type": "boolean"
+                },
+                "paragraphs": {
+                    "type": "integer"
+                },
+                "word_count": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.TimingBreakdown": {
+            "type": "object",
+            "properties": {
+                "connection_reused": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "content_download_ms": {
+                    "type": "number",
+                    "example": 48.3
+                },
+                "dns_lookup_ms": {
+                    "type": "number",
+                    "example": 12.5
+                },
+                "tcp_connect_ms": {
+                    "type": "number",
+                    "example": 20.1
+                },
+                "time_to_first_byte_ms": {
+                    "type": "number",
+                    "example": 110.2
+                },
+                "tls_handshake_ms": {
+                    "type": "number",
+                    "example": 35.7
+                },
+                "total_ms": {
+                    "description": "TotalMs also includes the time between the phases, such as waits for the per-host limits and retries",
+                    "type": "number",
+                    "example": 230.4
+                }
+            }
+        },
+        "models.WebhookAttempt": {
+            "type": "object",
+            "properties": {
+                "at": {
                     "type": "string",
-                    "example": "HTML5"
+                    "example": "2023-01-01T12:00:00Z"
                 },
-                "http_status_code": {
+                "duration_ms": {
                     "type": "integer",
-                    "example": 200
+                    "example": 85
                 },
-                "inaccessible_links": {
-                    "type": "integer",
-                    "example": 0
+                "error": {
+                    "type": "string",
+                    "example": "webhook responded with HTTP 503"
                 },
-                "internal_links": {
+                "status_code": {
                     "type": "integer",
-                    "example": 5
+                    "example": 503
+                }
+            }
+        },
+        "models.WebhookDelivery": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.WebhookAttempt"
+                    }
                 },
-                "page_title": {
+                "completed_at": {
                     "type": "string",
-                    "example": "Example Page"
+                    "example": "2023-01-01T12:00:01Z"
                 },
-                "timestamp": {
+                "created_at": {
                     "type": "string",
                     "example": "2023-01-01T12:00:00Z"
                 },
+                "event": {
+                    "type": "string",
+                    "example": "analysis.completed"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "3c9a7f1e2b4d6a80"
+                },
+                "next_attempt_at": {
+                    "type": "string",
+                    "example": "2023-01-01T12:00:02Z"
+                },
+                "resource_id": {
+                    "description": "ResourceID is the job or monitor the notification is about",
+                    "type": "string",
+                    "example": "9f86d081884c7d65"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "delivered"
+                },
                 "url": {
                     "type": "string",
-                    "example": "https://example.com"
+                    "example": "https://ci.example.com/hooks/analysis"
                 }
             }
         }
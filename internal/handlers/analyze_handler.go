@@ -1,11 +1,19 @@
 package handlers
 
 import (
+	"WebAppAnalyzer/internal/models"
+	"bytes"
+	"errors"
+	"fmt"
 	"github.com/gin-gonic/gin"
+	"io"
 	"net/http"
+	"strings"
 	"time"
 )
 
+const defaultHTMLUploadMaxBytes = 10 << 20
+
 // Handler struct contains dependencies for handling requests
 // @Summary: Handler struct for web application
 // @Description: Contains methods for handling requests, including health checks and page analysis
@@ -13,6 +21,8 @@ import (
 // @Accept: json
 // @Produce: json
 // @Param: url query string true "URL to analyze"
+// @Param: callback_url query string false "Analyze in the background and post the signed result to this URL"
+// @Success: 202 {object} models.Job
 // @Success: 200 {object} models.AnalysisResult
 // @Failure: 400 {object} APIError "Bad Request"
 // @Failure: 500 {object} APIError "Internal Server Error"
@@ -38,12 +48,21 @@ func (h *Handler) AnalyzePage(c *gin.Context) {
 		return
 	}
 
+	// With a callback the analysis runs as a job, and the result is posted to the callback once it is ready
+	if callbackURL := c.Query("callback_url"); callbackURL != "" {
+		jobsPath := strings.TrimSuffix(c.Request.URL.Path, "/analyze") + "/jobs"
+		h.submitJob(c, models.JobRequest{URL: url, CallbackURL: callbackURL}, jobsPath)
+		return
+	}
+
 	// Perform analysis
+	disableWriteTimeout(c)
 	ctx := c.Request.Context()
 	result := h.analyzer.Analyze(ctx, url)
 
 	// Set analysis time
 	result.AnalysisTime = time.Since(startTime).String()
+	h.recordResult(result)
 
 	c.JSON(http.StatusOK, result)
 
@@ -51,3 +70,140 @@ func (h *Handler) AnalyzePage(c *gin.Context) {
 		Info("Analysis completed")
 
 }
+
+// AnalyzeHTMLUpload analyzes an uploaded HTML document without fetching it
+// @Summary Analyze uploaded HTML
+// @Description Analyzes an HTML document sent as the raw request body or uploaded as file, such as a page of a static site built before deployment. Relative links are resolved against the base URL, and the links found are checked like those of a fetched page.
+// @Tags Analysis
+// @Accept html
+// @Accept mpfd
+// @Produce json
+// @Param base_url query string true "URL the document will be served at, used to resolve relative links"
+// @Param file formData file false "HTML document, when not sent as the request body"
+// @Success 200 {object} models.AnalysisResult
+// @Failure 400 {object} APIError "Bad Request"
+// @Failure 413 {object} APIError "The document is too large"
+// @Router /analyze/html [post]
+func (h *Handler) AnalyzeHTMLUpload(c *gin.Context) {
+	startTime := time.Now()
+
+	h.logger.WithRequest(c.Request.Method, c.Request.URL.Path, c.ClientIP()).
+		Info("HTML analysis request received")
+
+	maxBytes := h.htmlUploadMaxBytes()
+	// Leave room for the multipart headers and the other form fields around the file
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBytes+64<<10)
+
+	baseURL := c.Query("base_url")
+	if baseURL == "" {
+		if err := parseUploadForm(c, maxBytes); err != nil {
+			h.uploadError(c, err, maxBytes)
+			return
+		}
+		baseURL = c.PostForm("base_url")
+	}
+	if baseURL == "" {
+		c.JSON(http.StatusBadRequest, APIError{
+			Error:   "Bad Request",
+			Code:    http.StatusBadRequest,
+			Message: "base_url parameter is required",
+		})
+		return
+	}
+
+	document, err := h.uploadedHTML(c, maxBytes)
+	if err != nil {
+		h.uploadError(c, err, maxBytes)
+		return
+	}
+
+	disableWriteTimeout(c)
+	result := h.analyzer.AnalyzeHTML(c.Request.Context(), baseURL, bytes.NewReader(document))
+	result.AnalysisTime = time.Since(startTime).String()
+	h.recordResult(result)
+
+	c.JSON(http.StatusOK, result)
+
+	h.logger.WithField("duration", time.Since(startTime)).
+		WithField("bytes", len(document)).
+		Info("HTML analysis completed")
+}
+
+// htmlUploadMaxBytes returns the largest HTML document accepted for analysis, HTML_UPLOAD_MAX_BYTES
+func (h *Handler) htmlUploadMaxBytes() int64 {
+	if h.config.HTMLUploadMaxBytes > 0 {
+		return h.config.HTMLUploadMaxBytes
+	}
+	return defaultHTMLUploadMaxBytes
+}
+
+// uploadError reports a failed upload, with 413 when the request was larger than maxBytes allows
+func (h *Handler) uploadError(c *gin.Context, err error, maxBytes int64) {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		c.JSON(http.StatusRequestEntityTooLarge, APIError{
+			Error:   "Request Entity Too Large",
+			Code:    http.StatusRequestEntityTooLarge,
+			Message: fmt.Sprintf("The HTML document must not be larger than %d bytes", maxBytes),
+		})
+		return
+	}
+	c.JSON(http.StatusBadRequest, APIError{
+		Error:   "Bad Request",
+		Code:    http.StatusBadRequest,
+		Message: err.Error(),
+	})
+}
+
+// parseUploadForm parses the form fields of an upload whose body is already limited, keeping an uploaded file
+// of up to maxBytes in memory
+func parseUploadForm(c *gin.Context, maxBytes int64) error {
+	var err error
+	if strings.HasPrefix(c.ContentType(), "multipart/form-data") {
+		err = c.Request.ParseMultipartForm(maxBytes + 64<<10)
+	} else {
+		err = c.Request.ParseForm()
+	}
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("failed to parse the upload form")
+	}
+	return nil
+}
+
+// uploadedHTML reads the HTML document from an uploaded file or the raw request body, up to maxBytes. The
+// request body must already be limited with http.MaxBytesReader.
+func (h *Handler) uploadedHTML(c *gin.Context, maxBytes int64) ([]byte, error) {
+	var body io.Reader = c.Request.Body
+	if strings.HasPrefix(c.ContentType(), "multipart/form-data") {
+		header, err := c.FormFile("file")
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return nil, err
+		}
+		if err != nil {
+			return nil, fmt.Errorf("an HTML file is required")
+		}
+		file, err := header.Open()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the uploaded file")
+		}
+		defer file.Close()
+		body = file
+	}
+
+	document, err := io.ReadAll(io.LimitReader(body, maxBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(document)) > maxBytes {
+		return nil, &http.MaxBytesError{Limit: maxBytes}
+	}
+	if len(bytes.TrimSpace(document)) == 0 {
+		return nil, fmt.Errorf("the HTML document is empty")
+	}
+	return document, nil
+}
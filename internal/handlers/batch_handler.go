@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"WebAppAnalyzer/internal/batch"
+	"WebAppAnalyzer/internal/models"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const defaultBatchMaxURLs = 500
+
+// batchURLMaxBytes is the share of the request body allowed per URL of a full batch
+const batchURLMaxBytes = 4 << 10
+
+// AnalyzeBatch analyzes many URLs in one call and streams the results as NDJSON
+// @Summary Analyze a batch of web pages
+// @Description Analyzes a JSON array of URLs, or an uploaded CSV or newline separated list, with bounded parallelism. Results are streamed as newline delimited JSON in completion order, one result line per URL followed by a summary line.
+// @Tags Analysis
+// @Accept json
+// @Accept plain
+// @Accept mpfd
+// @Produce application/x-ndjson
+// @Param urls body []string false "URLs to analyze"
+// @Param file formData file false "CSV or newline separated list of URLs"
+// @Success 200 {object} models.BatchItem "One line per URL, then a models.BatchSummary line"
+// @Failure 400 {object} APIError "Bad Request"
+// @Failure 413 {object} APIError "Request Entity Too Large"
+// @Router /batch [post]
+func (h *Handler) AnalyzeBatch(c *gin.Context) {
+	startTime := time.Now()
+
+	h.logger.WithRequest(c.Request.Method, c.Request.URL.Path, c.ClientIP()).
+		Info("Batch request received")
+
+	maxURLs := h.config.BatchMaxURLs
+	if maxURLs <= 0 {
+		maxURLs = defaultBatchMaxURLs
+	}
+	// Leave room for the multipart headers and a CSV header row around the list
+	maxBytes := int64(maxURLs)*batchURLMaxBytes + 64<<10
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBytes)
+
+	urls, err := h.batchURLs(c)
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		c.JSON(http.StatusRequestEntityTooLarge, APIError{
+			Error:   "Request Entity Too Large",
+			Code:    http.StatusRequestEntityTooLarge,
+			Message: fmt.Sprintf("The list of URLs must not be larger than %d bytes", maxBytes),
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, APIError{
+			Error:   "Bad Request",
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if len(urls) == 0 || len(urls) > maxURLs {
+		c.JSON(http.StatusBadRequest, APIError{
+			Error:   "Bad Request",
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("A batch must contain between 1 and %d URLs", maxURLs),
+		})
+		return
+	}
+
+	disableWriteTimeout(c)
+	c.Header("Content-Type", "application/x-ndjson")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	encoder := json.NewEncoder(c.Writer)
+	summary := batch.Run(c.Request.Context(), h.analyzer, urls, h.config.BatchParallelism, func(item models.BatchItem) {
+		h.recordResult(item.Result)
+		if err := encoder.Encode(item); err != nil {
+			h.logger.Error("Failed to write batch result", err)
+			return
+		}
+		c.Writer.Flush()
+	})
+	if err := encoder.Encode(summary); err != nil {
+		h.logger.Error("Failed to write batch summary", err)
+	}
+	c.Writer.Flush()
+
+	h.logger.WithField("duration", time.Since(startTime)).
+		WithField("urls", summary.TotalURLs).
+		WithField("failed", summary.Failed).
+		Info("Batch completed")
+}
+
+// batchURLs reads the URLs of a batch from a JSON array, an uploaded file or a plain text body. A body cut off
+// by its size limit is reported with the *http.MaxBytesError.
+func (h *Handler) batchURLs(c *gin.Context) ([]string, error) {
+	var tooLarge *http.MaxBytesError
+	switch {
+	case strings.HasPrefix(c.ContentType(), "application/json"):
+		var urls []string
+		if err := c.ShouldBindJSON(&urls); err != nil {
+			if errors.As(err, &tooLarge) {
+				return nil, err
+			}
+			return nil, fmt.Errorf("the body must be a JSON array of URLs")
+		}
+		return urls, nil
+	case strings.HasPrefix(c.ContentType(), "multipart/form-data"):
+		header, err := c.FormFile("file")
+		if errors.As(err, &tooLarge) {
+			return nil, err
+		}
+		if err != nil {
+			return nil, fmt.Errorf("a file with the list of URLs is required")
+		}
+		file, err := header.Open()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the uploaded file")
+		}
+		defer file.Close()
+		return parseBatchList(file)
+	default:
+		return parseBatchList(c.Request.Body)
+	}
+}
+
+func parseBatchList(r io.Reader) ([]string, error) {
+	urls, err := batch.ParseURLList(r)
+	if err != nil {
+		return nil, fmt.Errorf("the list of URLs could not be parsed: %w", err)
+	}
+	return urls, nil
+}
@@ -0,0 +1,206 @@
+package handlers
+
+import (
+	"WebAppAnalyzer/internal/diff"
+	"WebAppAnalyzer/internal/models"
+	"WebAppAnalyzer/internal/store"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// compareError is a problem with one side of a comparison, reported with the HTTP status it maps to
+type compareError struct {
+	status  int
+	message string
+}
+
+func (e *compareError) Error() string {
+	return e.message
+}
+
+// limitCompareBody caps the body of a comparison at an HTML snapshot of up to HTML_UPLOAD_MAX_BYTES per side,
+// with room for the rest of the request, and returns the limit
+func (h *Handler) limitCompareBody(c *gin.Context) int64 {
+	maxBytes := 2*h.htmlUploadMaxBytes() + 64<<10
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBytes)
+	return maxBytes
+}
+
+// ComparePages compares two analyses
+// @Summary Compare two analyses
+// @Description Returns a structured diff across every section of two analyses, usually of the same page before and after a deploy. Each side is a posted analysis result, the ID of a stored analysis, an HTML snapshot analyzed with the given URL as its base, or a URL analyzed on the spot.
+// @Tags Analysis
+// @Accept json
+// @Produce json
+// @Param request body models.CompareRequest true "The base and target analyses"
+// @Success 200 {object} models.AnalysisDiff
+// @Failure 400 {object} APIError "Bad Request"
+// @Failure 404 {object} APIError "Stored analysis not found"
+// @Failure 413 {object} APIError "The request is too large"
+// @Failure 503 {object} APIError "History is disabled"
+// @Router /compare [post]
+func (h *Handler) ComparePages(c *gin.Context) {
+	startTime := time.Now()
+
+	h.logger.WithRequest(c.Request.Method, c.Request.URL.Path, c.ClientIP()).
+		Info("Compare request received")
+
+	maxBytes := h.limitCompareBody(c)
+	var request models.CompareRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, APIError{
+				Error:   "Request Entity Too Large",
+				Code:    http.StatusRequestEntityTooLarge,
+				Message: fmt.Sprintf("The comparison must not be larger than %d bytes", maxBytes),
+			})
+			return
+		}
+		c.JSON(http.StatusBadRequest, APIError{
+			Error:   "Bad Request",
+			Code:    http.StatusBadRequest,
+			Message: "The body must be a JSON object with a base and a target",
+		})
+		return
+	}
+
+	disableWriteTimeout(c)
+	result, err := h.compare(c.Request.Context(), request)
+	if err != nil {
+		var compareErr *compareError
+		if !errors.As(err, &compareErr) {
+			h.logger.Error("Failed to compare analyses", err)
+			compareErr = &compareError{http.StatusInternalServerError, "Failed to compare the analyses"}
+		}
+		c.JSON(compareErr.status, APIError{
+			Error:   http.StatusText(compareErr.status),
+			Code:    compareErr.status,
+			Message: compareErr.message,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+
+	h.logger.WithField("duration", time.Since(startTime)).
+		WithField("changes", result.Summary.Changes).
+		Info("Comparison completed")
+}
+
+// ComparePage renders the comparison form, or the diff of two stored analyses given as the base and target query parameters
+func (h *Handler) ComparePage(c *gin.Context) {
+	if c.Query("base") == "" && c.Query("target") == "" {
+		c.HTML(http.StatusOK, "index.html", gin.H{
+			"title":   h.config.WebAppTitle,
+			"compare": true,
+		})
+		return
+	}
+
+	h.renderComparison(c, models.CompareRequest{
+		Base:   models.CompareSource{ID: c.Query("base")},
+		Target: models.CompareSource{ID: c.Query("target")},
+	})
+}
+
+// CompareForm handles submissions of the comparison form. Each side is a URL, optionally with an HTML snapshot.
+func (h *Handler) CompareForm(c *gin.Context) {
+	h.logger.WithRequest(c.Request.Method, c.Request.URL.Path, c.ClientIP()).
+		Info("Form compare request received")
+
+	maxBytes := h.limitCompareBody(c)
+	if err := c.Request.ParseForm(); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.HTML(http.StatusRequestEntityTooLarge, "index.html", gin.H{
+				"title":   h.config.WebAppTitle,
+				"compare": true,
+				"error":   fmt.Sprintf("The comparison must not be larger than %d bytes", maxBytes),
+			})
+			return
+		}
+		c.HTML(http.StatusBadRequest, "index.html", gin.H{
+			"title":   h.config.WebAppTitle,
+			"compare": true,
+			"error":   "The comparison form could not be read",
+		})
+		return
+	}
+
+	disableWriteTimeout(c)
+	h.renderComparison(c, models.CompareRequest{
+		Base:   models.CompareSource{URL: c.PostForm("base_url"), HTML: c.PostForm("base_html")},
+		Target: models.CompareSource{URL: c.PostForm("target_url"), HTML: c.PostForm("target_html")},
+	})
+}
+
+func (h *Handler) renderComparison(c *gin.Context, request models.CompareRequest) {
+	result, err := h.compare(c.Request.Context(), request)
+	if err != nil {
+		c.HTML(http.StatusOK, "index.html", gin.H{
+			"title":   h.config.WebAppTitle,
+			"compare": true,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.HTML(http.StatusOK, "index.html", gin.H{
+		"title":   h.config.WebAppTitle,
+		"compare": true,
+		"diff":    result,
+	})
+}
+
+func (h *Handler) compare(ctx context.Context, request models.CompareRequest) (*models.AnalysisDiff, error) {
+	base, err := h.compareSource(ctx, "base", request.Base)
+	if err != nil {
+		return nil, err
+	}
+	target, err := h.compareSource(ctx, "target", request.Target)
+	if err != nil {
+		return nil, err
+	}
+	return diff.Compare(base, target), nil
+}
+
+// compareSource resolves one side of a comparison to an analysis result, analyzing the page when needed
+func (h *Handler) compareSource(ctx context.Context, name string, source models.CompareSource) (*models.AnalysisResult, error) {
+	switch {
+	case source.Result != nil:
+		return source.Result, nil
+	case source.ID != "":
+		if h.history == nil {
+			return nil, &compareError{http.StatusServiceUnavailable, "The analysis history is disabled"}
+		}
+		result, err := h.history.Get(source.ID)
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, &compareError{http.StatusNotFound, fmt.Sprintf("No analysis found with ID %s for the %s", source.ID, name)}
+		}
+		if err != nil {
+			h.logger.Error("Failed to access history", err)
+			return nil, &compareError{http.StatusInternalServerError, "Failed to access the analysis history"}
+		}
+		return result, nil
+	case source.URL == "":
+		return nil, &compareError{http.StatusBadRequest, fmt.Sprintf("The %s needs a result, an id, or a url with an optional html snapshot", name)}
+	}
+
+	startTime := time.Now()
+	var result *models.AnalysisResult
+	if strings.TrimSpace(source.HTML) != "" {
+		result = h.analyzer.AnalyzeHTML(ctx, source.URL, strings.NewReader(source.HTML))
+	} else {
+		result = h.analyzer.Analyze(ctx, source.URL)
+	}
+	result.AnalysisTime = time.Since(startTime).String()
+	h.recordResult(result)
+
+	return result, nil
+}
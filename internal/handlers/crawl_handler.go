@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"WebAppAnalyzer/internal/models"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// CrawlSite crawls a site from the given seed URL and returns a site level report
+// @Summary Crawl a site
+// @Description Analyzes the seed URL and follows its internal links up to the given depth and page budget
+// @Tags Analysis
+// @Produce json
+// @Param url query string true "Seed URL to start crawling from"
+// @Param depth query int false "Maximum link depth to follow from the seed page"
+// @Param max_pages query int false "Maximum number of pages to analyze"
+// @Success 200 {object} models.SiteReport
+// @Failure 400 {object} APIError "Bad Request"
+// @Router /crawl [get]
+// @Router /crawl [post]
+func (h *Handler) CrawlSite(c *gin.Context) {
+	startTime := time.Now()
+
+	h.logger.WithRequest(c.Request.Method, c.Request.URL.Path, c.ClientIP()).
+		Info("Crawl request received")
+
+	url := c.Query("url")
+	if url == "" {
+		c.JSON(http.StatusBadRequest, APIError{
+			Error:   "Bad Request",
+			Code:    http.StatusBadRequest,
+			Message: "URL parameter is required",
+		})
+		return
+	}
+
+	depth, err := parseOptionalPositiveInt(c, "depth")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, APIError{
+			Error:   "Bad Request",
+			Code:    http.StatusBadRequest,
+			Message: "depth must be a positive integer",
+		})
+		return
+	}
+	maxPages, err := parseOptionalPositiveInt(c, "max_pages")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, APIError{
+			Error:   "Bad Request",
+			Code:    http.StatusBadRequest,
+			Message: "max_pages must be a positive integer",
+		})
+		return
+	}
+
+	opts := models.CrawlOptions{
+		MaxDepth: depth,
+		MaxPages: maxPages,
+	}
+
+	disableWriteTimeout(c)
+	report := h.analyzer.Crawl(c.Request.Context(), url, opts)
+
+	c.JSON(http.StatusOK, report)
+
+	h.logger.WithField("duration", time.Since(startTime)).
+		WithField("pages", report.PagesAnalyzed).
+		Info("Crawl completed")
+}
+
+// parseOptionalInt reads a non-negative integer query parameter, returning 0 when it is absent
+func parseOptionalInt(c *gin.Context, key string) (int, error) {
+	raw := c.Query(key)
+	if raw == "" {
+		return 0, nil
+	}
+	value, err := strconv.Atoi(raw)
+	if err != nil || value < 0 {
+		return 0, strconv.ErrSyntax
+	}
+	return value, nil
+}
+
+// parseOptionalPositiveInt reads a positive integer query parameter, returning 0 when it is absent
+func parseOptionalPositiveInt(c *gin.Context, key string) (int, error) {
+	value, err := parseOptionalInt(c, key)
+	if err == nil && value == 0 && c.Query(key) != "" {
+		return 0, strconv.ErrSyntax
+	}
+	return value, err
+}
@@ -4,21 +4,58 @@ import (
 	"WebAppAnalyzer/config/env"
 	"WebAppAnalyzer/config/logger"
 	"WebAppAnalyzer/internal/models"
+	"WebAppAnalyzer/internal/store"
 	"context"
 	"github.com/gin-gonic/gin"
+	"io"
 	"net/http"
+	"sync/atomic"
 	"time"
 )
 
 // PageAnalyzerInterface defines the interface for page analysis
 type PageAnalyzerInterface interface {
 	Analyze(ctx context.Context, url string) *models.AnalysisResult
+	AnalyzeHTML(ctx context.Context, baseURL string, body io.Reader) *models.AnalysisResult
+	Crawl(ctx context.Context, seed string, opts models.CrawlOptions) *models.SiteReport
+	PoolStats() models.LinkCheckPoolStats
+}
+
+// JobManagerInterface defines the interface for running analyses in the background
+type JobManagerInterface interface {
+	Submit(request models.JobRequest) (models.Job, error)
+	Get(id string) (models.Job, error)
+	Cancel(id string) (models.Job, error)
+	Subscribe(id string) (<-chan models.AnalysisEvent, func(), error)
+}
+
+// MonitorSchedulerInterface defines the interface for managing scheduled monitors
+type MonitorSchedulerInterface interface {
+	Create(req models.MonitorRequest) (models.Monitor, error)
+	Update(id string, req models.MonitorRequest) (models.Monitor, error)
+	Get(id string) (models.Monitor, error)
+	List() []models.Monitor
+	Delete(id string) error
+	Run(id string) (models.Monitor, error)
+}
+
+// WebhookLogInterface defines the interface for validating webhook URLs and reading the webhook delivery log
+type WebhookLogInterface interface {
+	ValidateURL(url string) error
+	Get(id string) (models.WebhookDelivery, error)
+	List(filter models.DeliveryFilter) []models.WebhookDelivery
 }
 
 type Handler struct {
 	analyzer PageAnalyzerInterface
+	jobs     JobManagerInterface
+	history  store.ResultStore
+	monitors MonitorSchedulerInterface
+	webhooks WebhookLogInterface
 	logger   *logger.Logger
 	config   *env.Config
+	// draining is set once the server is shutting down, so load balancers stop sending it requests
+	draining atomic.Bool
 }
 
 type APIError struct {
@@ -28,9 +65,13 @@ type APIError struct {
 }
 
 // NewHandler creates a new handler instance
-func NewHandler(pageAnalyzer PageAnalyzerInterface, logger *logger.Logger, c *env.Config) *Handler {
+func NewHandler(pageAnalyzer PageAnalyzerInterface, jobManager JobManagerInterface, history store.ResultStore, monitors MonitorSchedulerInterface, webhooks WebhookLogInterface, logger *logger.Logger, c *env.Config) *Handler {
 	return &Handler{
 		analyzer: pageAnalyzer,
+		jobs:     jobManager,
+		history:  history,
+		monitors: monitors,
+		webhooks: webhooks,
 		logger:   logger,
 		config:   c,
 	}
@@ -43,9 +84,38 @@ func (h *Handler) HealthCheck(c *gin.Context) {
 		"timestamp": time.Now().UTC(),
 		"service":   "web-analyzer",
 		"version":   "1.0.0",
+		// Utilization and queue depth of the link checker workers shared by all analyses
+		"link_check_pool": h.analyzer.PoolStats(),
+	})
+}
+
+// ReadinessCheck reports whether the server accepts new work, answering 503 once it is draining for shutdown
+func (h *Handler) ReadinessCheck(c *gin.Context) {
+	if h.draining.Load() {
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"status":    "draining",
+			"timestamp": time.Now().UTC(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"status":    "ready",
+		"timestamp": time.Now().UTC(),
 	})
 }
 
+// SetDraining makes the readiness check fail for the rest of the life of the server
+func (h *Handler) SetDraining() {
+	h.draining.Store(true)
+}
+
+// disableWriteTimeout lifts the server write timeout for a response that takes as long as the work behind it,
+// such as an analysis, a stream of results or a crawl
+func disableWriteTimeout(c *gin.Context) {
+	// Not every ResponseWriter supports deadlines, such as the recorders of tests, which have no timeout to lift
+	_ = http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{})
+}
+
 func (h *Handler) Index(c *gin.Context) {
 	c.HTML(http.StatusOK, "index.html", gin.H{
 		"title": h.config.WebAppTitle,
@@ -102,11 +172,13 @@ func (h *Handler) AnalyzePageForm(c *gin.Context) {
 		return
 	}
 
+	disableWriteTimeout(c)
 	ctx := c.Request.Context()
 	startTime := time.Now()
 	result := h.analyzer.Analyze(ctx, url)
 
 	result.AnalysisTime = time.Since(startTime).String()
+	h.recordResult(result)
 
 	h.logger.WithField("success", result.IsSuccessful()).
 		Info("Form analysis completed")
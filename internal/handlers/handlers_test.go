@@ -3,12 +3,21 @@ package handlers
 import (
 	"WebAppAnalyzer/config/env"
 	"WebAppAnalyzer/config/logger"
+	"WebAppAnalyzer/internal/jobs"
 	"WebAppAnalyzer/internal/models"
+	"WebAppAnalyzer/internal/monitor"
+	"WebAppAnalyzer/internal/store"
+	"WebAppAnalyzer/internal/validator"
+	"WebAppAnalyzer/internal/webhook"
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -27,17 +36,40 @@ func (m *MockPageAnalyzer) Analyze(ctx context.Context, url string) *models.Anal
 	return args.Get(0).(*models.AnalysisResult)
 }
 
+func (m *MockPageAnalyzer) AnalyzeHTML(ctx context.Context, baseURL string, body io.Reader) *models.AnalysisResult {
+	html, _ := io.ReadAll(body)
+	args := m.Called(ctx, baseURL, string(html))
+	return args.Get(0).(*models.AnalysisResult)
+}
+
+func (m *MockPageAnalyzer) Crawl(ctx context.Context, seed string, opts models.CrawlOptions) *models.SiteReport {
+	args := m.Called(ctx, seed, opts)
+	return args.Get(0).(*models.SiteReport)
+}
+
+func (m *MockPageAnalyzer) PoolStats() models.LinkCheckPoolStats {
+	args := m.Called()
+	return args.Get(0).(models.LinkCheckPoolStats)
+}
+
 // createTestHandler creates handler with mock analyzer for testing
 func createTestHandler() (*Handler, *MockPageAnalyzer) {
 	config := &env.Config{
 		LogLevel:    "debug",
 		WebAppTitle: "Test Web App Analyzer",
+
+		WebhookSecret: "s3cret",
+		// Callbacks are posted to test servers listening on the loopback address
+		SSRFAllowlist: "127.0.0.1",
 	}
 	logger := logger.NewLogger(*config)
 
 	mockAnalyzer := &MockPageAnalyzer{}
 
-	handler := NewHandler(mockAnalyzer, logger, config)
+	webhooks := webhook.NewDispatcher(logger, config)
+	jobManager := jobs.NewManager(mockAnalyzer, nil, webhooks, logger, config)
+
+	handler := NewHandler(mockAnalyzer, jobManager, nil, nil, webhooks, logger, config)
 
 	return handler, mockAnalyzer
 }
@@ -48,8 +80,28 @@ func setupGinTest(handler *Handler) *gin.Engine {
 	router := gin.New()
 
 	router.GET("/analyze", handler.AnalyzePage)
+	router.POST("/analyze/html", handler.AnalyzeHTMLUpload)
+	router.GET("/crawl", handler.CrawlSite)
+	router.POST("/batch", handler.AnalyzeBatch)
+	router.POST("/compare", handler.ComparePages)
+	router.GET("/history", handler.ListHistory)
+	router.GET("/history/:id", handler.GetHistoryEntry)
+	router.DELETE("/history/:id", handler.DeleteHistoryEntry)
 	router.GET("/", handler.Index)
 	router.GET("/health", handler.HealthCheck)
+	router.GET("/ready", handler.ReadinessCheck)
+	router.POST("/monitors", handler.CreateMonitor)
+	router.GET("/monitors", handler.ListMonitors)
+	router.GET("/monitors/:id", handler.GetMonitor)
+	router.PUT("/monitors/:id", handler.UpdateMonitor)
+	router.DELETE("/monitors/:id", handler.DeleteMonitor)
+	router.POST("/monitors/:id/run", handler.RunMonitor)
+	router.GET("/webhooks/deliveries", handler.ListWebhookDeliveries)
+	router.GET("/webhooks/deliveries/:id", handler.GetWebhookDelivery)
+	router.POST("/jobs", handler.CreateJob)
+	router.GET("/jobs/:id", handler.GetJob)
+	router.DELETE("/jobs/:id", handler.CancelJob)
+	router.GET("/jobs/:id/events", handler.StreamJobEvents)
 	router.NoRoute(handler.NotFound)
 
 	return router
@@ -485,3 +537,853 @@ func TestAnalyzePage_SuccessfulAnalysis(t *testing.T) {
 	// Verify that analyzer was called with correct parameters
 	mockAnalyzer.AssertExpectations(t)
 }
+
+// TestAnalyzeHTMLUpload tests that HTML sent as the body or uploaded as a file is analyzed without fetching it
+func TestAnalyzeHTMLUpload(t *testing.T) {
+	document := "<html><head><title>Home</title></head><body><a href=\"/about\">About</a></body></html>"
+
+	tests := []struct {
+		name string
+		body func() (*bytes.Buffer, string)
+	}{
+		{
+			name: "Raw body",
+			body: func() (*bytes.Buffer, string) {
+				return bytes.NewBufferString(document), "text/html"
+			},
+		},
+		{
+			name: "Uploaded file",
+			body: func() (*bytes.Buffer, string) {
+				body := &bytes.Buffer{}
+				writer := multipart.NewWriter(body)
+				part, _ := writer.CreateFormFile("file", "index.html")
+				part.Write([]byte(document))
+				writer.Close()
+				return body, writer.FormDataContentType()
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, mockAnalyzer := createTestHandler()
+			router := setupGinTest(handler)
+
+			mockAnalyzer.On("AnalyzeHTML", mock.Anything, "https://example.com", document).
+				Return(&models.AnalysisResult{URL: "https://example.com", PageTitle: "Home", InternalLinks: 1})
+
+			body, contentType := tt.body()
+			req, _ := http.NewRequest("POST", "/analyze/html?base_url=https://example.com", body)
+			req.Header.Set("Content-Type", contentType)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+
+			var result models.AnalysisResult
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+			assert.Equal(t, "Home", result.PageTitle)
+			assert.NotEmpty(t, result.AnalysisTime)
+			mockAnalyzer.AssertExpectations(t)
+			mockAnalyzer.AssertNotCalled(t, "Analyze")
+		})
+	}
+}
+
+// TestAnalyzeHTMLUpload_BadRequest tests that uploads without a base URL, empty or too large are rejected
+func TestAnalyzeHTMLUpload_BadRequest(t *testing.T) {
+	handler, mockAnalyzer := createTestHandler()
+	handler.config.HTMLUploadMaxBytes = 64
+	router := setupGinTest(handler)
+
+	tests := []struct {
+		path     string
+		body     string
+		expected int
+	}{
+		{"/analyze/html", "<html></html>", http.StatusBadRequest},
+		{"/analyze/html?base_url=https://example.com", "  ", http.StatusBadRequest},
+		{"/analyze/html?base_url=https://example.com", strings.Repeat("<p>x</p>", 20), http.StatusRequestEntityTooLarge},
+	}
+
+	for _, tt := range tests {
+		req, _ := http.NewRequest("POST", tt.path, strings.NewReader(tt.body))
+		req.Header.Set("Content-Type", "text/html")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, tt.expected, w.Code, tt.path)
+	}
+
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	part, _ := writer.CreateFormFile("file", "index.html")
+	part.Write([]byte(strings.Repeat("<p>x</p>", 20000)))
+	writer.Close()
+
+	req, _ := http.NewRequest("POST", "/analyze/html?base_url=https://example.com", body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+
+	mockAnalyzer.AssertNotCalled(t, "AnalyzeHTML")
+}
+
+// TestAnalyzeHTMLUpload_TooLargeForm tests that the size limit covers the whole upload when the base URL is
+// sent as a form field, not only the uploaded file
+func TestAnalyzeHTMLUpload_TooLargeForm(t *testing.T) {
+	handler, mockAnalyzer := createTestHandler()
+	handler.config.HTMLUploadMaxBytes = 64
+	router := setupGinTest(handler)
+
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	writer.WriteField("base_url", "https://example.com")
+	writer.WriteField("padding", strings.Repeat("x", 256<<10))
+	part, _ := writer.CreateFormFile("file", "index.html")
+	part.Write([]byte("<html></html>"))
+	writer.Close()
+
+	req, _ := http.NewRequest("POST", "/analyze/html", body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	mockAnalyzer.AssertNotCalled(t, "AnalyzeHTML")
+}
+
+// TestCrawlSite tests the crawl endpoint
+func TestCrawlSite(t *testing.T) {
+	handler, mockAnalyzer := createTestHandler()
+	router := setupGinTest(handler)
+
+	expectedReport := &models.SiteReport{
+		SeedURL:       "https://example.com",
+		MaxDepth:      1,
+		MaxPages:      5,
+		PagesAnalyzed: 2,
+		Pages: []models.SitePage{
+			{URL: "https://example.com", Result: &models.AnalysisResult{URL: "https://example.com"}},
+			{URL: "https://example.com/about", Depth: 1, Result: &models.AnalysisResult{URL: "https://example.com/about"}},
+		},
+	}
+
+	mockAnalyzer.On("Crawl", mock.Anything, "https://example.com", models.CrawlOptions{MaxDepth: 1, MaxPages: 5}).Return(expectedReport)
+
+	req, _ := http.NewRequest("GET", "/crawl?url=https://example.com&depth=1&max_pages=5", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response models.SiteReport
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+
+	assert.Equal(t, expectedReport.SeedURL, response.SeedURL)
+	assert.Equal(t, expectedReport.PagesAnalyzed, response.PagesAnalyzed)
+	assert.Len(t, response.Pages, 2)
+
+	mockAnalyzer.AssertExpectations(t)
+}
+
+// TestCrawlSite_BadRequest tests crawl parameter validation
+func TestCrawlSite_BadRequest(t *testing.T) {
+	handler, mockAnalyzer := createTestHandler()
+	router := setupGinTest(handler)
+
+	for _, target := range []string{"/crawl", "/crawl?url=https://example.com&depth=abc", "/crawl?url=https://example.com&depth=0", "/crawl?url=https://example.com&max_pages=-1"} {
+		req, _ := http.NewRequest("GET", target, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, target)
+	}
+
+	mockAnalyzer.AssertNotCalled(t, "Crawl")
+}
+
+// TestHealthCheck tests that the health check reports the link check pool
+func TestHealthCheck(t *testing.T) {
+	handler, mockAnalyzer := createTestHandler()
+	router := setupGinTest(handler)
+
+	stats := models.LinkCheckPoolStats{Workers: 5, BusyWorkers: 2, ActiveAnalyses: 1, QueuedLinks: 7}
+	mockAnalyzer.On("PoolStats").Return(stats)
+
+	req, _ := http.NewRequest("GET", "/health", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Status        string                    `json:"status"`
+		LinkCheckPool models.LinkCheckPoolStats `json:"link_check_pool"`
+	}
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, "healthy", response.Status)
+	assert.Equal(t, stats, response.LinkCheckPool)
+
+	mockAnalyzer.AssertExpectations(t)
+}
+
+// TestCreateJob tests that a job is accepted right away and its result can be polled
+func TestCreateJob(t *testing.T) {
+	handler, mockAnalyzer := createTestHandler()
+	router := setupGinTest(handler)
+
+	expectedResult := &models.AnalysisResult{
+		URL:            "https://example.com",
+		PageTitle:      "Example Domain",
+		HTTPStatusCode: 200,
+		Timestamp:      time.Now(),
+	}
+	mockAnalyzer.On("Analyze", mock.Anything, "https://example.com").Return(expectedResult)
+
+	req, _ := http.NewRequest("POST", "/jobs", strings.NewReader(`{"url": "https://example.com"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusAccepted, w.Code)
+
+	var created models.Job
+	err := json.Unmarshal(w.Body.Bytes(), &created)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, created.ID)
+	assert.Equal(t, "/jobs/"+created.ID, w.Header().Get("Location"))
+
+	var job models.Job
+	assert.Eventually(t, func() bool {
+		req, _ := http.NewRequest("GET", "/jobs/"+created.ID, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			return false
+		}
+		_ = json.Unmarshal(w.Body.Bytes(), &job)
+		return job.IsFinished()
+	}, 2*time.Second, 10*time.Millisecond)
+
+	assert.Equal(t, models.JobStatusCompleted, job.Status)
+	if assert.NotNil(t, job.Result) {
+		assert.Equal(t, expectedResult.PageTitle, job.Result.PageTitle)
+	}
+
+	// A finished job can no longer be cancelled
+	req, _ = http.NewRequest("DELETE", "/jobs/"+created.ID, nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	mockAnalyzer.AssertExpectations(t)
+}
+
+// TestCreateJob_MissingURL tests that a job without a URL is rejected
+func TestCreateJob_MissingURL(t *testing.T) {
+	handler, mockAnalyzer := createTestHandler()
+	router := setupGinTest(handler)
+
+	req, _ := http.NewRequest("POST", "/jobs", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockAnalyzer.AssertNotCalled(t, "Analyze")
+}
+
+// TestGetJob_NotFound tests that unknown jobs return 404
+func TestGetJob_NotFound(t *testing.T) {
+	handler, _ := createTestHandler()
+	router := setupGinTest(handler)
+
+	for _, method := range []string{"GET", "DELETE"} {
+		req, _ := http.NewRequest(method, "/jobs/unknown", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+
+		var response APIError
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, response.Code)
+	}
+}
+
+// TestCreateJob_Callback tests that the result of a job is posted to its callback URL and logged as a delivery
+func TestCreateJob_Callback(t *testing.T) {
+	handler, mockAnalyzer := createTestHandler()
+	router := setupGinTest(handler)
+
+	mockAnalyzer.On("Analyze", mock.Anything, "https://example.com").Return(&models.AnalysisResult{
+		URL:            "https://example.com",
+		PageTitle:      "Example Domain",
+		HTTPStatusCode: 200,
+	})
+
+	received := make(chan models.AnalysisResult, 1)
+	callback := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var result models.AnalysisResult
+		_ = json.NewDecoder(r.Body).Decode(&result)
+		assert.Equal(t, models.WebhookEventAnalysisCompleted, r.Header.Get(webhook.EventHeader))
+		received <- result
+	}))
+	defer callback.Close()
+
+	req, _ := http.NewRequest("GET", "/analyze?url=https://example.com&callback_url="+callback.URL, nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusAccepted, w.Code)
+
+	var created models.Job
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	assert.Equal(t, callback.URL, created.CallbackURL)
+	assert.Equal(t, "/jobs/"+created.ID, w.Header().Get("Location"))
+
+	select {
+	case result := <-received:
+		assert.Equal(t, "Example Domain", result.PageTitle)
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected the result to be posted to the callback URL")
+	}
+
+	var deliveries []models.WebhookDelivery
+	assert.Eventually(t, func() bool {
+		req, _ := http.NewRequest("GET", "/webhooks/deliveries?resource_id="+created.ID, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		_ = json.Unmarshal(w.Body.Bytes(), &deliveries)
+		return len(deliveries) == 1 && deliveries[0].Status == models.DeliveryStatusDelivered
+	}, 2*time.Second, 10*time.Millisecond)
+
+	req, _ = http.NewRequest("GET", "/webhooks/deliveries/"+deliveries[0].ID, nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	req, _ = http.NewRequest("GET", "/jobs/"+created.ID, nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	var job models.Job
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &job))
+	assert.Equal(t, deliveries[0].ID, job.CallbackDeliveryID)
+}
+
+// TestCreateJob_InvalidCallback tests that callbacks which cannot be posted to are rejected
+func TestCreateJob_InvalidCallback(t *testing.T) {
+	handler, mockAnalyzer := createTestHandler()
+	router := setupGinTest(handler)
+
+	req, _ := http.NewRequest("POST", "/jobs", strings.NewReader(`{"url": "https://example.com", "callback_url": "ftp://example.com"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// Callbacks would go out unsigned without a secret
+	handler.webhooks = webhook.NewDispatcher(handler.logger, &env.Config{})
+	req, _ = http.NewRequest("GET", "/analyze?url=https://example.com&callback_url=https://hooks.example.com/a", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "WEBHOOK_SECRET")
+	mockAnalyzer.AssertNotCalled(t, "Analyze")
+
+	req, _ = http.NewRequest("GET", "/webhooks/deliveries/unknown", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	req, _ = http.NewRequest("GET", "/webhooks/deliveries?limit=0", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+// TestStreamJobEvents tests that the job events are streamed until the job is done
+func TestStreamJobEvents(t *testing.T) {
+	handler, mockAnalyzer := createTestHandler()
+	router := setupGinTest(handler)
+
+	mockAnalyzer.On("Analyze", mock.Anything, "https://example.com").Return(&models.AnalysisResult{
+		URL:            "https://example.com",
+		PageTitle:      "Example Domain",
+		HTTPStatusCode: 200,
+	})
+
+	job, err := handler.jobs.Submit(models.JobRequest{URL: "https://example.com"})
+	assert.NoError(t, err)
+
+	req, _ := http.NewRequest("GET", "/jobs/"+job.ID+"/events", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Type"), "text/event-stream")
+	assert.Contains(t, w.Body.String(), "event:progress")
+	assert.Contains(t, w.Body.String(), "event:done")
+	assert.Contains(t, w.Body.String(), "Example Domain")
+
+	req, _ = http.NewRequest("GET", "/jobs/unknown/events", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+// TestAnalyzeBatch tests that batch results are streamed as NDJSON followed by a summary
+func TestAnalyzeBatch(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        func() (*bytes.Buffer, string)
+	}{
+		{
+			name: "JSON array",
+			body: func() (*bytes.Buffer, string) {
+				return bytes.NewBufferString(`["https://a.com", "https://b.com"]`), "application/json"
+			},
+		},
+		{
+			name: "Newline list",
+			body: func() (*bytes.Buffer, string) {
+				return bytes.NewBufferString("https://a.com\nhttps://b.com\n"), "text/plain"
+			},
+		},
+		{
+			name: "Uploaded CSV",
+			body: func() (*bytes.Buffer, string) {
+				body := &bytes.Buffer{}
+				writer := multipart.NewWriter(body)
+				part, _ := writer.CreateFormFile("file", "urls.csv")
+				part.Write([]byte("url\nhttps://a.com\nhttps://b.com\n"))
+				writer.Close()
+				return body, writer.FormDataContentType()
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, mockAnalyzer := createTestHandler()
+			router := setupGinTest(handler)
+
+			mockAnalyzer.On("Analyze", mock.Anything, "https://a.com").Return(&models.AnalysisResult{URL: "https://a.com", InternalLinks: 3})
+			mockAnalyzer.On("Analyze", mock.Anything, "https://b.com").Return(&models.AnalysisResult{URL: "https://b.com", Error: "HTTP Error: 404 - Not Found"})
+
+			body, contentType := tt.body()
+			req, _ := http.NewRequest("POST", "/batch", body)
+			req.Header.Set("Content-Type", contentType)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, "application/x-ndjson", w.Header().Get("Content-Type"))
+
+			lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+			assert.Len(t, lines, 3)
+
+			for _, line := range lines[:2] {
+				var item models.BatchItem
+				assert.NoError(t, json.Unmarshal([]byte(line), &item))
+				assert.Equal(t, models.BatchLineResult, item.Type)
+				assert.NotNil(t, item.Result)
+			}
+
+			var summary models.BatchSummary
+			assert.NoError(t, json.Unmarshal([]byte(lines[2]), &summary))
+			assert.Equal(t, models.BatchLineSummary, summary.Type)
+			assert.Equal(t, 2, summary.TotalURLs)
+			assert.Equal(t, 1, summary.Succeeded)
+			assert.Equal(t, 1, summary.Failed)
+			assert.Equal(t, 3, summary.TotalInternalLinks)
+
+			mockAnalyzer.AssertExpectations(t)
+		})
+	}
+}
+
+// TestAnalyzeBatch_BadRequest tests that empty, oversized and malformed batches are rejected
+func TestAnalyzeBatch_BadRequest(t *testing.T) {
+	handler, mockAnalyzer := createTestHandler()
+	handler.config.BatchMaxURLs = 2
+	router := setupGinTest(handler)
+
+	bodies := []string{`[]`, `["https://a.com", "https://b.com", "https://c.com"]`, `{"url": "https://a.com"}`}
+	for _, body := range bodies {
+		req, _ := http.NewRequest("POST", "/batch", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, body)
+	}
+
+	mockAnalyzer.AssertNotCalled(t, "Analyze")
+}
+
+// TestAnalyzeBatch_TooLarge tests that batch bodies beyond the size allowed for the maximum number of URLs are
+// rejected before they are read in full
+func TestAnalyzeBatch_TooLarge(t *testing.T) {
+	handler, mockAnalyzer := createTestHandler()
+	handler.config.BatchMaxURLs = 2
+	router := setupGinTest(handler)
+
+	padding := strings.Repeat("a", 200<<10)
+	bodies := map[string]func() (io.Reader, string){
+		"JSON array": func() (io.Reader, string) {
+			return strings.NewReader(`["https://a.com/` + padding + `"]`), "application/json"
+		},
+		"Newline list": func() (io.Reader, string) {
+			return strings.NewReader("https://a.com/" + padding), "text/plain"
+		},
+		"Uploaded CSV": func() (io.Reader, string) {
+			body := &bytes.Buffer{}
+			writer := multipart.NewWriter(body)
+			part, _ := writer.CreateFormFile("file", "urls.csv")
+			part.Write([]byte("url\nhttps://a.com/" + padding + "\n"))
+			writer.Close()
+			return body, writer.FormDataContentType()
+		},
+	}
+	for name, body := range bodies {
+		reader, contentType := body()
+		req, _ := http.NewRequest("POST", "/batch", reader)
+		req.Header.Set("Content-Type", contentType)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code, name)
+	}
+
+	mockAnalyzer.AssertNotCalled(t, "Analyze")
+}
+
+// TestHistory tests that analyses are saved and can be listed, fetched and deleted
+func TestHistory(t *testing.T) {
+	handler, mockAnalyzer := createTestHandler()
+	history, err := store.NewBoltStore(t.TempDir()+"/history.db", 0)
+	assert.NoError(t, err)
+	defer history.Close()
+	handler.history = history
+	router := setupGinTest(handler)
+
+	for _, url := range []string{"https://a.com", "https://b.com"} {
+		mockAnalyzer.On("Analyze", mock.Anything, url).Return(&models.AnalysisResult{URL: url, PageTitle: "Page " + url, Timestamp: time.Now()})
+
+		req, _ := http.NewRequest("GET", "/analyze?url="+url, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+	}
+
+	req, _ := http.NewRequest("GET", "/history?url=https://a.com", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var page models.HistoryPage
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+	if assert.Len(t, page.Entries, 1) {
+		assert.Equal(t, "https://a.com", page.Entries[0].URL)
+	}
+	assert.Equal(t, 1, page.Total)
+	id := page.Entries[0].ID
+
+	req, _ = http.NewRequest("GET", "/history/"+id, nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var result models.AnalysisResult
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+	assert.Equal(t, id, result.ID)
+	assert.Equal(t, "Page https://a.com", result.PageTitle)
+
+	req, _ = http.NewRequest("DELETE", "/history/"+id, nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	req, _ = http.NewRequest("GET", "/history/"+id, nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	req, _ = http.NewRequest("GET", "/history?from=yesterday", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+// TestHistory_ZeroOffset tests that an offset of zero lists from the first entry, while a limit must be positive
+func TestHistory_ZeroOffset(t *testing.T) {
+	handler, _ := createTestHandler()
+	history, err := store.NewBoltStore(t.TempDir()+"/history.db", 0)
+	assert.NoError(t, err)
+	defer history.Close()
+	handler.history = history
+	router := setupGinTest(handler)
+
+	req, _ := http.NewRequest("GET", "/history?offset=0", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	for _, target := range []string{"/history?offset=-1", "/history?limit=0"} {
+		req, _ = http.NewRequest("GET", target, nil)
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code, target)
+	}
+}
+
+// TestHistory_Disabled tests that the history endpoints report when the history is disabled
+func TestHistory_Disabled(t *testing.T) {
+	handler, _ := createTestHandler()
+	router := setupGinTest(handler)
+
+	req, _ := http.NewRequest("GET", "/history", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+}
+
+// TestComparePages tests comparing a posted result with an HTML snapshot analyzed on the spot
+func TestComparePages(t *testing.T) {
+	handler, mockAnalyzer := createTestHandler()
+	router := setupGinTest(handler)
+
+	snapshot := "<html><head><title>New</title></head></html>"
+	mockAnalyzer.On("AnalyzeHTML", mock.Anything, "https://example.com", snapshot).
+		Return(&models.AnalysisResult{URL: "https://example.com", PageTitle: "New"})
+
+	body, _ := json.Marshal(models.CompareRequest{
+		Base:   models.CompareSource{Result: &models.AnalysisResult{URL: "https://example.com", PageTitle: "Old"}},
+		Target: models.CompareSource{URL: "https://example.com", HTML: snapshot},
+	})
+	req, _ := http.NewRequest("POST", "/compare", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var result models.AnalysisDiff
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+	assert.True(t, result.Summary.HasChanges)
+	if assert.Len(t, result.Fields, 1) {
+		assert.Equal(t, "page_title", result.Fields[0].Field)
+		assert.Equal(t, "Old", result.Fields[0].Before)
+		assert.Equal(t, "New", result.Fields[0].After)
+	}
+	mockAnalyzer.AssertExpectations(t)
+}
+
+// TestWriteTimeout tests that analyses taking longer than the server write timeout still get their response
+func TestWriteTimeout(t *testing.T) {
+	handler, mockAnalyzer := createTestHandler()
+	server := httptest.NewUnstartedServer(setupGinTest(handler))
+	server.Config.WriteTimeout = 50 * time.Millisecond
+	server.Start()
+	defer server.Close()
+
+	// The analyzed page is slow to respond
+	slow := func(mock.Arguments) { time.Sleep(200 * time.Millisecond) }
+	mockAnalyzer.On("Analyze", mock.Anything, "https://example.com").Run(slow).
+		Return(&models.AnalysisResult{URL: "https://example.com", PageTitle: "Example Domain"})
+	mockAnalyzer.On("AnalyzeHTML", mock.Anything, "https://example.com", "<title>Example Domain</title>").Run(slow).
+		Return(&models.AnalysisResult{URL: "https://example.com", PageTitle: "Example Domain"})
+
+	compare, _ := json.Marshal(models.CompareRequest{
+		Base:   models.CompareSource{Result: &models.AnalysisResult{URL: "https://example.com"}},
+		Target: models.CompareSource{URL: "https://example.com"},
+	})
+	requests := map[string]func() (*http.Response, error){
+		"analyze": func() (*http.Response, error) {
+			return http.Get(server.URL + "/analyze?url=https://example.com")
+		},
+		"analyze html": func() (*http.Response, error) {
+			return http.Post(server.URL+"/analyze/html?base_url=https://example.com", "text/html", strings.NewReader("<title>Example Domain</title>"))
+		},
+		"compare": func() (*http.Response, error) {
+			return http.Post(server.URL+"/compare", "application/json", bytes.NewReader(compare))
+		},
+	}
+	for name, request := range requests {
+		resp, err := request()
+		if !assert.NoError(t, err, name) {
+			continue
+		}
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		assert.NoError(t, err, name)
+		assert.Equal(t, http.StatusOK, resp.StatusCode, name)
+		assert.Contains(t, string(body), "Example Domain", name)
+	}
+}
+
+// TestComparePages_TooLarge tests that comparisons carrying more than an HTML snapshot of the upload limit per
+// side are rejected
+func TestComparePages_TooLarge(t *testing.T) {
+	handler, mockAnalyzer := createTestHandler()
+	handler.config.HTMLUploadMaxBytes = 1 << 10
+	router := setupGinTest(handler)
+
+	snapshot := strings.Repeat("<p>page</p>", 8<<10)
+	body, _ := json.Marshal(models.CompareRequest{
+		Base:   models.CompareSource{URL: "https://example.com", HTML: snapshot},
+		Target: models.CompareSource{URL: "https://example.com", HTML: snapshot},
+	})
+	req, _ := http.NewRequest("POST", "/compare", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	mockAnalyzer.AssertNotCalled(t, "AnalyzeHTML")
+}
+
+// TestComparePages_BadRequest tests that both sides of a comparison are required
+func TestComparePages_BadRequest(t *testing.T) {
+	handler, _ := createTestHandler()
+	router := setupGinTest(handler)
+
+	tests := []struct {
+		name           string
+		body           string
+		expectedStatus int
+	}{
+		{
+			name:           "Not JSON",
+			body:           "base=https://example.com",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Missing target",
+			body:           `{"base": {"url": "https://example.com"}}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Stored analysis without history",
+			body:           `{"base": {"id": "1"}, "target": {"id": "2"}}`,
+			expectedStatus: http.StatusServiceUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.name == "Missing target" {
+				handler.analyzer.(*MockPageAnalyzer).On("Analyze", mock.Anything, "https://example.com").
+					Return(models.NewAnalysisResult("https://example.com"))
+			}
+
+			req, _ := http.NewRequest("POST", "/compare", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
+
+// TestMonitors tests creating, reading, updating and deleting monitors
+func TestMonitors(t *testing.T) {
+	handler, mockAnalyzer := createTestHandler()
+	scheduler := monitor.NewScheduler(mockAnalyzer, nil, nil, nil, validator.NewURLValidator(), handler.logger)
+	defer scheduler.Close()
+	handler.monitors = scheduler
+	router := setupGinTest(handler)
+
+	req, _ := http.NewRequest("POST", "/monitors", strings.NewReader(`{"url": "https://example.com", "schedule": "@hourly", "rules": [{"type": "max_broken_links", "threshold": 2}]}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	var created models.Monitor
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	assert.Equal(t, "/monitors/"+created.ID, w.Header().Get("Location"))
+	assert.Equal(t, []models.MonitorRule{{Type: models.RuleMaxBrokenLinks, Threshold: 2}}, created.Rules)
+	assert.NotNil(t, created.NextRunAt)
+
+	req, _ = http.NewRequest("PUT", "/monitors/"+created.ID, strings.NewReader(`{"url": "https://example.com", "schedule": "every day"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "invalid schedule")
+
+	req, _ = http.NewRequest("PUT", "/monitors/"+created.ID, strings.NewReader(`{"url": "https://example.com", "schedule": "0 9 * * 1-5"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	req, _ = http.NewRequest("GET", "/monitors", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var monitors []models.Monitor
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &monitors))
+	if assert.Len(t, monitors, 1) {
+		assert.Equal(t, "0 9 * * 1-5", monitors[0].Schedule)
+	}
+
+	req, _ = http.NewRequest("DELETE", "/monitors/"+created.ID, nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	for _, method := range []string{"GET", "DELETE"} {
+		req, _ = http.NewRequest(method, "/monitors/"+created.ID, nil)
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	}
+
+	req, _ = http.NewRequest("POST", "/monitors/"+created.ID+"/run", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+// TestMonitors_Disabled tests that the monitor endpoints report when monitoring is disabled
+func TestMonitors_Disabled(t *testing.T) {
+	handler, _ := createTestHandler()
+	router := setupGinTest(handler)
+
+	req, _ := http.NewRequest("GET", "/monitors", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+}
+
+// TestReadinessCheck tests that the readiness check fails once the server is draining
+func TestReadinessCheck(t *testing.T) {
+	handler, _ := createTestHandler()
+	router := setupGinTest(handler)
+
+	req, _ := http.NewRequest("GET", "/ready", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"status":"ready"`)
+
+	handler.SetDraining()
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Contains(t, w.Body.String(), `"status":"draining"`)
+}
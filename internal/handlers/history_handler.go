@@ -0,0 +1,176 @@
+package handlers
+
+import (
+	"WebAppAnalyzer/internal/models"
+	"WebAppAnalyzer/internal/store"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"time"
+)
+
+// ListHistory lists past analyses, newest first
+// @Summary List past analyses
+// @Description Lists stored analyses newest first, optionally filtered by URL and date range
+// @Tags History
+// @Produce json
+// @Param url query string false "Only analyses of this exact URL"
+// @Param from query string false "Only analyses at or after this time (RFC 3339 or YYYY-MM-DD)"
+// @Param to query string false "Only analyses at or before this time (RFC 3339 or YYYY-MM-DD, inclusive)"
+// @Param limit query int false "Maximum number of entries to return (default 50, max 500)"
+// @Param offset query int false "Number of matching entries to skip"
+// @Success 200 {object} models.HistoryPage
+// @Failure 400 {object} APIError "Bad Request"
+// @Failure 503 {object} APIError "History is disabled"
+// @Router /history [get]
+func (h *Handler) ListHistory(c *gin.Context) {
+	if !h.historyEnabled(c) {
+		return
+	}
+
+	filter := models.HistoryFilter{URL: c.Query("url")}
+
+	var err error
+	if filter.From, err = parseHistoryTime(c.Query("from"), false); err != nil {
+		h.badHistoryRequest(c, "from must be an RFC 3339 time or a YYYY-MM-DD date")
+		return
+	}
+	if filter.To, err = parseHistoryTime(c.Query("to"), true); err != nil {
+		h.badHistoryRequest(c, "to must be an RFC 3339 time or a YYYY-MM-DD date")
+		return
+	}
+	if filter.Limit, err = parseOptionalPositiveInt(c, "limit"); err != nil {
+		h.badHistoryRequest(c, "limit must be a positive integer")
+		return
+	}
+	if filter.Offset, err = parseOptionalInt(c, "offset"); err != nil {
+		h.badHistoryRequest(c, "offset must be a non-negative integer")
+		return
+	}
+
+	page, err := h.history.List(filter)
+	if err != nil {
+		h.logger.Error("Failed to list history", err)
+		c.JSON(http.StatusInternalServerError, APIError{
+			Error:   "Internal Server Error",
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to read the analysis history",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+// GetHistoryEntry returns a past analysis
+// @Summary Get a past analysis
+// @Description Returns the full result of a stored analysis
+// @Tags History
+// @Produce json
+// @Param id path string true "Analysis ID"
+// @Success 200 {object} models.AnalysisResult
+// @Failure 404 {object} APIError "Analysis not found"
+// @Failure 503 {object} APIError "History is disabled"
+// @Router /history/{id} [get]
+func (h *Handler) GetHistoryEntry(c *gin.Context) {
+	if !h.historyEnabled(c) {
+		return
+	}
+
+	result, err := h.history.Get(c.Param("id"))
+	if err != nil {
+		h.historyError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// DeleteHistoryEntry removes a past analysis
+// @Summary Delete a past analysis
+// @Description Removes a stored analysis from the history
+// @Tags History
+// @Param id path string true "Analysis ID"
+// @Success 204 "Deleted"
+// @Failure 404 {object} APIError "Analysis not found"
+// @Failure 503 {object} APIError "History is disabled"
+// @Router /history/{id} [delete]
+func (h *Handler) DeleteHistoryEntry(c *gin.Context) {
+	if !h.historyEnabled(c) {
+		return
+	}
+
+	if err := h.history.Delete(c.Param("id")); err != nil {
+		h.historyError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// recordResult saves the result in the history when it is enabled. Failing to save does not fail the request.
+func (h *Handler) recordResult(result *models.AnalysisResult) {
+	if h.history == nil {
+		return
+	}
+	if err := h.history.Save(result); err != nil {
+		h.logger.Error("Failed to save analysis to history", err)
+	}
+}
+
+func (h *Handler) historyEnabled(c *gin.Context) bool {
+	if h.history != nil {
+		return true
+	}
+	c.JSON(http.StatusServiceUnavailable, APIError{
+		Error:   "Service Unavailable",
+		Code:    http.StatusServiceUnavailable,
+		Message: "The analysis history is disabled",
+	})
+	return false
+}
+
+func (h *Handler) historyError(c *gin.Context, err error) {
+	if errors.Is(err, store.ErrNotFound) {
+		c.JSON(http.StatusNotFound, APIError{
+			Error:   "Not Found",
+			Code:    http.StatusNotFound,
+			Message: "No analysis found with ID " + c.Param("id"),
+		})
+		return
+	}
+
+	h.logger.Error("Failed to access history", err)
+	c.JSON(http.StatusInternalServerError, APIError{
+		Error:   "Internal Server Error",
+		Code:    http.StatusInternalServerError,
+		Message: "Failed to access the analysis history",
+	})
+}
+
+func (h *Handler) badHistoryRequest(c *gin.Context, message string) {
+	c.JSON(http.StatusBadRequest, APIError{
+		Error:   "Bad Request",
+		Code:    http.StatusBadRequest,
+		Message: message,
+	})
+}
+
+// parseHistoryTime reads an RFC 3339 time or a date. A date used as the end of a range covers the whole day.
+func parseHistoryTime(value string, endOfDay bool) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+
+	date, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if endOfDay {
+		return date.Add(24*time.Hour - time.Nanosecond), nil
+	}
+	return date, nil
+}
@@ -0,0 +1,199 @@
+package handlers
+
+import (
+	"WebAppAnalyzer/internal/jobs"
+	"WebAppAnalyzer/internal/models"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// CreateJob starts an analysis in the background and returns its job ID right away
+// @Summary Create an analysis job
+// @Description Queues the analysis of a web page and returns immediately. Poll the job to follow its progress and get the result, or give a callback URL to have the signed result posted to it once the analysis has finished.
+// @Tags Jobs
+// @Accept json
+// @Produce json
+// @Param url query string false "URL of the web page to analyze"
+// @Param callback_url query string false "URL the result is posted to once the analysis has finished"
+// @Param request body models.JobRequest false "URL of the web page to analyze and callback URL, when not given as query parameters"
+// @Success 202 {object} models.Job
+// @Failure 400 {object} APIError "Bad Request"
+// @Failure 503 {object} APIError "Job queue is full"
+// @Router /jobs [post]
+func (h *Handler) CreateJob(c *gin.Context) {
+	h.logger.WithRequest(c.Request.Method, c.Request.URL.Path, c.ClientIP()).
+		Info("Job request received")
+
+	request := models.JobRequest{URL: c.Query("url"), CallbackURL: c.Query("callback_url")}
+	if request.URL == "" {
+		if err := c.ShouldBindJSON(&request); err != nil {
+			request.URL = ""
+		}
+	}
+	if request.URL == "" {
+		c.JSON(http.StatusBadRequest, APIError{
+			Error:   "Bad Request",
+			Code:    http.StatusBadRequest,
+			Message: "URL is required",
+		})
+		return
+	}
+
+	h.submitJob(c, request, c.Request.URL.Path)
+}
+
+// submitJob queues the analysis and responds with the new job, located under jobsPath
+func (h *Handler) submitJob(c *gin.Context, request models.JobRequest, jobsPath string) {
+	if request.CallbackURL != "" {
+		if err := h.webhooks.ValidateURL(request.CallbackURL); err != nil {
+			c.JSON(http.StatusBadRequest, APIError{
+				Error:   "Bad Request",
+				Code:    http.StatusBadRequest,
+				Message: fmt.Sprintf("Invalid callback_url: %v", err),
+			})
+			return
+		}
+	}
+
+	job, err := h.jobs.Submit(request)
+	if errors.Is(err, jobs.ErrQueueFull) {
+		c.JSON(http.StatusServiceUnavailable, APIError{
+			Error:   "Service Unavailable",
+			Code:    http.StatusServiceUnavailable,
+			Message: "Too many analysis jobs are waiting, try again later",
+		})
+		return
+	}
+	if err != nil {
+		h.logger.Error("Failed to create job", err)
+		c.JSON(http.StatusInternalServerError, APIError{
+			Error:   "Internal Server Error",
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to create the analysis job",
+		})
+		return
+	}
+
+	c.Header("Location", jobsPath+"/"+job.ID)
+	c.JSON(http.StatusAccepted, job)
+}
+
+// GetJob returns the status, progress and, once finished, the result of a job
+// @Summary Get an analysis job
+// @Description Returns the status and progress of an analysis job, and its result once it has finished
+// @Tags Jobs
+// @Produce json
+// @Param id path string true "Job ID"
+// @Success 200 {object} models.Job
+// @Failure 404 {object} APIError "Job not found"
+// @Router /jobs/{id} [get]
+func (h *Handler) GetJob(c *gin.Context) {
+	job, err := h.jobs.Get(c.Param("id"))
+	if err != nil {
+		h.jobNotFound(c)
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
+
+// StreamJobEvents streams the progress of a job as Server-Sent Events
+// @Summary Stream the progress of an analysis job
+// @Description Streams the phases of an analysis job and every link check result as Server-Sent Events. The event name is the event type, and the stream ends with a done event carrying the finished job.
+// @Tags Jobs
+// @Produce text/event-stream
+// @Param id path string true "Job ID"
+// @Success 200 {object} models.AnalysisEvent
+// @Failure 404 {object} APIError "Job not found"
+// @Router /jobs/{id}/events [get]
+func (h *Handler) StreamJobEvents(c *gin.Context) {
+	id := c.Param("id")
+	events, unsubscribe, err := h.jobs.Subscribe(id)
+	if err != nil {
+		h.jobNotFound(c)
+		return
+	}
+	defer unsubscribe()
+
+	disableWriteTimeout(c)
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no")
+
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				// The done event was dropped because the client fell behind, send it from the final job state
+				if job, err := h.jobs.Get(id); err == nil {
+					c.SSEvent(models.EventDone, models.AnalysisEvent{Type: models.EventDone, Progress: job.Progress, Job: &job})
+					c.Writer.Flush()
+				}
+				return
+			}
+			c.SSEvent(event.Type, event)
+			c.Writer.Flush()
+			if event.Type == models.EventDone {
+				return
+			}
+		case <-c.Request.Context().Done():
+			return
+		}
+	}
+}
+
+// JobPage renders the result of a job, or its live progress while it is still running
+func (h *Handler) JobPage(c *gin.Context) {
+	job, err := h.jobs.Get(c.Param("id"))
+	if err != nil {
+		c.HTML(http.StatusNotFound, "index.html", gin.H{
+			"title": h.config.WebAppTitle,
+			"error": "The analysis was not found, it may have expired",
+		})
+		return
+	}
+
+	data := gin.H{
+		"title": h.config.WebAppTitle,
+		"job":   job,
+	}
+	if job.IsFinished() {
+		data["result"] = job.Result
+	}
+	c.HTML(http.StatusOK, "index.html", data)
+}
+
+// CancelJob cancels a queued or running job
+// @Summary Cancel an analysis job
+// @Description Cancels a queued or running analysis job. A running job keeps the partial result gathered so far.
+// @Tags Jobs
+// @Produce json
+// @Param id path string true "Job ID"
+// @Success 200 {object} models.Job
+// @Failure 404 {object} APIError "Job not found"
+// @Failure 409 {object} APIError "Job has already finished"
+// @Router /jobs/{id} [delete]
+func (h *Handler) CancelJob(c *gin.Context) {
+	job, err := h.jobs.Cancel(c.Param("id"))
+	switch {
+	case errors.Is(err, jobs.ErrJobNotFound):
+		h.jobNotFound(c)
+	case errors.Is(err, jobs.ErrJobFinished):
+		c.JSON(http.StatusConflict, APIError{
+			Error:   "Conflict",
+			Code:    http.StatusConflict,
+			Message: "The job has already finished with status " + job.Status,
+		})
+	default:
+		c.JSON(http.StatusOK, job)
+	}
+}
+
+func (h *Handler) jobNotFound(c *gin.Context) {
+	c.JSON(http.StatusNotFound, APIError{
+		Error:   "Not Found",
+		Code:    http.StatusNotFound,
+		Message: "No job found with ID " + c.Param("id"),
+	})
+}
@@ -0,0 +1,204 @@
+package handlers
+
+import (
+	"WebAppAnalyzer/internal/models"
+	"WebAppAnalyzer/internal/monitor"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// CreateMonitor registers a URL to be re-analyzed on a schedule
+// @Summary Create a monitor
+// @Description Registers a URL to be re-analyzed on a cron schedule. Every new result is checked against the rules, and the alerts raised are posted to the webhook. Without rules, new broken links and failed analyses raise alerts.
+// @Tags Monitors
+// @Accept json
+// @Produce json
+// @Param request body models.MonitorRequest true "The monitor"
+// @Success 201 {object} models.Monitor
+// @Failure 400 {object} APIError "Bad Request"
+// @Failure 503 {object} APIError "Monitoring is disabled"
+// @Router /monitors [post]
+func (h *Handler) CreateMonitor(c *gin.Context) {
+	if !h.monitorsEnabled(c) {
+		return
+	}
+
+	var request models.MonitorRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		h.badMonitorRequest(c, "The body must be a JSON monitor")
+		return
+	}
+
+	created, err := h.monitors.Create(request)
+	if err != nil {
+		h.monitorError(c, err)
+		return
+	}
+
+	h.logger.WithField("monitor_id", created.ID).
+		WithField("url", created.URL).
+		Info("Monitor created")
+
+	c.Header("Location", c.Request.URL.Path+"/"+created.ID)
+	c.JSON(http.StatusCreated, created)
+}
+
+// ListMonitors lists the registered monitors
+// @Summary List monitors
+// @Description Lists the registered monitors with their next and last runs and the alerts of the last run
+// @Tags Monitors
+// @Produce json
+// @Success 200 {array} models.Monitor
+// @Failure 503 {object} APIError "Monitoring is disabled"
+// @Router /monitors [get]
+func (h *Handler) ListMonitors(c *gin.Context) {
+	if !h.monitorsEnabled(c) {
+		return
+	}
+
+	c.JSON(http.StatusOK, h.monitors.List())
+}
+
+// GetMonitor returns a monitor
+// @Summary Get a monitor
+// @Description Returns a monitor with its next and last runs and the alerts of the last run
+// @Tags Monitors
+// @Produce json
+// @Param id path string true "Monitor ID"
+// @Success 200 {object} models.Monitor
+// @Failure 404 {object} APIError "Monitor not found"
+// @Failure 503 {object} APIError "Monitoring is disabled"
+// @Router /monitors/{id} [get]
+func (h *Handler) GetMonitor(c *gin.Context) {
+	if !h.monitorsEnabled(c) {
+		return
+	}
+
+	found, err := h.monitors.Get(c.Param("id"))
+	if err != nil {
+		h.monitorError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, found)
+}
+
+// UpdateMonitor replaces a monitor
+// @Summary Update a monitor
+// @Description Replaces the URL, schedule, rules and webhook of a monitor. Pointing it at another URL starts a new baseline for the new_broken_links rule.
+// @Tags Monitors
+// @Accept json
+// @Produce json
+// @Param id path string true "Monitor ID"
+// @Param request body models.MonitorRequest true "The monitor"
+// @Success 200 {object} models.Monitor
+// @Failure 400 {object} APIError "Bad Request"
+// @Failure 404 {object} APIError "Monitor not found"
+// @Failure 503 {object} APIError "Monitoring is disabled"
+// @Router /monitors/{id} [put]
+func (h *Handler) UpdateMonitor(c *gin.Context) {
+	if !h.monitorsEnabled(c) {
+		return
+	}
+
+	var request models.MonitorRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		h.badMonitorRequest(c, "The body must be a JSON monitor")
+		return
+	}
+
+	updated, err := h.monitors.Update(c.Param("id"), request)
+	if err != nil {
+		h.monitorError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, updated)
+}
+
+// DeleteMonitor removes a monitor
+// @Summary Delete a monitor
+// @Description Stops and removes a monitor. Its past results stay in the history.
+// @Tags Monitors
+// @Param id path string true "Monitor ID"
+// @Success 204 "Deleted"
+// @Failure 404 {object} APIError "Monitor not found"
+// @Failure 503 {object} APIError "Monitoring is disabled"
+// @Router /monitors/{id} [delete]
+func (h *Handler) DeleteMonitor(c *gin.Context) {
+	if !h.monitorsEnabled(c) {
+		return
+	}
+
+	if err := h.monitors.Delete(c.Param("id")); err != nil {
+		h.monitorError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// RunMonitor runs a monitor now
+// @Summary Run a monitor now
+// @Description Starts a run of the monitor outside its schedule. Poll the monitor to see the result of the run.
+// @Tags Monitors
+// @Produce json
+// @Param id path string true "Monitor ID"
+// @Success 202 {object} models.Monitor
+// @Failure 404 {object} APIError "Monitor not found"
+// @Failure 503 {object} APIError "Monitoring is disabled"
+// @Router /monitors/{id}/run [post]
+func (h *Handler) RunMonitor(c *gin.Context) {
+	if !h.monitorsEnabled(c) {
+		return
+	}
+
+	started, err := h.monitors.Run(c.Param("id"))
+	if err != nil {
+		h.monitorError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, started)
+}
+
+func (h *Handler) monitorsEnabled(c *gin.Context) bool {
+	if h.monitors != nil {
+		return true
+	}
+	c.JSON(http.StatusServiceUnavailable, APIError{
+		Error:   "Service Unavailable",
+		Code:    http.StatusServiceUnavailable,
+		Message: "Monitoring is disabled",
+	})
+	return false
+}
+
+func (h *Handler) monitorError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, monitor.ErrMonitorNotFound):
+		c.JSON(http.StatusNotFound, APIError{
+			Error:   "Not Found",
+			Code:    http.StatusNotFound,
+			Message: "No monitor found with ID " + c.Param("id"),
+		})
+	case errors.Is(err, monitor.ErrInvalidMonitor):
+		h.badMonitorRequest(c, err.Error())
+	default:
+		h.logger.Error("Failed to manage monitor", err)
+		c.JSON(http.StatusInternalServerError, APIError{
+			Error:   "Internal Server Error",
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to manage the monitor",
+		})
+	}
+}
+
+func (h *Handler) badMonitorRequest(c *gin.Context, message string) {
+	c.JSON(http.StatusBadRequest, APIError{
+		Error:   "Bad Request",
+		Code:    http.StatusBadRequest,
+		Message: message,
+	})
+}
@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"WebAppAnalyzer/internal/models"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// ListWebhookDeliveries lists the recent webhook deliveries
+// @Summary List webhook deliveries
+// @Description Lists the recent deliveries of analysis callbacks and monitor alerts, newest first, with the outcome of every attempt
+// @Tags Webhooks
+// @Produce json
+// @Param status query string false "Only deliveries with this status (pending, delivered or failed)"
+// @Param resource_id query string false "Only deliveries about this job or monitor"
+// @Param limit query int false "Maximum number of deliveries to return (default 50)"
+// @Success 200 {array} models.WebhookDelivery
+// @Failure 400 {object} APIError "Bad Request"
+// @Router /webhooks/deliveries [get]
+func (h *Handler) ListWebhookDeliveries(c *gin.Context) {
+	filter := models.DeliveryFilter{
+		Status:     c.Query("status"),
+		ResourceID: c.Query("resource_id"),
+	}
+
+	var err error
+	if filter.Limit, err = parseOptionalPositiveInt(c, "limit"); err != nil {
+		c.JSON(http.StatusBadRequest, APIError{
+			Error:   "Bad Request",
+			Code:    http.StatusBadRequest,
+			Message: "limit must be a positive integer",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, h.webhooks.List(filter))
+}
+
+// GetWebhookDelivery returns a webhook delivery
+// @Summary Get a webhook delivery
+// @Description Returns a delivery of an analysis callback or monitor alert with the outcome of every attempt
+// @Tags Webhooks
+// @Produce json
+// @Param id path string true "Delivery ID"
+// @Success 200 {object} models.WebhookDelivery
+// @Failure 404 {object} APIError "Delivery not found"
+// @Router /webhooks/deliveries/{id} [get]
+func (h *Handler) GetWebhookDelivery(c *gin.Context) {
+	delivery, err := h.webhooks.Get(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, APIError{
+			Error:   "Not Found",
+			Code:    http.StatusNotFound,
+			Message: "No webhook delivery found with ID " + c.Param("id"),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, delivery)
+}
@@ -0,0 +1,388 @@
+package jobs
+
+import (
+	"WebAppAnalyzer/config/env"
+	"WebAppAnalyzer/config/logger"
+	"WebAppAnalyzer/internal/analyzer"
+	"WebAppAnalyzer/internal/models"
+	"WebAppAnalyzer/internal/store"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"sync"
+	"time"
+)
+
+const (
+	defaultJobWorkers   = 2
+	defaultJobQueueSize = 100
+	defaultJobRetention = time.Hour
+	// subscriberBufferSize is the number of events a slow subscriber may fall behind before events are dropped
+	subscriberBufferSize = 256
+)
+
+var (
+	ErrQueueFull   = errors.New("job queue is full")
+	ErrJobNotFound = errors.New("job not found")
+	ErrJobFinished = errors.New("job has already finished")
+)
+
+// Analyzer runs the analysis of a single page
+type Analyzer interface {
+	Analyze(ctx context.Context, url string) *models.AnalysisResult
+}
+
+// Callbacks delivers the results of jobs to their callback URLs in the background
+type Callbacks interface {
+	Deliver(event, resourceID, url string, payload any) models.WebhookDelivery
+}
+
+// Manager runs analyses in the background. Jobs wait in a bounded queue for one of the job workers, and
+// finished jobs are kept for the configured retention period so their results can be polled.
+type Manager struct {
+	analyzer  Analyzer
+	history   store.ResultStore
+	callbacks Callbacks
+	logger    *logger.Logger
+	retention time.Duration
+	queueSize int
+	// wake tells an idle worker that a job was queued
+	wake chan struct{}
+
+	mu    sync.Mutex
+	jobs  map[string]*job
+	queue []*job
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// job is a background analysis. The embedded Job and the subscribers are guarded by the manager lock.
+type job struct {
+	models.Job
+	ctx         context.Context
+	cancel      context.CancelFunc
+	subscribers []chan models.AnalysisEvent
+}
+
+// NewManager creates a job manager and starts its workers. Finished results are saved to the history when
+// a store is given, and posted to the callback URL of their job through callbacks.
+func NewManager(analyzer Analyzer, history store.ResultStore, callbacks Callbacks, logger *logger.Logger, c *env.Config) *Manager {
+	workers := c.JobWorkers
+	if workers <= 0 {
+		workers = defaultJobWorkers
+	}
+	queueSize := c.JobQueueSize
+	if queueSize <= 0 {
+		queueSize = defaultJobQueueSize
+	}
+	retention := time.Duration(c.JobRetentionInSeconds) * time.Second
+	if retention <= 0 {
+		retention = defaultJobRetention
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	m := &Manager{
+		analyzer:  analyzer,
+		history:   history,
+		callbacks: callbacks,
+		logger:    logger,
+		retention: retention,
+		queueSize: queueSize,
+		wake:      make(chan struct{}, 1),
+		jobs:      make(map[string]*job),
+		ctx:       ctx,
+		cancel:    cancel,
+	}
+
+	for i := 0; i < workers; i++ {
+		m.wg.Add(1)
+		go m.work()
+	}
+
+	return m
+}
+
+// Submit queues an analysis of the requested URL and returns the new job
+func (m *Manager) Submit(request models.JobRequest) (models.Job, error) {
+	id, err := newJobID()
+	if err != nil {
+		return models.Job{}, err
+	}
+
+	ctx, cancel := context.WithCancel(m.ctx)
+	j := &job{
+		Job: models.Job{
+			ID:          id,
+			URL:         request.URL,
+			Status:      models.JobStatusQueued,
+			CreatedAt:   time.Now(),
+			CallbackURL: request.CallbackURL,
+		},
+		ctx:    ctx,
+		cancel: cancel,
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.prune()
+
+	if len(m.queue) >= m.queueSize {
+		cancel()
+		return models.Job{}, ErrQueueFull
+	}
+	m.queue = append(m.queue, j)
+	m.jobs[id] = j
+	m.signal()
+
+	m.logger.WithField("job_id", id).WithField("url", request.URL).Info("Analysis job queued")
+
+	return j.Job, nil
+}
+
+// Get returns the current state of a job
+func (m *Manager) Get(id string) (models.Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.prune()
+
+	j, ok := m.jobs[id]
+	if !ok {
+		return models.Job{}, ErrJobNotFound
+	}
+	return j.Job, nil
+}
+
+// Subscribe returns the events of a job as they happen, starting with its current progress. The channel is
+// closed after the done event. The returned function stops the subscription early.
+func (m *Manager) Subscribe(id string) (<-chan models.AnalysisEvent, func(), error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	j, ok := m.jobs[id]
+	if !ok {
+		return nil, nil, ErrJobNotFound
+	}
+
+	events := make(chan models.AnalysisEvent, subscriberBufferSize)
+	events <- models.AnalysisEvent{Type: models.EventProgress, Progress: j.Progress}
+	if j.IsFinished() {
+		events <- j.doneEvent()
+		close(events)
+		return events, func() {}, nil
+	}
+	j.subscribers = append(j.subscribers, events)
+
+	unsubscribe := func() {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		for i, subscriber := range j.subscribers {
+			if subscriber == events {
+				j.subscribers = append(j.subscribers[:i], j.subscribers[i+1:]...)
+				close(events)
+				break
+			}
+		}
+	}
+
+	return events, unsubscribe, nil
+}
+
+// Cancel stops a queued or running job. A running job keeps the partial result of its analysis.
+func (m *Manager) Cancel(id string) (models.Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	j, ok := m.jobs[id]
+	if !ok {
+		return models.Job{}, ErrJobNotFound
+	}
+
+	switch j.Status {
+	case models.JobStatusQueued:
+		// Leave the queue right away so the job no longer holds one of its slots
+		m.dequeue(j)
+		j.finish(models.JobStatusCancelled)
+	case models.JobStatusRunning:
+		// The worker records the cancellation once the analysis has stopped
+		j.cancel()
+	case models.JobStatusCancelled:
+	default:
+		return j.Job, ErrJobFinished
+	}
+
+	m.logger.WithField("job_id", id).Info("Analysis job cancelled")
+
+	return j.Job, nil
+}
+
+// Close cancels the jobs still queued or running and waits for the workers to stop
+func (m *Manager) Close() {
+	m.cancel()
+	m.wg.Wait()
+}
+
+// work runs queued jobs until the manager is closed
+func (m *Manager) work() {
+	defer m.wg.Done()
+
+	for {
+		if j := m.next(); j != nil {
+			m.run(j)
+			continue
+		}
+		select {
+		case <-m.wake:
+		case <-m.ctx.Done():
+			return
+		}
+	}
+}
+
+// next takes the oldest job from the queue, or returns nil when the queue is empty
+func (m *Manager) next() *job {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if len(m.queue) == 0 {
+		return nil
+	}
+	j := m.queue[0]
+	m.queue[0] = nil
+	m.queue = m.queue[1:]
+	// Pass the wake up on so another idle worker picks up the jobs left behind
+	if len(m.queue) > 0 {
+		m.signal()
+	}
+	return j
+}
+
+// dequeue removes a job from the queue. Callers must hold the manager lock.
+func (m *Manager) dequeue(j *job) {
+	for i, queued := range m.queue {
+		if queued == j {
+			m.queue = append(m.queue[:i], m.queue[i+1:]...)
+			return
+		}
+	}
+}
+
+// signal wakes an idle worker without waiting when one is already due to wake up
+func (m *Manager) signal() {
+	select {
+	case m.wake <- struct{}{}:
+	default:
+	}
+}
+
+// run performs the analysis of a job and records its outcome
+func (m *Manager) run(j *job) {
+	m.mu.Lock()
+	// Jobs cancelled since they were taken from the queue, or taken as the manager closes, are not started
+	if j.Status != models.JobStatusQueued || m.ctx.Err() != nil {
+		m.mu.Unlock()
+		return
+	}
+	startedAt := time.Now()
+	j.Status = models.JobStatusRunning
+	j.StartedAt = &startedAt
+	m.mu.Unlock()
+
+	ctx := analyzer.WithProgress(j.ctx, func(event models.AnalysisEvent) {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+
+		j.Progress = event.Progress
+		j.publish(event)
+	})
+
+	result := m.analyzer.Analyze(ctx, j.URL)
+	result.AnalysisTime = time.Since(startedAt).String()
+
+	if m.history != nil {
+		if err := m.history.Save(result); err != nil {
+			m.logger.Error("Failed to save analysis to history", err)
+		}
+	}
+
+	status := models.JobStatusCompleted
+	switch {
+	case j.ctx.Err() != nil:
+		status = models.JobStatusCancelled
+	case !result.IsSuccessful():
+		status = models.JobStatusFailed
+	}
+	// Cancelled jobs were stopped on purpose, so only analyses that ran to the end are posted back. The callback
+	// is handed over outside the lock, so a slow notifier does not hold up the other jobs.
+	var deliveryID string
+	if j.CallbackURL != "" && m.callbacks != nil && status != models.JobStatusCancelled {
+		deliveryID = m.callbacks.Deliver(models.WebhookEventAnalysisCompleted, j.ID, j.CallbackURL, result).ID
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	j.Result = result
+	if status == models.JobStatusFailed {
+		j.Error = result.Error
+	}
+	j.CallbackDeliveryID = deliveryID
+	j.finish(status)
+
+	m.logger.WithField("job_id", j.ID).
+		WithField("status", j.Status).
+		WithField("duration", time.Since(startedAt)).
+		Info("Analysis job finished")
+}
+
+// finish moves the job to a final status and ends its subscriptions. Callers must hold the manager lock.
+func (j *job) finish(status string) {
+	finishedAt := time.Now()
+	j.Status = status
+	j.FinishedAt = &finishedAt
+	j.cancel()
+
+	j.publish(j.doneEvent())
+	for _, subscriber := range j.subscribers {
+		close(subscriber)
+	}
+	j.subscribers = nil
+}
+
+// publish sends the event to the subscribers without waiting for slow ones. Callers must hold the manager lock.
+func (j *job) publish(event models.AnalysisEvent) {
+	for _, subscriber := range j.subscribers {
+		select {
+		case subscriber <- event:
+		default:
+		}
+	}
+}
+
+// doneEvent returns the event announcing the job has finished. Callers must hold the manager lock.
+func (j *job) doneEvent() models.AnalysisEvent {
+	finished := j.Job
+	return models.AnalysisEvent{Type: models.EventDone, Progress: j.Progress, Job: &finished}
+}
+
+// prune forgets finished jobs older than the retention period. Callers must hold the manager lock.
+func (m *Manager) prune() {
+	for id, j := range m.jobs {
+		if j.FinishedAt != nil && time.Since(*j.FinishedAt) > m.retention {
+			delete(m.jobs, id)
+		}
+	}
+}
+
+// newJobID returns a random job identifier
+func newJobID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,246 @@
+package jobs
+
+import (
+	"WebAppAnalyzer/config/env"
+	"WebAppAnalyzer/config/logger"
+	"WebAppAnalyzer/internal/models"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// analyzerFunc adapts a function to the Analyzer interface
+type analyzerFunc func(ctx context.Context, url string) *models.AnalysisResult
+
+func (f analyzerFunc) Analyze(ctx context.Context, url string) *models.AnalysisResult {
+	return f(ctx, url)
+}
+
+// blockingAnalyzer waits until the analysis is cancelled
+func blockingAnalyzer(started chan<- string) analyzerFunc {
+	return func(ctx context.Context, url string) *models.AnalysisResult {
+		select {
+		case started <- url:
+		default:
+		}
+		<-ctx.Done()
+		return models.NewAnalysisResult(url)
+	}
+}
+
+func createTestManager(analyzer Analyzer, c *env.Config) *Manager {
+	c.LogLevel = "debug"
+	return NewManager(analyzer, nil, nil, logger.NewLogger(*c), c)
+}
+
+// waitForStatus polls the job until it reaches the expected status
+func waitForStatus(t *testing.T, m *Manager, id, status string) models.Job {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		job, err := m.Get(id)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if job.Status == status {
+			return job
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected status %s, got %s", status, job.Status)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// TestManagerLifecycle tests that jobs complete or fail according to the analysis result
+func TestManagerLifecycle(t *testing.T) {
+	m := createTestManager(analyzerFunc(func(ctx context.Context, url string) *models.AnalysisResult {
+		result := models.NewAnalysisResult(url)
+		if url == "https://broken.com" {
+			result.SetError("HTTP Error: 404 - Not Found", 404)
+		}
+		return result
+	}), &env.Config{})
+	defer m.Close()
+
+	ok, err := m.Submit(models.JobRequest{URL: "https://example.com"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	broken, err := m.Submit(models.JobRequest{URL: "https://broken.com"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	job := waitForStatus(t, m, ok.ID, models.JobStatusCompleted)
+	if job.Result == nil || job.StartedAt == nil || job.FinishedAt == nil {
+		t.Error("Expected a completed job to have a result and timestamps")
+	}
+
+	job = waitForStatus(t, m, broken.ID, models.JobStatusFailed)
+	if job.Error != "HTTP Error: 404 - Not Found" {
+		t.Errorf("Expected the analysis error, got %q", job.Error)
+	}
+}
+
+// TestManagerCancel tests cancelling queued and running jobs
+func TestManagerCancel(t *testing.T) {
+	started := make(chan string, 1)
+	m := createTestManager(blockingAnalyzer(started), &env.Config{JobWorkers: 1})
+	defer m.Close()
+
+	running, _ := m.Submit(models.JobRequest{URL: "https://running.com"})
+	queued, _ := m.Submit(models.JobRequest{URL: "https://queued.com"})
+	<-started
+
+	job, err := m.Cancel(queued.ID)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if job.Status != models.JobStatusCancelled {
+		t.Errorf("Expected queued job to be cancelled, got %s", job.Status)
+	}
+
+	if _, err := m.Cancel(running.ID); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	job = waitForStatus(t, m, running.ID, models.JobStatusCancelled)
+	if job.Result == nil {
+		t.Error("Expected a cancelled running job to keep its partial result")
+	}
+
+	// The cancelled queued job is never started
+	select {
+	case url := <-started:
+		t.Errorf("Expected no further analysis, got %s", url)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	if _, err := m.Cancel("unknown"); !errors.Is(err, ErrJobNotFound) {
+		t.Errorf("Expected ErrJobNotFound, got %v", err)
+	}
+}
+
+// TestManagerQueueFull tests that submissions beyond the queue size are rejected
+func TestManagerQueueFull(t *testing.T) {
+	started := make(chan string, 1)
+	m := createTestManager(blockingAnalyzer(started), &env.Config{JobWorkers: 1, JobQueueSize: 2})
+	defer m.Close()
+
+	if _, err := m.Submit(models.JobRequest{URL: "https://running.com"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	<-started
+
+	for i := 0; i < 2; i++ {
+		if _, err := m.Submit(models.JobRequest{URL: "https://queued.com"}); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+	if _, err := m.Submit(models.JobRequest{URL: "https://rejected.com"}); !errors.Is(err, ErrQueueFull) {
+		t.Errorf("Expected ErrQueueFull, got %v", err)
+	}
+}
+
+// TestManagerQueueFull_Cancelled tests that a job cancelled while queued frees its place in the queue
+func TestManagerQueueFull_Cancelled(t *testing.T) {
+	started := make(chan string, 1)
+	m := createTestManager(blockingAnalyzer(started), &env.Config{JobWorkers: 1, JobQueueSize: 2})
+	defer m.Close()
+
+	if _, err := m.Submit(models.JobRequest{URL: "https://running.com"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	<-started
+
+	queued, err := m.Submit(models.JobRequest{URL: "https://queued.com"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := m.Submit(models.JobRequest{URL: "https://queued.com"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := m.Submit(models.JobRequest{URL: "https://rejected.com"}); !errors.Is(err, ErrQueueFull) {
+		t.Fatalf("Expected ErrQueueFull, got %v", err)
+	}
+
+	if _, err := m.Cancel(queued.ID); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := m.Submit(models.JobRequest{URL: "https://resubmitted.com"}); err != nil {
+		t.Errorf("Expected the cancelled job to free its place, got %v", err)
+	}
+}
+
+// TestManagerRetention tests that finished jobs are forgotten after the retention period
+func TestManagerRetention(t *testing.T) {
+	m := createTestManager(analyzerFunc(func(ctx context.Context, url string) *models.AnalysisResult {
+		return models.NewAnalysisResult(url)
+	}), &env.Config{JobRetentionInSeconds: 1})
+	defer m.Close()
+
+	job, _ := m.Submit(models.JobRequest{URL: "https://example.com"})
+	waitForStatus(t, m, job.ID, models.JobStatusCompleted)
+
+	// Age the job past the retention period
+	m.mu.Lock()
+	finishedAt := time.Now().Add(-2 * time.Second)
+	m.jobs[job.ID].FinishedAt = &finishedAt
+	m.mu.Unlock()
+
+	if _, err := m.Get(job.ID); !errors.Is(err, ErrJobNotFound) {
+		t.Errorf("Expected ErrJobNotFound after retention, got %v", err)
+	}
+}
+
+// TestManagerSubscribe tests that subscribers get the analysis events followed by the done event
+func TestManagerSubscribe(t *testing.T) {
+	started := make(chan string, 1)
+	release := make(chan struct{})
+	m := createTestManager(analyzerFunc(func(ctx context.Context, url string) *models.AnalysisResult {
+		started <- url
+		<-release
+		return models.NewAnalysisResult(url)
+	}), &env.Config{})
+	defer m.Close()
+
+	job, _ := m.Submit(models.JobRequest{URL: "https://example.com"})
+	events, unsubscribe, err := m.Subscribe(job.ID)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer unsubscribe()
+
+	// Publish a link check result the way the analyzer progress function does
+	<-started
+	m.mu.Lock()
+	m.jobs[job.ID].publish(models.AnalysisEvent{Type: models.EventLink, Link: &models.LinkDetail{URL: "https://example.com/about"}})
+	m.mu.Unlock()
+	close(release)
+
+	var types []string
+	for event := range events {
+		types = append(types, event.Type)
+	}
+
+	expected := []string{models.EventProgress, models.EventLink, models.EventDone}
+	if strings.Join(types, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected events %v, got %v", expected, types)
+	}
+
+	// Subscribing to a finished job replays its final state
+	events, _, err = m.Subscribe(job.ID)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	types = nil
+	for event := range events {
+		types = append(types, event.Type)
+	}
+	if len(types) != 2 || types[1] != models.EventDone {
+		t.Errorf("Expected the progress and done events, got %v", types)
+	}
+}
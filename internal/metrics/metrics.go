@@ -0,0 +1,230 @@
+package metrics
+
+import (
+	"WebAppAnalyzer/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const namespace = "web_analyzer"
+
+// Outcomes of analyses and page fetches
+const (
+	OutcomeSuccess = "success"
+	OutcomeError   = "error"
+)
+
+// Sources of analyses: pages fetched by the analyzer, HTML documents sent to it and pages of static site builds
+const (
+	SourceFetch      = "fetch"
+	SourceHTML       = "html"
+	SourceStaticSite = "static_site"
+)
+
+// Outcomes of link checks
+const (
+	OutcomeAccessible   = "accessible"
+	OutcomeInaccessible = "inaccessible"
+)
+
+// Metrics holds the Prometheus collectors of the service on a registry of their own. A nil *Metrics observes
+// nothing, so the analyzer can run without metrics, such as from the command line.
+type Metrics struct {
+	registry *prometheus.Registry
+
+	analyses          *prometheus.CounterVec
+	analysisDuration  *prometheus.HistogramVec
+	fetchDuration     *prometheus.HistogramVec
+	linkChecks        *prometheus.CounterVec
+	linkCheckDuration *prometheus.HistogramVec
+	skippedLinks      *prometheus.CounterVec
+	httpRequests      *prometheus.CounterVec
+	httpDuration      *prometheus.HistogramVec
+}
+
+// New creates the collectors and registers them with the Go runtime and process collectors. poolStats reports
+// the state of the link check workers whenever the metrics are scraped.
+func New(poolStats func() models.LinkCheckPoolStats) *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		analyses: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "analyses_total",
+			Help:      "Analyses by source, outcome and HTTP status code of the page, 0 when it could not be fetched.",
+		}, []string{"source", "outcome", "status_code"}),
+		analysisDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "analysis_duration_seconds",
+			Help:      "Duration of analyses by source, link checks included.",
+			Buckets:   []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 20, 30, 60},
+		}, []string{"source", "outcome"}),
+		fetchDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "page_fetch_duration_seconds",
+			Help:      "Time until the response headers of an analyzed page were received.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"outcome"}),
+		linkChecks: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "link_checks_total",
+			Help:      "Link checks by outcome and error class, answered from the link status cache or not.",
+		}, []string{"outcome", "error_class", "cached"}),
+		linkCheckDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "link_check_duration_seconds",
+			Help:      "Duration of the link checks not answered from the cache, redirects and retries included.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"outcome"}),
+		skippedLinks: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "links_skipped_total",
+			Help:      "Links found on analyzed pages that were not checked, by reason, such as the link limit or a cancelled analysis.",
+		}, []string{"reason"}),
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "HTTP requests served by method, route and status code.",
+		}, []string{"method", "route", "status_code"}),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "Duration of the HTTP requests served by method and route.",
+			Buckets:   []float64{0.005, 0.01, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60},
+		}, []string{"method", "route"}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.analyses,
+		m.analysisDuration,
+		m.fetchDuration,
+		m.linkChecks,
+		m.linkCheckDuration,
+		m.skippedLinks,
+		m.httpRequests,
+		m.httpDuration,
+	)
+	m.registerPoolStats(poolStats)
+
+	return m
+}
+
+// registerPoolStats exposes the utilization and queue depth of the link check workers
+func (m *Metrics) registerPoolStats(poolStats func() models.LinkCheckPoolStats) {
+	gauge := func(name, help string, value func(models.LinkCheckPoolStats) float64) prometheus.Collector {
+		return prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "link_check_pool",
+			Name:      name,
+			Help:      help,
+		}, func() float64 {
+			return value(poolStats())
+		})
+	}
+	counter := func(name, help string, value func(models.LinkCheckPoolStats) float64) prometheus.Collector {
+		return prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "link_check_pool",
+			Name:      name,
+			Help:      help,
+		}, func() float64 {
+			return value(poolStats())
+		})
+	}
+
+	m.registry.MustRegister(
+		gauge("workers", "Link check workers.", func(s models.LinkCheckPoolStats) float64 {
+			return float64(s.Workers)
+		}),
+		gauge("busy_workers", "Link check workers checking a link.", func(s models.LinkCheckPoolStats) float64 {
+			return float64(s.BusyWorkers)
+		}),
+		gauge("active_analyses", "Analyses with links waiting for or being checked.", func(s models.LinkCheckPoolStats) float64 {
+			return float64(s.ActiveAnalyses)
+		}),
+		gauge("queued_links", "Links waiting for a link check worker.", func(s models.LinkCheckPoolStats) float64 {
+			return float64(s.QueuedLinks)
+		}),
+		counter("completed_checks_total", "Link checks completed by the workers.", func(s models.LinkCheckPoolStats) float64 {
+			return float64(s.CompletedChecks)
+		}),
+		counter("cancelled_checks_total", "Queued link checks dropped because their analysis was cancelled.", func(s models.LinkCheckPoolStats) float64 {
+			return float64(s.CancelledChecks)
+		}),
+	)
+}
+
+// Handler serves the metrics in the Prometheus text exposition format
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// Middleware records the count and duration of the requests served. Requests are labelled with their route
+// pattern rather than their path, so IDs in paths do not multiply the series.
+func (m *Metrics) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		startTime := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		m.httpRequests.WithLabelValues(c.Request.Method, route, strconv.Itoa(c.Writer.Status())).Inc()
+		m.httpDuration.WithLabelValues(c.Request.Method, route).Observe(time.Since(startTime).Seconds())
+	}
+}
+
+// ObserveAnalysis records the outcome and duration of the analysis of a page from the given source
+func (m *Metrics) ObserveAnalysis(source string, result *models.AnalysisResult, duration time.Duration) {
+	if m == nil {
+		return
+	}
+	outcome := OutcomeSuccess
+	if !result.IsSuccessful() {
+		outcome = OutcomeError
+	}
+	m.analyses.WithLabelValues(source, outcome, strconv.Itoa(result.HTTPStatusCode)).Inc()
+	m.analysisDuration.WithLabelValues(source, outcome).Observe(duration.Seconds())
+}
+
+// ObservePageFetch records the latency of fetching a page to analyze
+func (m *Metrics) ObservePageFetch(duration time.Duration, err error) {
+	if m == nil {
+		return
+	}
+	outcome := OutcomeSuccess
+	if err != nil {
+		outcome = OutcomeError
+	}
+	m.fetchDuration.WithLabelValues(outcome).Observe(duration.Seconds())
+}
+
+// ObserveLinkCheck records the outcome of a link check, and its duration when it was not answered from the cache
+func (m *Metrics) ObserveLinkCheck(detail models.LinkDetail) {
+	if m == nil {
+		return
+	}
+	outcome := OutcomeAccessible
+	if !detail.IsAccessible {
+		outcome = OutcomeInaccessible
+	}
+	m.linkChecks.WithLabelValues(outcome, detail.ErrorClass, strconv.FormatBool(detail.Cached)).Inc()
+	if !detail.Cached {
+		m.linkCheckDuration.WithLabelValues(outcome).Observe((time.Duration(detail.ResponseTimeMs) * time.Millisecond).Seconds())
+	}
+}
+
+// ObserveSkippedLink records a link that was not checked
+func (m *Metrics) ObserveSkippedLink(reason string) {
+	if m == nil {
+		return
+	}
+	m.skippedLinks.WithLabelValues(reason).Inc()
+}
@@ -0,0 +1,92 @@
+package metrics
+
+import (
+	"WebAppAnalyzer/internal/models"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func scrape(t *testing.T, engine *gin.Engine) string {
+	recorder := httptest.NewRecorder()
+	engine.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("Expected the metrics to be served, got %d", recorder.Code)
+	}
+	body, _ := io.ReadAll(recorder.Body)
+	return string(body)
+}
+
+// TestMetrics tests that the observations and the pool stats are exposed in the text exposition format
+func TestMetrics(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	m := New(func() models.LinkCheckPoolStats {
+		return models.LinkCheckPoolStats{Workers: 5, BusyWorkers: 2, QueuedLinks: 7, CompletedChecks: 40}
+	})
+
+	engine := gin.New()
+	engine.Use(m.Middleware())
+	engine.GET("/jobs/:id", func(c *gin.Context) {
+		c.Status(http.StatusNotFound)
+	})
+	engine.GET("/metrics", gin.WrapH(m.Handler()))
+
+	engine.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/jobs/123", nil))
+	engine.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/missing", nil))
+
+	success := models.NewAnalysisResult("https://example.com")
+	success.HTTPStatusCode = http.StatusOK
+	m.ObserveAnalysis(SourceFetch, success, time.Second)
+	failed := models.NewAnalysisResult("https://example.com/missing")
+	failed.SetError("HTTP Error: 404 - Not Found", http.StatusNotFound)
+	m.ObserveAnalysis(SourceFetch, failed, time.Second)
+	m.ObserveAnalysis(SourceHTML, models.NewAnalysisResult("https://example.com"), time.Second)
+
+	m.ObservePageFetch(200*time.Millisecond, nil)
+	m.ObservePageFetch(time.Second, errors.New("connection refused"))
+	m.ObserveLinkCheck(models.LinkDetail{IsAccessible: true, ResponseTimeMs: 120})
+	m.ObserveLinkCheck(models.LinkDetail{IsAccessible: false, ErrorClass: models.LinkErrorTimeout, Cached: true})
+	m.ObserveSkippedLink(models.SkipReasonLinkLimit)
+
+	body := scrape(t, engine)
+	expected := []string{
+		`web_analyzer_analyses_total{outcome="success",source="fetch",status_code="200"} 1`,
+		`web_analyzer_analyses_total{outcome="error",source="fetch",status_code="404"} 1`,
+		`web_analyzer_analyses_total{outcome="success",source="html",status_code="200"} 1`,
+		`web_analyzer_analysis_duration_seconds_count{outcome="success",source="fetch"} 1`,
+		`web_analyzer_page_fetch_duration_seconds_count{outcome="error"} 1`,
+		`web_analyzer_link_checks_total{cached="false",error_class="",outcome="accessible"} 1`,
+		`web_analyzer_link_checks_total{cached="true",error_class="timeout",outcome="inaccessible"} 1`,
+		`web_analyzer_link_check_duration_seconds_count{outcome="accessible"} 1`,
+		`web_analyzer_links_skipped_total{reason="link_limit"} 1`,
+		`web_analyzer_link_check_pool_busy_workers 2`,
+		`web_analyzer_link_check_pool_queued_links 7`,
+		`web_analyzer_link_check_pool_completed_checks_total 40`,
+		`web_analyzer_http_requests_total{method="GET",route="/jobs/:id",status_code="404"} 1`,
+		`web_analyzer_http_requests_total{method="GET",route="unmatched",status_code="404"} 1`,
+		`go_goroutines`,
+	}
+	for _, line := range expected {
+		if !strings.Contains(body, line) {
+			t.Errorf("Expected the metrics to contain %q", line)
+		}
+	}
+	// Cached link checks did not take the time of a request
+	if strings.Contains(body, `web_analyzer_link_check_duration_seconds_count{outcome="inaccessible"}`) {
+		t.Error("Expected cached link checks to be left out of the latency histogram")
+	}
+}
+
+// TestNilMetrics tests that a nil *Metrics observes nothing without panicking
+func TestNilMetrics(t *testing.T) {
+	var m *Metrics
+	m.ObserveAnalysis(SourceFetch, models.NewAnalysisResult("https://example.com"), time.Second)
+	m.ObservePageFetch(time.Second, nil)
+	m.ObserveLinkCheck(models.LinkDetail{})
+	m.ObserveSkippedLink(models.SkipReasonCancelled)
+}
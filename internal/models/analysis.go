@@ -4,29 +4,121 @@ import "time"
 
 // AnalysisResult represents the result of analyzing a web page
 type AnalysisResult struct {
-	URL               string            `json:"url" example:"https://example.com"`
-	HTMLVersion       string            `json:"html_version" example:"HTML5"`
-	PageTitle         string            `json:"page_title" example:"Example Page"`
-	Headings          map[string]int    `json:"headings"`
-	InternalLinks     int               `json:"internal_links" example:"5"`
-	ExternalLinks     int               `json:"external_links" example:"2"`
-	InaccessibleLinks int               `json:"inaccessible_links" example:"0"`
-	HasLoginForm      bool              `json:"has_login_form" example:"true"`
-	AnalysisTime      string            `json:"analysis_time" example:"1.234s"` // Changed from time.Duration to string
-	Timestamp         time.Time         `json:"timestamp" example:"2023-01-01T12:00:00Z"`
-	Error             string            `json:"error,omitempty" example:"Failed to fetch page"`
-	HTTPStatusCode    int               `json:"http_status_code,omitempty" example:"200"`
-	Images            []ImageInfo       `json:"images"`
-	MetaTags          []MetaTag         `json:"meta_tags"`
-	Scripts           []ScriptInfo      `json:"scripts"`
-	Stylesheets       []StylesheetInfo  `json:"stylesheets"`
-	Forms             []FormInfo        `json:"forms"`
-	Tables            int               `json:"tables" example:"2"`
-	Lists             int               `json:"lists" example:"5"`
-	Buttons           int               `json:"buttons" example:"3"`
-	Inputs            int               `json:"inputs" example:"8"`
-	TextContent       TextContentInfo   `json:"text_content"`
-	Accessibility     AccessibilityInfo `json:"accessibility"`
+	ID                        string            `json:"id,omitempty" example:"0000018c2a5f3e1a9b1c2d3e"`
+	URL                       string            `json:"url" example:"https://example.com"`
+	HTMLVersion               string            `json:"html_version" example:"HTML5"`
+	PageTitle                 string            `json:"page_title" example:"Example Page"`
+	Headings                  map[string]int    `json:"headings"`
+	InternalLinks             int               `json:"internal_links" example:"5"`
+	ExternalLinks             int               `json:"external_links" example:"2"`
+	InaccessibleLinks         int               `json:"inaccessible_links" example:"0"`
+	InaccessibleInternalLinks int               `json:"inaccessible_internal_links" example:"0"`
+	InaccessibleExternalLinks int               `json:"inaccessible_external_links" example:"0"`
+	Links                     []LinkDetail      `json:"links"`
+	SkippedLinks              []SkippedLink     `json:"skipped_links"`
+	LinkCheckComplete         bool              `json:"link_check_complete" example:"true"`
+	HasLoginForm              bool              `json:"has_login_form" example:"true"`
+	AnalysisTime              string            `json:"analysis_time" example:"1.234s"` // Changed from time.Duration to string
+	Timestamp                 time.Time         `json:"timestamp" example:"2023-01-01T12:00:00Z"`
+	Error                     string            `json:"error,omitempty" example:"Failed to fetch page"`
+	HTTPStatusCode            int               `json:"http_status_code,omitempty" example:"200"`
+	Images                    []ImageInfo       `json:"images"`
+	MetaTags                  []MetaTag         `json:"meta_tags"`
+	Scripts                   []ScriptInfo      `json:"scripts"`
+	Stylesheets               []StylesheetInfo  `json:"stylesheets"`
+	Forms                     []FormInfo        `json:"forms"`
+	Tables                    int               `json:"tables" example:"2"`
+	Lists                     int               `json:"lists" example:"5"`
+	Buttons                   int               `json:"buttons" example:"3"`
+	Inputs                    int               `json:"inputs" example:"8"`
+	TextContent               TextContentInfo   `json:"text_content"`
+	Accessibility             AccessibilityInfo `json:"accessibility"`
+
+	// FetchTiming breaks down the fetch of the page, it is absent for documents that were not fetched
+	FetchTiming *TimingBreakdown `json:"fetch_timing,omitempty"`
+}
+
+// TimingBreakdown splits the time taken by the requests for a page or a link into phases, in milliseconds. The
+// phases of every request sent, such as those of redirects, retries and the ranged GET sent when a link refuses
+// HEAD, are added up. Phases that did not happen, such as the DNS lookup and connect of a reused connection, are
+// zero. The download of a page ends once its body has been read, before it is parsed.
+type TimingBreakdown struct {
+	DNSLookupMs       float64 `json:"dns_lookup_ms" example:"12.5"`
+	TCPConnectMs      float64 `json:"tcp_connect_ms" example:"20.1"`
+	TLSHandshakeMs    float64 `json:"tls_handshake_ms" example:"35.7"`
+	TimeToFirstByteMs float64 `json:"time_to_first_byte_ms" example:"110.2"`
+	ContentDownloadMs float64 `json:"content_download_ms" example:"48.3"`
+	// TotalMs also includes the time between the phases, such as waits for the per-host limits and retries
+	TotalMs          float64 `json:"total_ms" example:"230.4"`
+	ConnectionReused bool    `json:"connection_reused" example:"false"`
+}
+
+// Link error classes reported in LinkDetail.ErrorClass
+const (
+	LinkErrorDNS        = "dns"
+	LinkErrorTimeout    = "timeout"
+	LinkErrorTLS        = "tls"
+	LinkErrorHTTP       = "http"
+	LinkErrorConnection = "connection"
+	LinkErrorInvalidURL = "invalid_url"
+	LinkErrorOther      = "other"
+
+	LinkErrorRedirectLoop     = "redirect_loop"
+	LinkErrorTooManyRedirects = "too_many_redirects"
+
+	// LinkErrorBlocked is a link to a private, loopback, link-local or reserved address outside the SSRF allowlist
+	LinkErrorBlocked = "blocked"
+
+	// LinkErrorMissingFile is an internal link of a static site build with no file behind it
+	LinkErrorMissingFile = "missing_file"
+)
+
+// LinkDetail describes the outcome of checking a single link found on the page
+type LinkDetail struct {
+	URL            string        `json:"url" example:"https://example.com/about"`
+	AnchorText     string        `json:"anchor_text" example:"About us"`
+	IsExternal     bool          `json:"is_external" example:"true"`
+	IsAccessible   bool          `json:"is_accessible" example:"false"`
+	StatusCode     int           `json:"status_code,omitempty" example:"404"`
+	ErrorClass     string        `json:"error_class,omitempty" example:"http"`
+	Error          string        `json:"error,omitempty" example:"HTTP 404 Not Found"`
+	Method         string        `json:"method,omitempty" example:"HEAD"`
+	FinalURL       string        `json:"final_url,omitempty" example:"https://example.com/about/"`
+	RedirectChain  []RedirectHop `json:"redirect_chain,omitempty"`
+	ResponseTimeMs int64         `json:"response_time_ms" example:"120"`
+	Occurrences    int           `json:"occurrences" example:"1"`
+	Cached         bool          `json:"cached" example:"false"`
+	CheckedAt      time.Time     `json:"checked_at" example:"2023-01-01T12:00:00Z"`
+
+	// Timing breaks down the check, it is absent for links answered from the cache
+	Timing *TimingBreakdown `json:"timing,omitempty"`
+}
+
+// Reasons reported in SkippedLink.Reason
+const (
+	SkipReasonCancelled   = "cancelled"
+	SkipReasonLinkLimit   = "link_limit"
+	SkipReasonRateLimited = "rate_limited"
+	// SkipReasonOffline is a link of a static site build that cannot be checked without the network
+	SkipReasonOffline = "offline"
+	// SkipReasonDomainPolicy is a link to a host outside the domain allowlist, on the denylist, or whose domain
+	// policy turns link checks off
+	SkipReasonDomainPolicy = "domain_policy"
+)
+
+// SkippedLink is a discovered link that was deliberately not checked
+type SkippedLink struct {
+	URL        string `json:"url" example:"https://example.com/about"`
+	AnchorText string `json:"anchor_text" example:"About us"`
+	IsExternal bool   `json:"is_external" example:"true"`
+	Reason     string `json:"reason" example:"link_limit"`
+}
+
+// RedirectHop is a single redirect response followed while checking a link
+type RedirectHop struct {
+	URL        string `json:"url" example:"https://example.com/about"`
+	StatusCode int    `json:"status_code" example:"301"`
+	Location   string `json:"location" example:"https://example.com/about/"`
 }
 
 type ImageInfo struct {
@@ -79,18 +171,21 @@ type AccessibilityInfo struct {
 // NewAnalysisResult creates a new AnalysisResult with default values
 func NewAnalysisResult(url string) *AnalysisResult {
 	return &AnalysisResult{
-		URL:            url,
-		Headings:       make(map[string]int),
-		Timestamp:      time.Now(),
-		HTMLVersion:    "Unknown",
-		HTTPStatusCode: 200, // Default to OK
-		Images:         make([]ImageInfo, 0),
-		MetaTags:       make([]MetaTag, 0),
-		Scripts:        make([]ScriptInfo, 0),
-		Stylesheets:    make([]StylesheetInfo, 0),
-		Forms:          make([]FormInfo, 0),
-		TextContent:    TextContentInfo{},
-		Accessibility:  AccessibilityInfo{},
+		URL:               url,
+		Headings:          make(map[string]int),
+		Timestamp:         time.Now(),
+		HTMLVersion:       "Unknown",
+		HTTPStatusCode:    200, // Default to OK
+		Links:             make([]LinkDetail, 0),
+		SkippedLinks:      make([]SkippedLink, 0),
+		LinkCheckComplete: true,
+		Images:            make([]ImageInfo, 0),
+		MetaTags:          make([]MetaTag, 0),
+		Scripts:           make([]ScriptInfo, 0),
+		Stylesheets:       make([]StylesheetInfo, 0),
+		Forms:             make([]FormInfo, 0),
+		TextContent:       TextContentInfo{},
+		Accessibility:     AccessibilityInfo{},
 	}
 }
 
@@ -100,6 +195,41 @@ func (ar *AnalysisResult) SetError(message string, statusCode int) {
 	ar.HTTPStatusCode = statusCode
 }
 
+// AddLink records the outcome of a link check and counts it as inaccessible when it failed. A link whose href
+// could not be parsed is neither internal nor external, so it only adds to InaccessibleLinks.
+func (ar *AnalysisResult) AddLink(link LinkDetail) {
+	ar.Links = append(ar.Links, link)
+	if link.IsAccessible {
+		return
+	}
+	ar.InaccessibleLinks++
+	if link.ErrorClass == LinkErrorInvalidURL {
+		return
+	}
+	if link.IsExternal {
+		ar.InaccessibleExternalLinks++
+	} else {
+		ar.InaccessibleInternalLinks++
+	}
+}
+
+// AddSkippedLink records a link that was not checked, which makes the link check incomplete
+func (ar *AnalysisResult) AddSkippedLink(link SkippedLink) {
+	ar.SkippedLinks = append(ar.SkippedLinks, link)
+	ar.LinkCheckComplete = false
+}
+
+// BrokenLinks returns the checked links that were not accessible
+func (ar *AnalysisResult) BrokenLinks() []LinkDetail {
+	broken := make([]LinkDetail, 0)
+	for _, link := range ar.Links {
+		if !link.IsAccessible {
+			broken = append(broken, link)
+		}
+	}
+	return broken
+}
+
 // AddHeading increments the count for a specific heading type
 func (ar *AnalysisResult) AddHeading(level string) {
 	ar.Headings[level]++
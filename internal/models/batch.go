@@ -0,0 +1,55 @@
+package models
+
+// Line types of the NDJSON batch response
+const (
+	BatchLineResult  = "result"
+	BatchLineSummary = "summary"
+)
+
+// BatchItem is the result of analyzing one URL of a batch. Items are streamed in completion order, the
+// index is the position of the URL in the request.
+type BatchItem struct {
+	Type   string          `json:"type" example:"result"`
+	Index  int             `json:"index" example:"0"`
+	URL    string          `json:"url" example:"https://example.com"`
+	Result *AnalysisResult `json:"result"`
+}
+
+// BatchSummary aggregates the results of a batch, it is the last line of the batch response
+type BatchSummary struct {
+	Type                   string `json:"type" example:"summary"`
+	TotalURLs              int    `json:"total_urls" example:"100"`
+	Analyzed               int    `json:"analyzed" example:"100"`
+	Succeeded              int    `json:"succeeded" example:"97"`
+	Failed                 int    `json:"failed" example:"3"`
+	NotAnalyzed            int    `json:"not_analyzed" example:"0"`
+	TotalInternalLinks     int    `json:"total_internal_links" example:"1200"`
+	TotalExternalLinks     int    `json:"total_external_links" example:"300"`
+	TotalInaccessibleLinks int    `json:"total_inaccessible_links" example:"8"`
+	PagesWithLoginForm     int    `json:"pages_with_login_form" example:"4"`
+	BatchTime              string `json:"batch_time" example:"42.1s"`
+}
+
+// NewBatchSummary creates an empty summary for a batch of the given size
+func NewBatchSummary(totalURLs int) *BatchSummary {
+	return &BatchSummary{
+		Type:      BatchLineSummary,
+		TotalURLs: totalURLs,
+	}
+}
+
+// Add counts the result of one URL in the summary
+func (bs *BatchSummary) Add(result *AnalysisResult) {
+	bs.Analyzed++
+	if !result.IsSuccessful() {
+		bs.Failed++
+	} else {
+		bs.Succeeded++
+	}
+	bs.TotalInternalLinks += result.InternalLinks
+	bs.TotalExternalLinks += result.ExternalLinks
+	bs.TotalInaccessibleLinks += result.InaccessibleLinks
+	if result.HasLoginForm {
+		bs.PagesWithLoginForm++
+	}
+}
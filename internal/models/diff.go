@@ -0,0 +1,147 @@
+package models
+
+import "time"
+
+// CompareSource is one side of a comparison: a stored or posted analysis result, or a page to analyze on the spot.
+// Exactly one of Result, ID, HTML or URL is used, in that order. HTML is analyzed with URL as its base URL.
+type CompareSource struct {
+	Result *AnalysisResult `json:"result,omitempty"`
+	ID     string          `json:"id,omitempty" example:"0000018c2a5f3e1a9b1c2d3e"`
+	URL    string          `json:"url,omitempty" example:"https://example.com"`
+	HTML   string          `json:"html,omitempty" example:"<html><head><title>Example</title></head></html>"`
+}
+
+// CompareRequest asks for the differences between a base and a target analysis
+type CompareRequest struct {
+	Base   CompareSource `json:"base"`
+	Target CompareSource `json:"target"`
+}
+
+// AnalysisDiff describes what changed between a base and a target analysis, section by section
+type AnalysisDiff struct {
+	Base        DiffSide          `json:"base"`
+	Target      DiffSide          `json:"target"`
+	Summary     DiffSummary       `json:"summary"`
+	Fields      []FieldChange     `json:"fields"`
+	Headings    []HeadingChange   `json:"headings"`
+	Links       LinkChanges       `json:"links"`
+	Images      ImageChanges      `json:"images"`
+	MetaTags    MetaTagChanges    `json:"meta_tags"`
+	Scripts     ScriptChanges     `json:"scripts"`
+	Stylesheets StylesheetChanges `json:"stylesheets"`
+	Forms       FormChanges       `json:"forms"`
+}
+
+// DiffSide identifies one of the compared analyses
+type DiffSide struct {
+	ID        string    `json:"id,omitempty" example:"0000018c2a5f3e1a9b1c2d3e"`
+	URL       string    `json:"url" example:"https://example.com"`
+	Timestamp time.Time `json:"timestamp" example:"2023-01-01T12:00:00Z"`
+}
+
+// DiffSummary counts the changes most teams look at first
+type DiffSummary struct {
+	HasChanges           bool `json:"has_changes" example:"true"`
+	Changes              int  `json:"changes" example:"4"`
+	NewlyBrokenLinks     int  `json:"newly_broken_links" example:"1"`
+	FixedLinks           int  `json:"fixed_links" example:"0"`
+	AddedExternalScripts int  `json:"added_external_scripts" example:"1"`
+	RemovedMetaTags      int  `json:"removed_meta_tags" example:"1"`
+	ChangedHeadings      int  `json:"changed_headings" example:"1"`
+	ChangedAccessibility int  `json:"changed_accessibility" example:"0"`
+	AddedLinks           int  `json:"added_links" example:"2"`
+	RemovedLinks         int  `json:"removed_links" example:"0"`
+}
+
+// FieldChange is a changed single value of the result, such as the page title, a count or an accessibility flag.
+// Field is the JSON path of the value, e.g. "page_title" or "accessibility.has_alt_text".
+type FieldChange struct {
+	Field  string `json:"field" example:"page_title"`
+	Before any    `json:"before"`
+	After  any    `json:"after"`
+}
+
+// HeadingChange is a changed number of headings of one level
+type HeadingChange struct {
+	Level  string `json:"level" example:"h2"`
+	Before int    `json:"before" example:"3"`
+	After  int    `json:"after" example:"4"`
+}
+
+// LinkChanges lists the links that appeared, disappeared or changed status. Links are matched by URL.
+// NewlyBroken holds the target's inaccessible links that were not inaccessible in the base, including added ones,
+// and Fixed the links that were inaccessible in the base and are accessible in the target.
+type LinkChanges struct {
+	Added       []LinkDetail `json:"added"`
+	Removed     []LinkDetail `json:"removed"`
+	Changed     []LinkChange `json:"changed"`
+	NewlyBroken []LinkDetail `json:"newly_broken"`
+	Fixed       []LinkDetail `json:"fixed"`
+}
+
+// LinkChange is a link found in both analyses whose check outcome differs
+type LinkChange struct {
+	URL    string     `json:"url" example:"https://example.com/about"`
+	Before LinkDetail `json:"before"`
+	After  LinkDetail `json:"after"`
+}
+
+// ImageChanges lists the images that appeared, disappeared or changed, matched by source
+type ImageChanges struct {
+	Added   []ImageInfo   `json:"added"`
+	Removed []ImageInfo   `json:"removed"`
+	Changed []ImageChange `json:"changed"`
+}
+
+type ImageChange struct {
+	Before ImageInfo `json:"before"`
+	After  ImageInfo `json:"after"`
+}
+
+// MetaTagChanges lists the meta tags that appeared, disappeared or changed content, matched by name and property
+type MetaTagChanges struct {
+	Added   []MetaTag       `json:"added"`
+	Removed []MetaTag       `json:"removed"`
+	Changed []MetaTagChange `json:"changed"`
+}
+
+type MetaTagChange struct {
+	Before MetaTag `json:"before"`
+	After  MetaTag `json:"after"`
+}
+
+// ScriptChanges lists the scripts that appeared, disappeared or changed, matched by source
+type ScriptChanges struct {
+	Added   []ScriptInfo   `json:"added"`
+	Removed []ScriptInfo   `json:"removed"`
+	Changed []ScriptChange `json:"changed"`
+}
+
+type ScriptChange struct {
+	Before ScriptInfo `json:"before"`
+	After  ScriptInfo `json:"after"`
+}
+
+// StylesheetChanges lists the stylesheets that appeared, disappeared or changed, matched by href
+type StylesheetChanges struct {
+	Added   []StylesheetInfo   `json:"added"`
+	Removed []StylesheetInfo   `json:"removed"`
+	Changed []StylesheetChange `json:"changed"`
+}
+
+type StylesheetChange struct {
+	Before StylesheetInfo `json:"before"`
+	After  StylesheetInfo `json:"after"`
+}
+
+// FormChanges lists the forms that appeared, disappeared or changed, matched by action and method
+type FormChanges struct {
+	Added   []FormInfo   `json:"added"`
+	Removed []FormInfo   `json:"removed"`
+	Changed []FormChange `json:"changed"`
+}
+
+type FormChange struct {
+	Before FormInfo `json:"before"`
+	After  FormInfo `json:"after"`
+}
@@ -0,0 +1,64 @@
+package models
+
+import "time"
+
+// HistoryEntry is the summary of a stored analysis shown when listing the history
+type HistoryEntry struct {
+	ID                string    `json:"id" example:"0000018c2a5f3e1a9b1c2d3e"`
+	URL               string    `json:"url" example:"https://example.com"`
+	PageTitle         string    `json:"page_title" example:"Example Page"`
+	HTTPStatusCode    int       `json:"http_status_code,omitempty" example:"200"`
+	Error             string    `json:"error,omitempty" example:"Failed to fetch page"`
+	InternalLinks     int       `json:"internal_links" example:"5"`
+	ExternalLinks     int       `json:"external_links" example:"2"`
+	InaccessibleLinks int       `json:"inaccessible_links" example:"0"`
+	HasLoginForm      bool      `json:"has_login_form" example:"true"`
+	Timestamp         time.Time `json:"timestamp" example:"2023-01-01T12:00:00Z"`
+}
+
+// HistoryFilter selects stored analyses. Zero values do not filter.
+type HistoryFilter struct {
+	URL    string
+	From   time.Time
+	To     time.Time
+	Limit  int
+	Offset int
+}
+
+// HistoryPage is one page of stored analyses, newest first
+type HistoryPage struct {
+	Entries []HistoryEntry `json:"entries"`
+	Total   int            `json:"total" example:"42"`
+	Limit   int            `json:"limit" example:"50"`
+	Offset  int            `json:"offset" example:"0"`
+}
+
+// NewHistoryEntry summarizes an analysis result for the history list
+func NewHistoryEntry(result *AnalysisResult) HistoryEntry {
+	return HistoryEntry{
+		ID:                result.ID,
+		URL:               result.URL,
+		PageTitle:         result.PageTitle,
+		HTTPStatusCode:    result.HTTPStatusCode,
+		Error:             result.Error,
+		InternalLinks:     result.InternalLinks,
+		ExternalLinks:     result.ExternalLinks,
+		InaccessibleLinks: result.InaccessibleLinks,
+		HasLoginForm:      result.HasLoginForm,
+		Timestamp:         result.Timestamp,
+	}
+}
+
+// Matches reports whether the entry is selected by the URL and date range of the filter
+func (f HistoryFilter) Matches(entry HistoryEntry) bool {
+	if f.URL != "" && entry.URL != f.URL {
+		return false
+	}
+	if !f.From.IsZero() && entry.Timestamp.Before(f.From) {
+		return false
+	}
+	if !f.To.IsZero() && entry.Timestamp.After(f.To) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,76 @@
+package models
+
+import "time"
+
+// Job statuses reported in Job.Status
+const (
+	JobStatusQueued    = "queued"
+	JobStatusRunning   = "running"
+	JobStatusCompleted = "completed"
+	JobStatusFailed    = "failed"
+	JobStatusCancelled = "cancelled"
+)
+
+// Analysis stages reported in AnalysisProgress.Stage
+const (
+	StageFetching      = "fetching"
+	StageParsing       = "parsing"
+	StageCheckingLinks = "checking_links"
+	StageCompleted     = "completed"
+)
+
+// Analysis event types reported in AnalysisEvent.Type
+const (
+	EventStage       = "stage"
+	EventProgress    = "progress"
+	EventLink        = "link"
+	EventSkippedLink = "skipped_link"
+	EventDone        = "done"
+)
+
+// JobRequest is the body accepted when creating an analysis job
+type JobRequest struct {
+	URL string `json:"url" example:"https://example.com"`
+	// CallbackURL receives the signed AnalysisResult once the analysis has finished
+	CallbackURL string `json:"callback_url,omitempty" example:"https://ci.example.com/hooks/analysis"`
+}
+
+// Job is an analysis running in the background. The result is set once the job has finished.
+type Job struct {
+	ID          string           `json:"id" example:"9f86d081884c7d65"`
+	URL         string           `json:"url" example:"https://example.com"`
+	Status      string           `json:"status" example:"running"`
+	Progress    AnalysisProgress `json:"progress"`
+	Result      *AnalysisResult  `json:"result,omitempty"`
+	Error       string           `json:"error,omitempty" example:"HTTP Error: 404 - Not Found"`
+	CreatedAt   time.Time        `json:"created_at" example:"2023-01-01T12:00:00Z"`
+	StartedAt   *time.Time       `json:"started_at,omitempty" example:"2023-01-01T12:00:01Z"`
+	FinishedAt  *time.Time       `json:"finished_at,omitempty" example:"2023-01-01T12:00:05Z"`
+	CallbackURL string           `json:"callback_url,omitempty" example:"https://ci.example.com/hooks/analysis"`
+	// CallbackDeliveryID is the webhook delivery of the result to CallbackURL, set once the job has finished
+	CallbackDeliveryID string `json:"callback_delivery_id,omitempty" example:"3c9a7f1e2b4d6a80"`
+}
+
+// AnalysisProgress describes how far an analysis has come. Links found keeps growing while the page is
+// walked, every one of them ends up either checked or skipped.
+type AnalysisProgress struct {
+	Stage        string `json:"stage" example:"checking_links"`
+	LinksFound   int    `json:"links_found" example:"40"`
+	LinksChecked int    `json:"links_checked" example:"25"`
+	LinksSkipped int    `json:"links_skipped" example:"0"`
+}
+
+// AnalysisEvent is a step of an analysis as it happens. Link events carry the outcome of a single link
+// check and the done event carries the finished job.
+type AnalysisEvent struct {
+	Type        string           `json:"type" example:"link"`
+	Progress    AnalysisProgress `json:"progress"`
+	Link        *LinkDetail      `json:"link,omitempty"`
+	SkippedLink *SkippedLink     `json:"skipped_link,omitempty"`
+	Job         *Job             `json:"job,omitempty"`
+}
+
+// IsFinished reports whether the job has reached a final status
+func (j *Job) IsFinished() bool {
+	return j.Status == JobStatusCompleted || j.Status == JobStatusFailed || j.Status == JobStatusCancelled
+}
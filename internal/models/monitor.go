@@ -0,0 +1,73 @@
+package models
+
+import "time"
+
+// Rule types evaluated against each analysis of a monitored URL
+const (
+	// RuleNewBrokenLinks alerts when links are inaccessible that were not in the previous run of the monitor
+	RuleNewBrokenLinks = "new_broken_links"
+	// RuleMaxBrokenLinks alerts when more than Threshold links are inaccessible
+	RuleMaxBrokenLinks = "max_broken_links"
+	// RuleTitleMissing alerts when the page has no title
+	RuleTitleMissing = "title_missing"
+	// RuleLoginFormPresent alerts when the page has a login form
+	RuleLoginFormPresent = "login_form_present"
+	// RuleAnalysisFailed alerts when the page could not be fetched or analyzed
+	RuleAnalysisFailed = "analysis_failed"
+)
+
+// MonitorRule is a condition that raises an alert when a new analysis of the monitored URL matches it
+type MonitorRule struct {
+	Type      string `json:"type" example:"max_broken_links"`
+	Threshold int    `json:"threshold,omitempty" example:"5"`
+}
+
+// MonitorRequest creates or replaces a monitor
+type MonitorRequest struct {
+	URL string `json:"url" example:"https://example.com"`
+	// Schedule is a five field cron expression or a descriptor such as "@hourly" or "@every 30m"
+	Schedule   string        `json:"schedule" example:"*/15 * * * *"`
+	Rules      []MonitorRule `json:"rules"`
+	WebhookURL string        `json:"webhook_url,omitempty" example:"https://hooks.example.com/alerts"`
+	// Enabled defaults to true
+	Enabled *bool `json:"enabled,omitempty" example:"true"`
+}
+
+// Monitor re-analyzes a URL on a schedule and alerts when a rule matches the new result. BaselineResultID is its
+// last successful analysis, which the next run is compared with, and LastError the error of its last run when the
+// page could not be analyzed.
+type Monitor struct {
+	ID               string        `json:"id" example:"5f2b8c1d9e3a4b6c"`
+	URL              string        `json:"url" example:"https://example.com"`
+	Schedule         string        `json:"schedule" example:"*/15 * * * *"`
+	Rules            []MonitorRule `json:"rules"`
+	WebhookURL       string        `json:"webhook_url,omitempty" example:"https://hooks.example.com/alerts"`
+	Enabled          bool          `json:"enabled" example:"true"`
+	CreatedAt        time.Time     `json:"created_at" example:"2023-01-01T12:00:00Z"`
+	UpdatedAt        time.Time     `json:"updated_at" example:"2023-01-01T12:00:00Z"`
+	NextRunAt        *time.Time    `json:"next_run_at,omitempty" example:"2023-01-01T12:15:00Z"`
+	LastRunAt        *time.Time    `json:"last_run_at,omitempty" example:"2023-01-01T12:00:00Z"`
+	LastResultID     string        `json:"last_result_id,omitempty" example:"0000018c2a5f3e1a9b1c2d3e"`
+	BaselineResultID string        `json:"baseline_result_id,omitempty" example:"0000018c2a5f3e1a9b1c2d3e"`
+	LastError        string        `json:"last_error,omitempty" example:"HTTP Error: 500 - Internal Server Error"`
+	LastAlerts       []Alert       `json:"last_alerts"`
+}
+
+// Alert is a rule of a monitor that matched an analysis
+type Alert struct {
+	MonitorID   string    `json:"monitor_id" example:"5f2b8c1d9e3a4b6c"`
+	URL         string    `json:"url" example:"https://example.com"`
+	Rule        string    `json:"rule" example:"new_broken_links"`
+	Message     string    `json:"message" example:"2 links became inaccessible: https://example.com/about, https://example.com/team"`
+	ResultID    string    `json:"result_id,omitempty" example:"0000018c2a5f3e1a9b1c2d3e"`
+	TriggeredAt time.Time `json:"triggered_at" example:"2023-01-01T12:00:00Z"`
+}
+
+// AlertNotification is the body posted to the webhook of a monitor when its rules raise alerts
+type AlertNotification struct {
+	Event     string       `json:"event" example:"monitor.alert"`
+	MonitorID string       `json:"monitor_id" example:"5f2b8c1d9e3a4b6c"`
+	URL       string       `json:"url" example:"https://example.com"`
+	Alerts    []Alert      `json:"alerts"`
+	Result    HistoryEntry `json:"result"`
+}
@@ -0,0 +1,84 @@
+package models
+
+import "time"
+
+// CrawlOptions controls how far a site crawl is allowed to go
+type CrawlOptions struct {
+	MaxDepth int `json:"max_depth" example:"2"`
+	MaxPages int `json:"max_pages" example:"50"`
+}
+
+// SiteReport represents the result of analyzing several pages of the same site
+type SiteReport struct {
+	SeedURL                string     `json:"seed_url" example:"https://example.com"`
+	MaxDepth               int        `json:"max_depth" example:"2"`
+	MaxPages               int        `json:"max_pages" example:"50"`
+	PagesAnalyzed          int        `json:"pages_analyzed" example:"12"`
+	PagesFailed            int        `json:"pages_failed" example:"1"`
+	BudgetExhausted        bool       `json:"budget_exhausted" example:"false"`
+	TotalInternalLinks     int        `json:"total_internal_links" example:"120"`
+	TotalExternalLinks     int        `json:"total_external_links" example:"30"`
+	TotalInaccessibleLinks int        `json:"total_inaccessible_links" example:"2"`
+	PagesWithLoginForm     int        `json:"pages_with_login_form" example:"1"`
+	CrawlTime              string     `json:"crawl_time" example:"12.5s"`
+	Timestamp              time.Time  `json:"timestamp" example:"2023-01-01T12:00:00Z"`
+	Error                  string     `json:"error,omitempty" example:"Invalid URL"`
+	Pages                  []SitePage `json:"pages"`
+	// NonHTMLPages are crawled links that turned out not to be HTML documents, which do not count as pages
+	NonHTMLPages []NonHTMLPage `json:"non_html_pages,omitempty"`
+	// Directory and MissingFiles are only reported for static site builds analyzed from disk
+	Directory    string        `json:"directory,omitempty" example:"dist"`
+	MissingFiles []MissingFile `json:"missing_files,omitempty"`
+}
+
+// SitePage is a single page of a site report together with where it was found
+type SitePage struct {
+	URL     string          `json:"url" example:"https://example.com/about"`
+	Depth   int             `json:"depth" example:"1"`
+	FoundOn string          `json:"found_on,omitempty" example:"https://example.com"`
+	File    string          `json:"file,omitempty" example:"about/index.html"`
+	Result  *AnalysisResult `json:"result"`
+}
+
+// NonHTMLPage is an internal link followed by a crawl that served something other than an HTML document
+type NonHTMLPage struct {
+	URL         string `json:"url" example:"https://example.com/brochure"`
+	FoundOn     string `json:"found_on,omitempty" example:"https://example.com"`
+	ContentType string `json:"content_type" example:"application/pdf"`
+}
+
+// MissingFile is an internal link of a static site build that no file in the build directory serves
+type MissingFile struct {
+	URL        string   `json:"url" example:"https://example.com/team/"`
+	LinkedFrom []string `json:"linked_from"`
+}
+
+// NewSiteReport creates a new SiteReport with default values
+func NewSiteReport(seedURL string, opts CrawlOptions) *SiteReport {
+	return &SiteReport{
+		SeedURL:   seedURL,
+		MaxDepth:  opts.MaxDepth,
+		MaxPages:  opts.MaxPages,
+		Timestamp: time.Now(),
+		Pages:     make([]SitePage, 0),
+	}
+}
+
+// AddPage appends a page to the report and updates the aggregated counters
+func (sr *SiteReport) AddPage(page SitePage) {
+	sr.Pages = append(sr.Pages, page)
+	sr.PagesAnalyzed++
+
+	if page.Result == nil {
+		return
+	}
+	if !page.Result.IsSuccessful() {
+		sr.PagesFailed++
+	}
+	sr.TotalInternalLinks += page.Result.InternalLinks
+	sr.TotalExternalLinks += page.Result.ExternalLinks
+	sr.TotalInaccessibleLinks += page.Result.InaccessibleLinks
+	if page.Result.HasLoginForm {
+		sr.PagesWithLoginForm++
+	}
+}
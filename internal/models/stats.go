@@ -0,0 +1,11 @@
+package models
+
+// LinkCheckPoolStats is a snapshot of the shared link check worker pool
+type LinkCheckPoolStats struct {
+	Workers         int    `json:"workers" example:"5"`
+	BusyWorkers     int    `json:"busy_workers" example:"3"`
+	ActiveAnalyses  int    `json:"active_analyses" example:"2"`
+	QueuedLinks     int    `json:"queued_links" example:"40"`
+	CompletedChecks uint64 `json:"completed_checks" example:"1250"`
+	CancelledChecks uint64 `json:"cancelled_checks" example:"12"`
+}
@@ -0,0 +1,51 @@
+package models
+
+import "time"
+
+// Webhook events reported in WebhookDelivery.Event and the X-Webhook-Event header
+const (
+	WebhookEventAnalysisCompleted = "analysis.completed"
+	WebhookEventMonitorAlert      = "monitor.alert"
+)
+
+// Webhook delivery statuses reported in WebhookDelivery.Status
+const (
+	DeliveryStatusPending   = "pending"
+	DeliveryStatusDelivered = "delivered"
+	DeliveryStatusFailed    = "failed"
+)
+
+// WebhookDelivery is a notification posted to a webhook, with every attempt made to deliver it
+type WebhookDelivery struct {
+	ID    string `json:"id" example:"3c9a7f1e2b4d6a80"`
+	Event string `json:"event" example:"analysis.completed"`
+	// ResourceID is the job or monitor the notification is about
+	ResourceID    string           `json:"resource_id" example:"9f86d081884c7d65"`
+	URL           string           `json:"url" example:"https://ci.example.com/hooks/analysis"`
+	Status        string           `json:"status" example:"delivered"`
+	Attempts      []WebhookAttempt `json:"attempts"`
+	NextAttemptAt *time.Time       `json:"next_attempt_at,omitempty" example:"2023-01-01T12:00:02Z"`
+	CreatedAt     time.Time        `json:"created_at" example:"2023-01-01T12:00:00Z"`
+	CompletedAt   *time.Time       `json:"completed_at,omitempty" example:"2023-01-01T12:00:01Z"`
+}
+
+// WebhookAttempt is a single POST of a webhook delivery
+type WebhookAttempt struct {
+	StatusCode int       `json:"status_code,omitempty" example:"503"`
+	Error      string    `json:"error,omitempty" example:"webhook responded with HTTP 503"`
+	DurationMs int64     `json:"duration_ms" example:"85"`
+	At         time.Time `json:"at" example:"2023-01-01T12:00:00Z"`
+}
+
+// DeliveryFilter selects webhook deliveries. Zero values do not filter.
+type DeliveryFilter struct {
+	Status     string
+	ResourceID string
+	Limit      int
+}
+
+// Matches reports whether the delivery is selected by the filter
+func (f DeliveryFilter) Matches(delivery WebhookDelivery) bool {
+	return (f.Status == "" || delivery.Status == f.Status) &&
+		(f.ResourceID == "" || delivery.ResourceID == f.ResourceID)
+}
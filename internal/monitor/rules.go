@@ -0,0 +1,117 @@
+package monitor
+
+import (
+	"WebAppAnalyzer/internal/diff"
+	"WebAppAnalyzer/internal/models"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// maxAlertLinks is the number of link URLs listed in an alert message before the rest are summarized
+const maxAlertLinks = 5
+
+// defaultRules are used when a monitor is created without rules
+var defaultRules = []models.MonitorRule{
+	{Type: models.RuleNewBrokenLinks},
+	{Type: models.RuleAnalysisFailed},
+}
+
+func validateRule(rule models.MonitorRule) error {
+	switch rule.Type {
+	case models.RuleNewBrokenLinks, models.RuleTitleMissing, models.RuleLoginFormPresent, models.RuleAnalysisFailed:
+		return nil
+	case models.RuleMaxBrokenLinks:
+		if rule.Threshold < 0 {
+			return fmt.Errorf("the threshold of %s must not be negative", rule.Type)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown rule type %q", rule.Type)
+	}
+}
+
+// Evaluate returns an alert for every rule the current result matches. previous is the last successful result of
+// the monitor, or nil before its first successful run, in which case new_broken_links has nothing to compare with
+// and does not alert. The rules on the state of the page alert when it starts failing them, not again on every run
+// while it keeps failing; without a previous result they alert whenever the page fails them. analysis_failed
+// alerts when the analysis starts failing, going by the LastError of the monitor's last run.
+func Evaluate(monitor models.Monitor, previous, current *models.AnalysisResult) []models.Alert {
+	alerts := make([]models.Alert, 0)
+	alert := func(rule, message string) {
+		alerts = append(alerts, models.Alert{
+			MonitorID:   monitor.ID,
+			URL:         monitor.URL,
+			Rule:        rule,
+			Message:     message,
+			ResultID:    current.ID,
+			TriggeredAt: time.Now(),
+		})
+	}
+
+	for _, rule := range monitor.Rules {
+		if !current.IsSuccessful() {
+			// Only the failure itself can be judged when the page could not be analyzed
+			if rule.Type == models.RuleAnalysisFailed && monitor.LastError == "" {
+				alert(rule.Type, "The analysis failed: "+current.Error)
+			}
+			continue
+		}
+
+		switch rule.Type {
+		case models.RuleNewBrokenLinks:
+			if previous == nil || !previous.IsSuccessful() {
+				continue
+			}
+			if broken := diff.Compare(previous, current).Links.NewlyBroken; len(broken) > 0 {
+				alert(rule.Type, fmt.Sprintf("%d links became inaccessible: %s", len(broken), linkList(broken)))
+			}
+		case models.RuleMaxBrokenLinks:
+			if failing(rule, current) && !stillFailing(rule, previous) {
+				alert(rule.Type, fmt.Sprintf("%d links are inaccessible, more than the threshold of %d: %s",
+					current.InaccessibleLinks, rule.Threshold, linkList(current.BrokenLinks())))
+			}
+		case models.RuleTitleMissing:
+			if failing(rule, current) && !stillFailing(rule, previous) {
+				alert(rule.Type, "The page has no title")
+			}
+		case models.RuleLoginFormPresent:
+			if failing(rule, current) && !stillFailing(rule, previous) {
+				alert(rule.Type, "The page has a login form")
+			}
+		}
+	}
+
+	return alerts
+}
+
+// failing reports whether a successfully analyzed page fails a rule on the state of the page
+func failing(rule models.MonitorRule, result *models.AnalysisResult) bool {
+	switch rule.Type {
+	case models.RuleMaxBrokenLinks:
+		return result.InaccessibleLinks > rule.Threshold
+	case models.RuleTitleMissing:
+		return strings.TrimSpace(result.PageTitle) == ""
+	case models.RuleLoginFormPresent:
+		return result.HasLoginForm
+	default:
+		return false
+	}
+}
+
+// stillFailing reports whether the previous run already failed the rule, and so was alerted on
+func stillFailing(rule models.MonitorRule, previous *models.AnalysisResult) bool {
+	return previous != nil && previous.IsSuccessful() && failing(rule, previous)
+}
+
+func linkList(links []models.LinkDetail) string {
+	urls := make([]string, 0, min(len(links), maxAlertLinks))
+	for _, link := range links[:min(len(links), maxAlertLinks)] {
+		urls = append(urls, link.URL)
+	}
+	list := strings.Join(urls, ", ")
+	if len(links) > maxAlertLinks {
+		list += fmt.Sprintf(" and %d more", len(links)-maxAlertLinks)
+	}
+	return list
+}
@@ -0,0 +1,161 @@
+package monitor
+
+import (
+	"WebAppAnalyzer/internal/models"
+	"testing"
+)
+
+func createResult(title string, brokenLinks ...string) *models.AnalysisResult {
+	result := models.NewAnalysisResult("https://example.com")
+	result.PageTitle = title
+	result.AddLink(models.LinkDetail{URL: "https://example.com/ok", IsAccessible: true})
+	for _, link := range brokenLinks {
+		result.AddLink(models.LinkDetail{URL: link, IsAccessible: false, StatusCode: 404})
+	}
+	return result
+}
+
+// TestEvaluate tests which rules raise alerts for a new result
+func TestEvaluate(t *testing.T) {
+	loginPage := createResult("Login")
+	loginPage.HasLoginForm = true
+
+	failed := models.NewAnalysisResult("https://example.com")
+	failed.SetError("HTTP Error: 500 - Internal Server Error", 500)
+
+	allRules := []models.MonitorRule{
+		{Type: models.RuleNewBrokenLinks},
+		{Type: models.RuleMaxBrokenLinks, Threshold: 1},
+		{Type: models.RuleTitleMissing},
+		{Type: models.RuleLoginFormPresent},
+		{Type: models.RuleAnalysisFailed},
+	}
+
+	tests := []struct {
+		name          string
+		previous      *models.AnalysisResult
+		current       *models.AnalysisResult
+		expectedRules []string
+	}{
+		{
+			name:          "No regression",
+			previous:      createResult("Home", "https://example.com/a"),
+			current:       createResult("Home", "https://example.com/a"),
+			expectedRules: []string{},
+		},
+		{
+			name:          "First run has no baseline",
+			previous:      nil,
+			current:       createResult("Home", "https://example.com/a"),
+			expectedRules: []string{},
+		},
+		{
+			name:          "New broken links over the threshold",
+			previous:      createResult("Home", "https://example.com/a"),
+			current:       createResult("Home", "https://example.com/a", "https://example.com/b"),
+			expectedRules: []string{models.RuleNewBrokenLinks, models.RuleMaxBrokenLinks},
+		},
+		{
+			name:          "Title disappeared",
+			previous:      createResult("Home"),
+			current:       createResult("  "),
+			expectedRules: []string{models.RuleTitleMissing},
+		},
+		{
+			name:          "Login form appeared",
+			previous:      createResult("Home"),
+			current:       loginPage,
+			expectedRules: []string{models.RuleLoginFormPresent},
+		},
+		{
+			name:          "Still failing after the last run",
+			previous:      loginPage,
+			current:       loginPage,
+			expectedRules: []string{},
+		},
+		{
+			name:          "Failing since before the analysis failed",
+			previous:      failed,
+			current:       loginPage,
+			expectedRules: []string{models.RuleLoginFormPresent},
+		},
+		{
+			name:          "Failed analysis only raises the failure",
+			previous:      createResult("Home"),
+			current:       failed,
+			expectedRules: []string{models.RuleAnalysisFailed},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			monitor := models.Monitor{ID: "m1", URL: "https://example.com", Rules: allRules}
+			alerts := Evaluate(monitor, tt.previous, tt.current)
+
+			if len(alerts) != len(tt.expectedRules) {
+				t.Fatalf("Expected alerts for %v, got %+v", tt.expectedRules, alerts)
+			}
+			for i, alert := range alerts {
+				if alert.Rule != tt.expectedRules[i] {
+					t.Errorf("Expected alert %d for %s, got %s", i, tt.expectedRules[i], alert.Rule)
+				}
+				if alert.MonitorID != "m1" || alert.Message == "" {
+					t.Errorf("Expected the alert to name the monitor and explain itself, got %+v", alert)
+				}
+			}
+		})
+	}
+}
+
+// TestEvaluate_RepeatedFailures tests that a page failing the rules on consecutive runs alerts once
+func TestEvaluate_RepeatedFailures(t *testing.T) {
+	monitor := models.Monitor{ID: "m1", URL: "https://example.com", Rules: []models.MonitorRule{
+		{Type: models.RuleMaxBrokenLinks, Threshold: 0},
+		{Type: models.RuleTitleMissing},
+		{Type: models.RuleLoginFormPresent},
+	}}
+	failingPage := func() *models.AnalysisResult {
+		result := createResult("", "https://example.com/a")
+		result.HasLoginForm = true
+		return result
+	}
+
+	first := failingPage()
+	if alerts := Evaluate(monitor, createResult("Home"), first); len(alerts) != 3 {
+		t.Fatalf("Expected the first failing run to raise 3 alerts, got %+v", alerts)
+	}
+	if alerts := Evaluate(monitor, first, failingPage()); len(alerts) != 0 {
+		t.Errorf("Expected no alerts while the page keeps failing, got %+v", alerts)
+	}
+	if alerts := Evaluate(monitor, nil, failingPage()); len(alerts) != 3 {
+		t.Errorf("Expected every failure to alert without a previous result, got %+v", alerts)
+	}
+}
+
+// TestEvaluate_AnalysisStillFailing tests that a failed analysis alerts once until the page can be analyzed again
+func TestEvaluate_AnalysisStillFailing(t *testing.T) {
+	monitor := models.Monitor{ID: "m1", URL: "https://example.com", Rules: []models.MonitorRule{{Type: models.RuleAnalysisFailed}}}
+	failed := models.NewAnalysisResult("https://example.com")
+	failed.SetError("HTTP Error: 500 - Internal Server Error", 500)
+
+	if alerts := Evaluate(monitor, createResult("Home"), failed); len(alerts) != 1 {
+		t.Fatalf("Expected the first failure to alert, got %+v", alerts)
+	}
+	monitor.LastError = failed.Error
+	if alerts := Evaluate(monitor, createResult("Home"), failed); len(alerts) != 0 {
+		t.Errorf("Expected no alerts while the analysis keeps failing, got %+v", alerts)
+	}
+}
+
+// TestValidateRule tests that unknown rules and negative thresholds are rejected
+func TestValidateRule(t *testing.T) {
+	if err := validateRule(models.MonitorRule{Type: models.RuleMaxBrokenLinks, Threshold: 3}); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if err := validateRule(models.MonitorRule{Type: models.RuleMaxBrokenLinks, Threshold: -1}); err == nil {
+		t.Error("Expected a negative threshold to be rejected")
+	}
+	if err := validateRule(models.MonitorRule{Type: "page_is_slow"}); err == nil {
+		t.Error("Expected an unknown rule to be rejected")
+	}
+}
@@ -0,0 +1,400 @@
+package monitor
+
+import (
+	"WebAppAnalyzer/config/logger"
+	"WebAppAnalyzer/internal/models"
+	"WebAppAnalyzer/internal/store"
+	"WebAppAnalyzer/internal/validator"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/robfig/cron/v3"
+	"sort"
+	"sync"
+	"time"
+)
+
+// runTimeout bounds a single run so a page that never finishes loading cannot hold up the monitor
+const runTimeout = 5 * time.Minute
+
+var (
+	ErrMonitorNotFound = errors.New("monitor not found")
+	ErrInvalidMonitor  = errors.New("invalid monitor")
+)
+
+// Analyzer runs the analysis of a single page
+type Analyzer interface {
+	Analyze(ctx context.Context, url string) *models.AnalysisResult
+}
+
+// Notifier delivers a JSON payload to a webhook URL in the background
+type Notifier interface {
+	ValidateURL(url string) error
+	Deliver(event, resourceID, url string, payload any) models.WebhookDelivery
+}
+
+// Scheduler re-analyzes the URLs of the registered monitors on their cron schedules, evaluates the monitors'
+// rules against every new result and posts the alerts to the monitors' webhooks.
+type Scheduler struct {
+	analyzer  Analyzer
+	history   store.ResultStore
+	persist   store.MonitorStore
+	notifier  Notifier
+	logger    *logger.Logger
+	validator *validator.URLValidator
+	cron      *cron.Cron
+
+	mu       sync.Mutex
+	monitors map[string]*monitor
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// monitor is a registered monitor with its scheduling state. It is guarded by the scheduler lock.
+type monitor struct {
+	models.Monitor
+	entry cron.EntryID
+	// previous is the result of the last successful run, the baseline for rules that look for regressions
+	previous *models.AnalysisResult
+	running  bool
+}
+
+// NewScheduler creates a scheduler, restores the monitors kept in persist and starts scheduling them. Results
+// are saved to the history when a store is given, and monitors only live in memory when persist is nil. The URLs
+// of new monitors are checked with urlValidator, which should apply the same domain lists as the analyzer.
+func NewScheduler(analyzer Analyzer, history store.ResultStore, persist store.MonitorStore, notifier Notifier, urlValidator *validator.URLValidator, logger *logger.Logger) *Scheduler {
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &Scheduler{
+		analyzer:  analyzer,
+		history:   history,
+		persist:   persist,
+		notifier:  notifier,
+		logger:    logger,
+		validator: urlValidator,
+		cron:      cron.New(),
+		monitors:  make(map[string]*monitor),
+		ctx:       ctx,
+		cancel:    cancel,
+	}
+
+	if persist != nil {
+		stored, err := persist.ListMonitors()
+		if err != nil {
+			logger.Error("Failed to load monitors", err)
+		}
+		for _, saved := range stored {
+			m := &monitor{Monitor: saved}
+			if err := s.schedule(m); err != nil {
+				logger.Error("Failed to schedule monitor "+saved.ID, err)
+			}
+			s.monitors[m.ID] = m
+		}
+	}
+
+	s.cron.Start()
+	return s
+}
+
+// Create registers a new monitor and schedules it
+func (s *Scheduler) Create(req models.MonitorRequest) (models.Monitor, error) {
+	m := &monitor{}
+	if err := s.apply(m, req); err != nil {
+		return models.Monitor{}, err
+	}
+
+	id, err := newMonitorID()
+	if err != nil {
+		return models.Monitor{}, err
+	}
+	m.ID = id
+	m.CreatedAt = m.UpdatedAt
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.schedule(m); err != nil {
+		return models.Monitor{}, err
+	}
+	s.monitors[id] = m
+	s.save(m)
+
+	return s.snapshot(m), nil
+}
+
+// Update replaces the URL, schedule, rules and webhook of a monitor. Changing the URL starts a new baseline.
+func (s *Scheduler) Update(id string, req models.MonitorRequest) (models.Monitor, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	m, ok := s.monitors[id]
+	if !ok {
+		return models.Monitor{}, ErrMonitorNotFound
+	}
+
+	previous := m.Monitor
+	if err := s.apply(m, req); err != nil {
+		return models.Monitor{}, err
+	}
+	if m.URL != previous.URL {
+		m.previous = nil
+		m.LastResultID = ""
+		m.BaselineResultID = ""
+		m.LastError = ""
+	}
+
+	s.cron.Remove(m.entry)
+	if err := s.schedule(m); err != nil {
+		// The schedule was parsed by apply, so cron rejecting it is unexpected. Keep the monitor as it was.
+		m.Monitor = previous
+		s.schedule(m)
+		return models.Monitor{}, err
+	}
+	s.save(m)
+
+	return s.snapshot(m), nil
+}
+
+// Get returns a monitor by ID
+func (s *Scheduler) Get(id string) (models.Monitor, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	m, ok := s.monitors[id]
+	if !ok {
+		return models.Monitor{}, ErrMonitorNotFound
+	}
+	return s.snapshot(m), nil
+}
+
+// List returns all monitors, oldest first
+func (s *Scheduler) List() []models.Monitor {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	monitors := make([]models.Monitor, 0, len(s.monitors))
+	for _, m := range s.monitors {
+		monitors = append(monitors, s.snapshot(m))
+	}
+	sort.Slice(monitors, func(i, j int) bool {
+		return monitors[i].CreatedAt.Before(monitors[j].CreatedAt)
+	})
+	return monitors
+}
+
+// Delete stops and removes a monitor
+func (s *Scheduler) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	m, ok := s.monitors[id]
+	if !ok {
+		return ErrMonitorNotFound
+	}
+	s.cron.Remove(m.entry)
+	delete(s.monitors, id)
+
+	if s.persist != nil {
+		if err := s.persist.DeleteMonitor(id); err != nil {
+			s.logger.Error("Failed to delete monitor "+id, err)
+		}
+	}
+	return nil
+}
+
+// Run starts a run of the monitor now, outside its schedule. A run that is already in progress is not repeated.
+func (s *Scheduler) Run(id string) (models.Monitor, error) {
+	monitor, err := s.Get(id)
+	if err != nil {
+		return models.Monitor{}, err
+	}
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.run(id)
+	}()
+	return monitor, nil
+}
+
+// Close stops scheduling, cancels the runs in progress and waits for them to finish
+func (s *Scheduler) Close() {
+	s.cancel()
+	<-s.cron.Stop().Done()
+	s.wg.Wait()
+}
+
+// apply validates the request and copies it onto the monitor
+func (s *Scheduler) apply(m *monitor, req models.MonitorRequest) error {
+	validatedURL, err := s.validator.ValidateURL(req.URL)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidMonitor, err)
+	}
+	if _, err := cron.ParseStandard(req.Schedule); err != nil {
+		return fmt.Errorf("%w: invalid schedule %q: %v", ErrInvalidMonitor, req.Schedule, err)
+	}
+	if req.WebhookURL != "" {
+		if s.notifier == nil {
+			return fmt.Errorf("%w: webhooks are not available", ErrInvalidMonitor)
+		}
+		if err := s.notifier.ValidateURL(req.WebhookURL); err != nil {
+			return fmt.Errorf("%w: invalid webhook_url: %v", ErrInvalidMonitor, err)
+		}
+	}
+
+	rules := req.Rules
+	if len(rules) == 0 {
+		rules = defaultRules
+	}
+	for _, rule := range rules {
+		if err := validateRule(rule); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidMonitor, err)
+		}
+	}
+
+	m.URL = validatedURL
+	m.Schedule = req.Schedule
+	m.Rules = append([]models.MonitorRule(nil), rules...)
+	m.WebhookURL = req.WebhookURL
+	m.Enabled = req.Enabled == nil || *req.Enabled
+	m.UpdatedAt = time.Now()
+	return nil
+}
+
+// schedule adds an enabled monitor to the cron schedule
+func (s *Scheduler) schedule(m *monitor) error {
+	m.entry = 0
+	if !m.Enabled {
+		return nil
+	}
+
+	id := m.ID
+	entry, err := s.cron.AddFunc(m.Schedule, func() { s.run(id) })
+	if err != nil {
+		return fmt.Errorf("%w: invalid schedule %q: %v", ErrInvalidMonitor, m.Schedule, err)
+	}
+	m.entry = entry
+	return nil
+}
+
+// run analyzes the monitored URL, evaluates the rules against the result and delivers the alerts
+func (s *Scheduler) run(id string) {
+	s.mu.Lock()
+	m, ok := s.monitors[id]
+	if !ok || m.running || s.ctx.Err() != nil {
+		s.mu.Unlock()
+		return
+	}
+	m.running = true
+	current := m.Monitor
+	previous := m.previous
+	s.mu.Unlock()
+
+	if previous == nil && current.BaselineResultID != "" && s.history != nil {
+		// The baseline of a monitor restored after a restart is the last successful result it saved
+		if stored, err := s.history.Get(current.BaselineResultID); err == nil {
+			previous = stored
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(s.ctx, runTimeout)
+	startTime := time.Now()
+	result := s.analyzer.Analyze(ctx, current.URL)
+	result.AnalysisTime = time.Since(startTime).String()
+	cancel()
+
+	if s.ctx.Err() != nil {
+		// Shutting down, the result of an interrupted analysis is not a regression
+		s.mu.Lock()
+		m.running = false
+		s.mu.Unlock()
+		return
+	}
+
+	if s.history != nil {
+		if err := s.history.Save(result); err != nil {
+			s.logger.Error("Failed to save monitor result to history", err)
+		}
+	}
+	alerts := Evaluate(current, previous, result)
+
+	s.mu.Lock()
+	m.running = false
+	_, exists := s.monitors[id]
+	if exists {
+		now := time.Now()
+		m.LastRunAt = &now
+		m.LastAlerts = alerts
+		// The monitor may have been pointed at another URL during the run. A failed run keeps the baseline, so the
+		// next successful run is compared with the page as it was before the failure.
+		if m.URL == current.URL {
+			m.LastResultID = result.ID
+			m.LastError = result.Error
+			if result.IsSuccessful() {
+				m.previous = result
+				m.BaselineResultID = result.ID
+			}
+		}
+		s.save(m)
+	}
+	s.mu.Unlock()
+
+	s.logger.WithField("monitor_id", id).
+		WithField("url", current.URL).
+		WithField("alerts", len(alerts)).
+		Info("Monitor run completed")
+
+	// A monitor deleted during the run no longer wants its alerts
+	if exists && len(alerts) > 0 && current.WebhookURL != "" {
+		s.notify(current, alerts, result)
+	}
+}
+
+func (s *Scheduler) notify(current models.Monitor, alerts []models.Alert, result *models.AnalysisResult) {
+	notification := models.AlertNotification{
+		Event:     models.WebhookEventMonitorAlert,
+		MonitorID: current.ID,
+		URL:       current.URL,
+		Alerts:    alerts,
+		Result:    models.NewHistoryEntry(result),
+	}
+	delivery := s.notifier.Deliver(models.WebhookEventMonitorAlert, current.ID, current.WebhookURL, notification)
+
+	s.logger.WithField("monitor_id", current.ID).
+		WithField("delivery_id", delivery.ID).
+		Info("Monitor alerts sent to webhook")
+}
+
+// save persists the monitor. Failing to persist keeps the monitor running in memory.
+func (s *Scheduler) save(m *monitor) {
+	if s.persist == nil {
+		return
+	}
+	if err := s.persist.SaveMonitor(m.Monitor); err != nil {
+		s.logger.Error("Failed to save monitor "+m.ID, err)
+	}
+}
+
+// snapshot copies the monitor for callers outside the lock, with the time of its next scheduled run
+func (s *Scheduler) snapshot(m *monitor) models.Monitor {
+	snapshot := m.Monitor
+	snapshot.Rules = append([]models.MonitorRule(nil), m.Rules...)
+	snapshot.LastAlerts = append(make([]models.Alert, 0, len(m.LastAlerts)), m.LastAlerts...)
+	if m.entry != 0 {
+		if next := s.cron.Entry(m.entry).Next; !next.IsZero() {
+			snapshot.NextRunAt = &next
+		}
+	}
+	return snapshot
+}
+
+func newMonitorID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,333 @@
+package monitor
+
+import (
+	"WebAppAnalyzer/config/env"
+	"WebAppAnalyzer/config/logger"
+	"WebAppAnalyzer/internal/models"
+	"WebAppAnalyzer/internal/store"
+	"WebAppAnalyzer/internal/validator"
+	"WebAppAnalyzer/internal/webhook"
+	"context"
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// analyzerFunc adapts a function to the Analyzer interface
+type analyzerFunc func(ctx context.Context, url string) *models.AnalysisResult
+
+func (f analyzerFunc) Analyze(ctx context.Context, url string) *models.AnalysisResult {
+	return f(ctx, url)
+}
+
+// recordingNotifier keeps the notifications it was asked to send
+type recordingNotifier struct {
+	mu            sync.Mutex
+	notifications []models.AlertNotification
+}
+
+func (n *recordingNotifier) ValidateURL(url string) error {
+	return nil
+}
+
+func (n *recordingNotifier) Deliver(event, resourceID, url string, payload any) models.WebhookDelivery {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.notifications = append(n.notifications, payload.(models.AlertNotification))
+	return models.WebhookDelivery{ID: "d1", Event: event, ResourceID: resourceID, URL: url}
+}
+
+func (n *recordingNotifier) sent() []models.AlertNotification {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return append([]models.AlertNotification(nil), n.notifications...)
+}
+
+func createTestScheduler(analyzer Analyzer, history store.ResultStore, persist store.MonitorStore, notifier Notifier) *Scheduler {
+	config := &env.Config{LogLevel: "debug", DomainDenylist: "denied.example.com"}
+	urlValidator, _ := validator.NewURLValidatorWithPolicies(config)
+	return NewScheduler(analyzer, history, persist, notifier, urlValidator, logger.NewLogger(*config))
+}
+
+// waitForRun polls the monitor until it has run after the given time
+func waitForRun(t *testing.T, s *Scheduler, id string, after time.Time) models.Monitor {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		monitor, err := s.Get(id)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if monitor.LastRunAt != nil && monitor.LastRunAt.After(after) {
+			return monitor
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Expected the monitor to run")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// TestSchedulerCRUD tests creating, updating, listing and deleting monitors
+func TestSchedulerCRUD(t *testing.T) {
+	s := createTestScheduler(analyzerFunc(func(ctx context.Context, url string) *models.AnalysisResult {
+		return models.NewAnalysisResult(url)
+	}), nil, nil, nil)
+	defer s.Close()
+
+	created, err := s.Create(models.MonitorRequest{URL: "example.com", Schedule: "*/15 * * * *"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if created.URL != "https://example.com" || !created.Enabled || len(created.Rules) != len(defaultRules) {
+		t.Errorf("Expected an enabled monitor with the default rules, got %+v", created)
+	}
+	if created.NextRunAt == nil || created.NextRunAt.Minute()%15 != 0 {
+		t.Errorf("Expected the next run on the quarter hour, got %v", created.NextRunAt)
+	}
+
+	disabled := false
+	updated, err := s.Update(created.ID, models.MonitorRequest{
+		URL:      "https://example.com/pricing",
+		Schedule: "@hourly",
+		Rules:    []models.MonitorRule{{Type: models.RuleTitleMissing}},
+		Enabled:  &disabled,
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if updated.Enabled || updated.NextRunAt != nil || updated.Schedule != "@hourly" || updated.CreatedAt != created.CreatedAt {
+		t.Errorf("Expected a disabled monitor without a next run, got %+v", updated)
+	}
+
+	if monitors := s.List(); len(monitors) != 1 || monitors[0].ID != created.ID {
+		t.Errorf("Expected the monitor to be listed, got %+v", monitors)
+	}
+
+	if err := s.Delete(created.ID); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := s.Get(created.ID); !errors.Is(err, ErrMonitorNotFound) {
+		t.Errorf("Expected ErrMonitorNotFound, got %v", err)
+	}
+	if _, err := s.Update(created.ID, models.MonitorRequest{URL: "example.com", Schedule: "@daily"}); !errors.Is(err, ErrMonitorNotFound) {
+		t.Errorf("Expected ErrMonitorNotFound, got %v", err)
+	}
+}
+
+// TestSchedulerValidation tests that invalid monitors are rejected
+func TestSchedulerValidation(t *testing.T) {
+	config := &env.Config{LogLevel: "debug", WebhookSecret: "s3cret", DomainDenylist: "denied.example.com"}
+	webhooks := webhook.NewDispatcher(logger.NewLogger(*config), config)
+	defer webhooks.Close()
+	s := createTestScheduler(nil, nil, nil, webhooks)
+	defer s.Close()
+
+	requests := map[string]models.MonitorRequest{
+		"invalid URL":      {URL: "not a url", Schedule: "@hourly"},
+		"denied URL":       {URL: "https://denied.example.com", Schedule: "@hourly"},
+		"invalid schedule": {URL: "example.com", Schedule: "every now and then"},
+		"invalid rule":     {URL: "example.com", Schedule: "@hourly", Rules: []models.MonitorRule{{Type: "unknown"}}},
+		"invalid webhook":  {URL: "example.com", Schedule: "@hourly", WebhookURL: "ftp://hooks.example.com"},
+		"denied webhook":   {URL: "example.com", Schedule: "@hourly", WebhookURL: "https://denied.example.com/hook"},
+		"loopback webhook": {URL: "example.com", Schedule: "@hourly", WebhookURL: "http://127.0.0.1:9000/hook"},
+	}
+	for name, request := range requests {
+		if _, err := s.Create(request); !errors.Is(err, ErrInvalidMonitor) {
+			t.Errorf("Expected ErrInvalidMonitor for %s, got %v", name, err)
+		}
+	}
+}
+
+// TestSchedulerRun tests that runs compare with the previous result and post the alerts to the webhook
+func TestSchedulerRun(t *testing.T) {
+	var mu sync.Mutex
+	broken := []string{}
+	s := createTestScheduler(analyzerFunc(func(ctx context.Context, url string) *models.AnalysisResult {
+		mu.Lock()
+		defer mu.Unlock()
+		return createResult("Home", broken...)
+	}), nil, nil, &recordingNotifier{})
+	defer s.Close()
+	notifier := s.notifier.(*recordingNotifier)
+
+	created, err := s.Create(models.MonitorRequest{
+		URL:        "https://example.com",
+		Schedule:   "@daily",
+		Rules:      []models.MonitorRule{{Type: models.RuleNewBrokenLinks}},
+		WebhookURL: "https://hooks.example.com/alerts",
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	start := time.Now()
+	if _, err := s.Run(created.ID); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	first := waitForRun(t, s, created.ID, start)
+	if len(first.LastAlerts) != 0 {
+		t.Errorf("Expected the first run to set the baseline without alerts, got %+v", first.LastAlerts)
+	}
+
+	mu.Lock()
+	broken = []string{"https://example.com/gone"}
+	mu.Unlock()
+
+	if _, err := s.Run(created.ID); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	second := waitForRun(t, s, created.ID, *first.LastRunAt)
+	if len(second.LastAlerts) != 1 || second.LastAlerts[0].Rule != models.RuleNewBrokenLinks {
+		t.Fatalf("Expected a new broken links alert, got %+v", second.LastAlerts)
+	}
+
+	sent := notifier.sent()
+	if len(sent) != 1 || sent[0].MonitorID != created.ID || sent[0].Result.InaccessibleLinks != 1 {
+		t.Errorf("Expected one notification for the regression, got %+v", sent)
+	}
+}
+
+// TestSchedulerRun_FailedRun tests that a failed run keeps the baseline of the last successful run and alerts
+// once while the page stays down
+func TestSchedulerRun_FailedRun(t *testing.T) {
+	var mu sync.Mutex
+	var current *models.AnalysisResult
+	s := createTestScheduler(analyzerFunc(func(ctx context.Context, url string) *models.AnalysisResult {
+		mu.Lock()
+		defer mu.Unlock()
+		return current
+	}), nil, nil, nil)
+	defer s.Close()
+
+	created, err := s.Create(models.MonitorRequest{
+		URL:      "https://example.com",
+		Schedule: "@daily",
+		Rules: []models.MonitorRule{
+			{Type: models.RuleNewBrokenLinks},
+			{Type: models.RuleTitleMissing},
+			{Type: models.RuleAnalysisFailed},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	run := func(result *models.AnalysisResult, after time.Time) models.Monitor {
+		t.Helper()
+		mu.Lock()
+		current = result
+		mu.Unlock()
+		if _, err := s.Run(created.ID); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		return waitForRun(t, s, created.ID, after)
+	}
+	failed := func() *models.AnalysisResult {
+		result := models.NewAnalysisResult("https://example.com")
+		result.SetError("HTTP Error: 503 - Service Unavailable", 503)
+		return result
+	}
+
+	first := run(createResult(""), created.CreatedAt)
+	if len(first.LastAlerts) != 1 || first.LastAlerts[0].Rule != models.RuleTitleMissing {
+		t.Fatalf("Expected a missing title alert, got %+v", first.LastAlerts)
+	}
+
+	down := run(failed(), *first.LastRunAt)
+	if len(down.LastAlerts) != 1 || down.LastAlerts[0].Rule != models.RuleAnalysisFailed || down.LastError == "" {
+		t.Fatalf("Expected an analysis failed alert, got %+v", down)
+	}
+	stillDown := run(failed(), *down.LastRunAt)
+	if len(stillDown.LastAlerts) != 0 {
+		t.Errorf("Expected no alerts while the page stays down, got %+v", stillDown.LastAlerts)
+	}
+	if stillDown.BaselineResultID != first.LastResultID {
+		t.Errorf("Expected failed runs to keep the baseline, got %+v", stillDown)
+	}
+
+	recovered := run(createResult("", "https://example.com/gone"), *stillDown.LastRunAt)
+	if len(recovered.LastAlerts) != 1 || recovered.LastAlerts[0].Rule != models.RuleNewBrokenLinks {
+		t.Errorf("Expected only the links broken during the failure to alert, got %+v", recovered.LastAlerts)
+	}
+	if recovered.LastError != "" {
+		t.Errorf("Expected a successful run to clear the last error, got %q", recovered.LastError)
+	}
+}
+
+// TestSchedulerRun_DeletedDuringRun tests that a monitor deleted while it runs sends no alerts
+func TestSchedulerRun_DeletedDuringRun(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	s := createTestScheduler(analyzerFunc(func(ctx context.Context, url string) *models.AnalysisResult {
+		close(started)
+		<-release
+		return createResult("")
+	}), nil, nil, &recordingNotifier{})
+	defer s.Close()
+	notifier := s.notifier.(*recordingNotifier)
+
+	created, err := s.Create(models.MonitorRequest{
+		URL:        "https://example.com",
+		Schedule:   "@daily",
+		Rules:      []models.MonitorRule{{Type: models.RuleTitleMissing}},
+		WebhookURL: "https://hooks.example.com/alerts",
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if _, err := s.Run(created.ID); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	<-started
+	if err := s.Delete(created.ID); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	close(release)
+	s.wg.Wait()
+
+	if sent := notifier.sent(); len(sent) != 0 {
+		t.Errorf("Expected no notifications for a deleted monitor, got %+v", sent)
+	}
+}
+
+// TestSchedulerPersistence tests that monitors and their baselines survive a restart
+func TestSchedulerPersistence(t *testing.T) {
+	db, err := store.NewBoltStore(filepath.Join(t.TempDir(), "history.db"), 0)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer db.Close()
+
+	analyzer := analyzerFunc(func(ctx context.Context, url string) *models.AnalysisResult {
+		return createResult("Home")
+	})
+
+	s := createTestScheduler(analyzer, db, db, nil)
+	created, err := s.Create(models.MonitorRequest{URL: "https://example.com", Schedule: "@daily"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	s.Run(created.ID)
+	ran := waitForRun(t, s, created.ID, created.CreatedAt)
+	s.Close()
+
+	restarted := createTestScheduler(analyzer, db, db, nil)
+	defer restarted.Close()
+
+	restored, err := restarted.Get(created.ID)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if restored.URL != created.URL || restored.LastResultID == "" || restored.LastResultID != ran.LastResultID ||
+		restored.BaselineResultID != ran.LastResultID || restored.NextRunAt == nil {
+		t.Errorf("Expected the monitor to be restored and scheduled, got %+v", restored)
+	}
+	if _, err := db.Get(restored.LastResultID); err != nil {
+		t.Errorf("Expected the result to be saved to the history, got %v", err)
+	}
+}
@@ -0,0 +1,137 @@
+package netguard
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// ErrBlockedAddress is returned when a connection to a private, loopback, link-local or otherwise reserved
+// address is refused
+var ErrBlockedAddress = errors.New("connections to private, loopback, link-local and reserved addresses are blocked")
+
+// blockedPrefixes are the reserved ranges not covered by the netip.Addr predicates used in Blocked
+var blockedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),         // "this" network
+	netip.MustParsePrefix("100.64.0.0/10"),     // carrier-grade NAT, also home of some cloud metadata services
+	netip.MustParsePrefix("192.0.0.0/24"),      // IETF protocol assignments
+	netip.MustParsePrefix("198.18.0.0/15"),     // benchmarking
+	netip.MustParsePrefix("240.0.0.0/4"),       // reserved, including the broadcast address
+	netip.MustParsePrefix("64:ff9b:1::/48"),    // local-use IPv4/IPv6 translation
+	netip.MustParsePrefix("fd00:ec2::254/128"), // the IPv6 instance metadata service
+}
+
+// IPv6 ranges whose addresses reach the IPv4 host embedded in them
+var (
+	ipv4Compatible = netip.MustParsePrefix("::/96")        // deprecated IPv4-compatible addresses
+	nat64          = netip.MustParsePrefix("64:ff9b::/96") // well-known IPv4/IPv6 translation prefix
+	sixToFour      = netip.MustParsePrefix("2002::/16")    // 6to4, the IPv4 address follows the prefix
+)
+
+// Guard refuses connections to addresses that are not on the public internet, so user-supplied URLs cannot be
+// used to reach the internal network or cloud metadata services. Addresses in the allowlist are always allowed.
+type Guard struct {
+	allowlist []netip.Prefix
+}
+
+// NewGuard creates a guard from a comma separated allowlist of IP addresses and CIDR ranges. Invalid entries are
+// left out of the allowlist and reported in the returned error.
+func NewGuard(allowlist string) (*Guard, error) {
+	g := &Guard{}
+	var errs []error
+	for _, entry := range strings.Split(allowlist, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		prefix, err := parsePrefix(entry)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("invalid allowlist entry %q: %w", entry, err))
+			continue
+		}
+		g.allowlist = append(g.allowlist, prefix)
+	}
+	return g, errors.Join(errs...)
+}
+
+// Blocked reports whether connections to the address are refused
+func (g *Guard) Blocked(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	for _, prefix := range g.allowlist {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+
+	if addr.IsLoopback() || addr.IsPrivate() || addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() ||
+		addr.IsInterfaceLocalMulticast() || addr.IsMulticast() || addr.IsUnspecified() {
+		return true
+	}
+	for _, prefix := range blockedPrefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	if embedded, ok := embeddedIPv4(addr); ok {
+		return g.Blocked(embedded)
+	}
+	return false
+}
+
+// embeddedIPv4 returns the IPv4 address embedded in an IPv4-compatible, NAT64 or 6to4 address, so translated
+// addresses are held to the same rules as the IPv4 hosts they lead to
+func embeddedIPv4(addr netip.Addr) (netip.Addr, bool) {
+	bytes := addr.As16()
+	switch {
+	case !addr.Is6():
+		return netip.Addr{}, false
+	case ipv4Compatible.Contains(addr), nat64.Contains(addr):
+		return netip.AddrFrom4([4]byte(bytes[12:16])), true
+	case sixToFour.Contains(addr):
+		return netip.AddrFrom4([4]byte(bytes[2:6])), true
+	}
+	return netip.Addr{}, false
+}
+
+// Control checks the address a dialer is about to connect to. It runs after DNS resolution, for every
+// connection including those of redirects, so host names resolving to blocked addresses are refused too.
+func (g *Guard) Control(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return err
+	}
+	if g.Blocked(addr) {
+		return fmt.Errorf("%w: %s", ErrBlockedAddress, addr)
+	}
+	return nil
+}
+
+// Dialer returns a dialer that only connects to addresses the guard allows
+func (g *Guard) Dialer(timeout time.Duration) *net.Dialer {
+	return &net.Dialer{
+		Timeout:   timeout,
+		KeepAlive: 30 * time.Second,
+		Control:   g.Control,
+	}
+}
+
+func parsePrefix(entry string) (netip.Prefix, error) {
+	if strings.Contains(entry, "/") {
+		prefix, err := netip.ParsePrefix(entry)
+		return prefix.Masked(), err
+	}
+	addr, err := netip.ParseAddr(entry)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	addr = addr.Unmap()
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
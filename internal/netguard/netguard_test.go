@@ -0,0 +1,117 @@
+package netguard
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+)
+
+// TestBlocked tests the blocked address ranges and the allowlist
+func TestBlocked(t *testing.T) {
+	guard, err := NewGuard("10.1.0.0/16, 192.168.1.10")
+	if err != nil {
+		t.Fatalf("Expected a valid allowlist, got %v", err)
+	}
+
+	tests := []struct {
+		addr    string
+		blocked bool
+	}{
+		{"93.184.216.34", false},
+		{"2606:2800:220:1:248:1893:25c8:1946", false},
+		{"127.0.0.1", true},
+		{"::1", true},
+		{"10.0.0.1", true},
+		{"172.16.5.4", true},
+		{"192.168.1.1", true},
+		{"169.254.169.254", true},
+		{"100.100.100.200", true},
+		{"fd00:ec2::254", true},
+		{"fe80::1", true},
+		{"0.0.0.0", true},
+		{"::", true},
+		{"224.0.0.1", true},
+		{"255.255.255.255", true},
+		{"::ffff:127.0.0.1", true},
+		{"::ffff:169.254.169.254", true},
+		// IPv4 embedded in IPv6
+		{"::127.0.0.1", true},
+		{"::169.254.169.254", true},
+		{"::93.184.216.34", false},
+		{"64:ff9b::127.0.0.1", true},
+		{"64:ff9b::a9fe:a9fe", true},
+		{"64:ff9b::10.0.0.1", true},
+		{"64:ff9b::93.184.216.34", false},
+		{"64:ff9b:1::5db8:d822", true},
+		{"2002:7f00:1::", true},
+		{"2002:a9fe:a9fe::1", true},
+		{"2002:c0a8:101::", true},
+		{"2002:5db8:d822::1", false},
+		// Allowlisted
+		{"10.1.2.3", false},
+		{"192.168.1.10", false},
+		{"::ffff:10.1.2.3", false},
+		{"64:ff9b::10.1.2.3", false},
+	}
+
+	for _, tt := range tests {
+		if blocked := guard.Blocked(netip.MustParseAddr(tt.addr)); blocked != tt.blocked {
+			t.Errorf("Blocked(%s) = %v, expected %v", tt.addr, blocked, tt.blocked)
+		}
+	}
+}
+
+// TestNewGuard_InvalidEntries tests that invalid allowlist entries are reported and left out
+func TestNewGuard_InvalidEntries(t *testing.T) {
+	guard, err := NewGuard("10.0.0.0/8,not-an-ip,,300.0.0.1/8")
+	if err == nil {
+		t.Error("Expected an error for the invalid entries")
+	}
+	if len(guard.allowlist) != 1 {
+		t.Errorf("Expected only the valid entry in the allowlist, got %v", guard.allowlist)
+	}
+}
+
+// TestDialer tests that connections are checked after resolution, on redirects too
+func TestDialer(t *testing.T) {
+	internal, err := net.Listen("tcp", "127.0.0.2:0")
+	if err != nil {
+		t.Skipf("127.0.0.2 is not available: %v", err)
+	}
+	internalServer := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	internalServer.Listener.Close()
+	internalServer.Listener = internal
+	internalServer.Start()
+	defer internalServer.Close()
+
+	redirect := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, internalServer.URL, http.StatusFound)
+	}))
+	defer redirect.Close()
+
+	client := func(allowlist string) *http.Client {
+		guard, err := NewGuard(allowlist)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return &http.Client{Transport: &http.Transport{DialContext: guard.Dialer(5 * time.Second).DialContext}}
+	}
+
+	if _, err := client("").Get(redirect.URL); !errors.Is(err, ErrBlockedAddress) {
+		t.Errorf("Expected the loopback address to be blocked, got %v", err)
+	}
+	if _, err := client("127.0.0.1").Get(redirect.URL); !errors.Is(err, ErrBlockedAddress) {
+		t.Errorf("Expected the redirect to the internal address to be blocked, got %v", err)
+	}
+	resp, err := client("127.0.0.1,127.0.0.2").Get(redirect.URL)
+	if err != nil {
+		t.Fatalf("Expected the allowlisted addresses to be reachable, got %v", err)
+	}
+	resp.Body.Close()
+}
@@ -5,23 +5,63 @@ import (
 	"WebAppAnalyzer/config/logger"
 	"WebAppAnalyzer/internal/analyzer"
 	"WebAppAnalyzer/internal/handlers"
+	"WebAppAnalyzer/internal/jobs"
+	"WebAppAnalyzer/internal/metrics"
+	"WebAppAnalyzer/internal/monitor"
+	"WebAppAnalyzer/internal/store"
+	"WebAppAnalyzer/internal/tlsreload"
+	"WebAppAnalyzer/internal/tracing"
+	"WebAppAnalyzer/internal/webhook"
 	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"net"
 	"net/http"
+	"os"
 	"path/filepath"
 	"time"
 )
 
+const (
+	defaultHistoryDBPath      = "data/history.db"
+	defaultHistoryRetention   = 30 * 24 * time.Hour
+	defaultServerReadTimeout  = 15 * time.Second
+	defaultServerWriteTimeout = 2 * time.Minute
+	defaultServerIdleTimeout  = 60 * time.Second
+	// cancelGracePeriod is how long the requests still in flight at the shutdown deadline get to respond once
+	// their analyses are cancelled
+	cancelGracePeriod = 5 * time.Second
+	// traceFlushTimeout bounds the export of the spans still buffered on shutdown
+	traceFlushTimeout = 5 * time.Second
+)
+
 type Server struct {
-	engine  *gin.Engine
-	handler *handlers.Handler
-	logger  *logger.Logger
-	config  *env.Config
+	engine   *gin.Engine
+	handler  *handlers.Handler
+	analyzer *analyzer.PageAnalyzer
+	jobs     *jobs.Manager
+	monitors *monitor.Scheduler
+	webhooks *webhook.Dispatcher
+	history  store.ResultStore
+	logger   *logger.Logger
+	config   *env.Config
+
+	// httpServer serves the engine, and cancelRequests cancels the context of every request it handles, stopping
+	// the analyses and link checks in flight
+	httpServer     *http.Server
+	cancelRequests context.CancelFunc
+	// metrics is nil when METRICS_ENABLED is off
+	metrics *metrics.Metrics
+	// tracerProvider is nil when TRACING_EXPORTER is off
+	tracerProvider *sdktrace.TracerProvider
 }
 
 func NewServer(logger *logger.Logger, c *env.Config) *Server {
@@ -35,14 +75,43 @@ func NewServer(logger *logger.Logger, c *env.Config) *Server {
 
 	engine := gin.New()
 
-	handler := handlers.NewHandler(pageAnalyzer, logger, c)
+	history := openHistory(logger, c)
+	if c.WebhookSecret == "" {
+		logger.Warn("WEBHOOK_SECRET is not set, callback URLs and monitor webhooks are rejected")
+	}
+	webhooks := webhook.NewDispatcher(logger, c)
+	jobManager := jobs.NewManager(pageAnalyzer, history, webhooks, logger, c)
+
+	monitors := openMonitors(pageAnalyzer, history, webhooks, logger, c)
+
+	// A nil scheduler must reach the handler as a nil interface so the monitor endpoints report it as disabled
+	var monitorScheduler handlers.MonitorSchedulerInterface
+	if monitors != nil {
+		monitorScheduler = monitors
+	}
+	handler := handlers.NewHandler(pageAnalyzer, jobManager, history, monitorScheduler, webhooks, logger, c)
 
 	server := &Server{
-		engine:  engine,
-		handler: handler,
-		logger:  logger,
-		config:  c,
+		engine:   engine,
+		handler:  handler,
+		analyzer: pageAnalyzer,
+		jobs:     jobManager,
+		monitors: monitors,
+		webhooks: webhooks,
+		history:  history,
+		logger:   logger,
+		config:   c,
+	}
+	server.httpServer, server.cancelRequests = newHTTPServer(engine, c)
+	if c.MetricsEnabled {
+		server.metrics = metrics.New(pageAnalyzer.PoolStats)
+		pageAnalyzer.SetMetrics(server.metrics)
+	}
+	tracerProvider, err := tracing.NewTracerProvider(c)
+	if err != nil {
+		logger.Error("Failed to set up tracing, spans are not exported", err)
 	}
+	server.tracerProvider = tracerProvider
 
 	// Setup middleware and routes
 	server.setupMiddleware()
@@ -51,25 +120,182 @@ func NewServer(logger *logger.Logger, c *env.Config) *Server {
 	return server
 }
 
+// newHTTPServer creates the HTTP server of the engine with the SERVER_*_TIMEOUT_SECONDS timeouts, speaking
+// HTTP/2 as well as HTTP/1.1 when HTTP2_ENABLED is set. The requests it handles share a context, cancelled by
+// the returned function.
+func newHTTPServer(engine *gin.Engine, c *env.Config) (*http.Server, context.CancelFunc) {
+	protocols := new(http.Protocols)
+	protocols.SetHTTP1(true)
+	if c.HTTP2Enabled {
+		protocols.SetHTTP2(true)
+		// Without TLS, HTTP/2 is spoken to clients starting the connection with it, such as proxies using h2c
+		protocols.SetUnencryptedHTTP2(!tlsEnabled(c))
+	}
+
+	requestCtx, cancelRequests := context.WithCancel(context.Background())
+	httpServer := &http.Server{
+		Addr:         fmt.Sprintf(":%s", c.Port),
+		Handler:      engine,
+		ReadTimeout:  durationOrDefault(c.ServerReadTimeoutInSeconds, defaultServerReadTimeout),
+		WriteTimeout: durationOrDefault(c.ServerWriteTimeoutInSeconds, defaultServerWriteTimeout),
+		IdleTimeout:  durationOrDefault(c.ServerIdleTimeoutInSeconds, defaultServerIdleTimeout),
+		Protocols:    protocols,
+		BaseContext: func(net.Listener) context.Context {
+			return requestCtx
+		},
+	}
+	return httpServer, cancelRequests
+}
+
+// tlsEnabled reports whether the server is configured to serve TLS
+func tlsEnabled(c *env.Config) bool {
+	return c.TLSCertFile != "" || c.TLSKeyFile != ""
+}
+
+func durationOrDefault(seconds int, fallback time.Duration) time.Duration {
+	if seconds <= 0 {
+		return fallback
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// openHistory opens the analysis history store, or returns nil when the history is disabled or cannot be opened
+func openHistory(logger *logger.Logger, c *env.Config) store.ResultStore {
+	if !c.HistoryEnabled {
+		return nil
+	}
+
+	path := c.HistoryDBPath
+	if path == "" {
+		path = defaultHistoryDBPath
+	}
+
+	history, err := store.NewBoltStore(path, durationOrDefault(c.HistoryRetentionInSeconds, defaultHistoryRetention))
+	if err != nil {
+		logger.Error("Failed to open history store, analysis history is disabled", err)
+		return nil
+	}
+	logger.WithField("path", path).Info("Analysis history enabled")
+
+	return history
+}
+
+// openMonitors starts the monitor scheduler, or returns nil when monitoring is disabled. Monitors are kept in
+// the history database when it is open, and only in memory otherwise.
+func openMonitors(pageAnalyzer *analyzer.PageAnalyzer, history store.ResultStore, webhooks *webhook.Dispatcher, logger *logger.Logger, c *env.Config) *monitor.Scheduler {
+	if !c.MonitorsEnabled {
+		return nil
+	}
+
+	persist, _ := history.(store.MonitorStore)
+	if persist == nil {
+		logger.Warn("Analysis history is disabled, monitors are not kept across restarts")
+	}
+	return monitor.NewScheduler(pageAnalyzer, history, persist, webhooks, pageAnalyzer.URLValidator(), logger)
+}
+
+// ListenAndServe serves requests on the port until the server is shut down, returning nil after a shutdown. With
+// TLS_CERT_FILE and TLS_KEY_FILE the server speaks TLS only, reloading the certificate when the files change.
 func (s *Server) ListenAndServe(port *string) error {
-	s.logger.Logger.Info("Starting server")
-	portAddr := fmt.Sprintf(":%s", *port)
-	return s.engine.Run(portAddr)
+	s.httpServer.Addr = fmt.Sprintf(":%s", *port)
+	s.logger.WithField("addr", s.httpServer.Addr).
+		WithField("tls", tlsEnabled(s.config)).
+		WithField("http2", s.config.HTTP2Enabled).
+		Info("Starting server")
+
+	var err error
+	if tlsEnabled(s.config) {
+		err = s.listenAndServeTLS()
+	} else if s.config.TLSClientCAFile != "" {
+		err = errors.New("TLS_CLIENT_CA_FILE requires TLS_CERT_FILE and TLS_KEY_FILE")
+	} else {
+		err = s.httpServer.ListenAndServe()
+	}
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
 }
 
+func (s *Server) listenAndServeTLS() error {
+	reloader, err := tlsreload.NewReloader(s.config.TLSCertFile, s.config.TLSKeyFile, s.config.TLSClientCAFile,
+		time.Duration(s.config.TLSReloadIntervalInSeconds)*time.Second, s.logger)
+	if err != nil {
+		return fmt.Errorf("failed to set up TLS: %w", err)
+	}
+	defer reloader.Close()
+
+	// The protocols are negotiated from the configuration of each connection, so they are listed explicitly
+	nextProtos := []string{"http/1.1"}
+	if s.config.HTTP2Enabled {
+		nextProtos = []string{"h2", "http/1.1"}
+	}
+	s.httpServer.TLSConfig = reloader.Config(&tls.Config{
+		MinVersion: tls.VersionTLS12,
+		NextProtos: nextProtos,
+	})
+	return s.httpServer.ListenAndServeTLS("", "")
+}
+
+// Shutdown drains the server. The readiness check reports it as draining for SERVER_DRAIN_DELAY_SECONDS while
+// requests are still accepted, so load balancers stop routing to it, then the server stops accepting connections
+// and waits for the requests in flight until ctx is done. Analyses still running at that point are cancelled,
+// which stops their link checks, and get a short grace period to respond before their connections are closed.
+// The background workers and the history store are closed last.
 func (s *Server) Shutdown(ctx context.Context) error {
-	s.logger.Logger.Info("Shutting down server")
-	port := fmt.Sprintf(":%s", s.config.Port)
+	s.logger.Logger.Info("Shutting down server, draining requests in flight")
+	s.handler.SetDraining()
 
-	srv := &http.Server{
-		Addr: port,
+	if delay := time.Duration(s.config.ServerDrainDelayInSeconds) * time.Second; delay > 0 {
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+		}
+	}
+
+	err := s.httpServer.Shutdown(ctx)
+	if err != nil {
+		s.logger.Warn("Shutdown deadline reached, cancelling the requests still in flight")
+		s.cancelRequests()
+
+		graceCtx, cancel := context.WithTimeout(context.Background(), cancelGracePeriod)
+		if graceErr := s.httpServer.Shutdown(graceCtx); graceErr != nil {
+			s.httpServer.Close()
+		}
+		cancel()
+	}
+	s.cancelRequests()
+
+	if s.monitors != nil {
+		s.monitors.Close()
+	}
+	s.jobs.Close()
+	s.webhooks.Close()
+	s.analyzer.Close()
+	if s.history != nil {
+		if closeErr := s.history.Close(); closeErr != nil {
+			s.logger.Error("Failed to close history store", closeErr)
+		}
 	}
-	return srv.Shutdown(ctx)
+	if s.tracerProvider != nil {
+		flushCtx, cancel := context.WithTimeout(context.Background(), traceFlushTimeout)
+		if flushErr := s.tracerProvider.Shutdown(flushCtx); flushErr != nil {
+			s.logger.Error("Failed to export the remaining spans", flushErr)
+		}
+		cancel()
+	}
+	return err
 }
 
 func (s Server) setupMiddleware() {
 	s.engine.Use(gin.Recovery())
 	s.engine.Use(s.loggingMiddleware())
+	if s.tracerProvider != nil {
+		s.engine.Use(otelgin.Middleware(tracing.ServiceName, otelgin.WithTracerProvider(s.tracerProvider)))
+	}
+	if s.metrics != nil {
+		s.engine.Use(s.metrics.Middleware())
+	}
 
 	s.engine.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{"*"},
@@ -94,6 +320,22 @@ func (s Server) securityHeadersMiddleware() gin.HandlerFunc {
 	}
 }
 
+// requireClientCertificate rejects the requests that did not present a client certificate signed by one of the
+// TLS_CLIENT_CA_FILE authorities
+func (s Server) requireClientCertificate() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.TLS == nil || len(c.Request.TLS.VerifiedChains) == 0 {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, handlers.APIError{
+				Error:   "Unauthorized",
+				Code:    http.StatusUnauthorized,
+				Message: "A client certificate signed by a trusted authority is required",
+			})
+			return
+		}
+		c.Next()
+	}
+}
+
 func (s Server) loggingMiddleware() gin.HandlerFunc {
 	return gin.LoggerWithFormatter(func(param gin.LogFormatterParams) string {
 		s.logger.Logger.WithFields(logrus.Fields{
@@ -105,7 +347,7 @@ func (s Server) loggingMiddleware() gin.HandlerFunc {
 			"user_agent": param.Request.UserAgent(),
 			"error":      param.ErrorMessage,
 			"timestamp":  param.TimeStamp.Format(time.RFC3339),
-		}).Info("Request: %s %s", param.Method, param.Path)
+		}).Infof("Request: %s %s", param.Method, param.Path)
 		return ""
 	})
 }
@@ -136,17 +378,60 @@ func (s Server) setupRoutes() {
 		panic("HTML templates not found")
 	}
 
+	staticPaths := []string{
+		"web/static",
+		"../web/static",
+		"../../web/static",
+	}
+	for _, path := range staticPaths {
+		if info, err := os.Stat(path); err == nil && info.IsDir() {
+			s.engine.Static("/static", path)
+			s.logger.WithField("static_path", path).Info("Static files loaded successfully")
+			break
+		}
+	}
+
 	s.engine.GET("/health", s.handler.HealthCheck)
+	s.engine.GET("/ready", s.handler.ReadinessCheck)
+	if s.metrics != nil {
+		s.engine.GET("/metrics", gin.WrapH(s.metrics.Handler()))
+	}
 
 	api := s.engine.Group("/api/v1")
+	if s.config.TLSClientCAFile != "" {
+		api.Use(s.requireClientCertificate())
+	}
 	{
 		api.POST("/analyze", s.handler.AnalyzePage)
 		api.GET("/analyze", s.handler.AnalyzePage)
+		api.POST("/analyze/html", s.handler.AnalyzeHTMLUpload)
+		api.POST("/crawl", s.handler.CrawlSite)
+		api.GET("/crawl", s.handler.CrawlSite)
+		api.POST("/batch", s.handler.AnalyzeBatch)
+		api.POST("/compare", s.handler.ComparePages)
+		api.GET("/history", s.handler.ListHistory)
+		api.GET("/history/:id", s.handler.GetHistoryEntry)
+		api.DELETE("/history/:id", s.handler.DeleteHistoryEntry)
+		api.POST("/monitors", s.handler.CreateMonitor)
+		api.GET("/monitors", s.handler.ListMonitors)
+		api.GET("/monitors/:id", s.handler.GetMonitor)
+		api.PUT("/monitors/:id", s.handler.UpdateMonitor)
+		api.DELETE("/monitors/:id", s.handler.DeleteMonitor)
+		api.POST("/monitors/:id/run", s.handler.RunMonitor)
+		api.GET("/webhooks/deliveries", s.handler.ListWebhookDeliveries)
+		api.GET("/webhooks/deliveries/:id", s.handler.GetWebhookDelivery)
+		api.POST("/jobs", s.handler.CreateJob)
+		api.GET("/jobs/:id", s.handler.GetJob)
+		api.DELETE("/jobs/:id", s.handler.CancelJob)
+		api.GET("/jobs/:id/events", s.handler.StreamJobEvents)
 	}
 
 	//Web routes
 	s.engine.GET("/", s.handler.Index)
 	s.engine.POST("/analyze", s.handler.AnalyzePageForm)
+	s.engine.GET("/jobs/:id", s.handler.JobPage)
+	s.engine.GET("/compare", s.handler.ComparePage)
+	s.engine.POST("/compare", s.handler.CompareForm)
 
 	//Swagger documentation
 	s.engine.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
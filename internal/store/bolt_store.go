@@ -0,0 +1,264 @@
+package store
+
+import (
+	"WebAppAnalyzer/internal/models"
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"go.etcd.io/bbolt"
+	"math"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	defaultHistoryLimit = 50
+	maxHistoryLimit     = 500
+)
+
+var (
+	// maxKeyTime is the latest analysis time an ID can hold
+	maxKeyTime = time.Unix(0, math.MaxInt64)
+
+	resultsBucket = []byte("results")
+	// entriesBucket holds the history summaries under the same keys, so listing does not decode whole results
+	entriesBucket  = []byte("entries")
+	monitorsBucket = []byte("monitors")
+)
+
+// BoltStore is a ResultStore and MonitorStore backed by an embedded bbolt database file
+type BoltStore struct {
+	db        *bbolt.DB
+	retention time.Duration
+}
+
+// NewBoltStore opens or creates the database file at the given path. Results analyzed longer than retention ago
+// are deleted as new ones are saved, and a retention of zero keeps them all.
+func NewBoltStore(path string, retention time.Duration) (*BoltStore, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+
+	db, err := bbolt.Open(path, 0o600, &bbolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open history database: %w", err)
+	}
+
+	err = db.Update(func(tx *bbolt.Tx) error {
+		for _, bucket := range [][]byte{resultsBucket, entriesBucket, monitorsBucket} {
+			if _, err := tx.CreateBucketIfNotExists(bucket); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &BoltStore{db: db, retention: retention}, nil
+}
+
+// Save stores the result under a new time ordered ID. The result only gets the ID once it is stored, so a failed
+// save leaves no ID pointing at a missing result.
+func (s *BoltStore) Save(result *models.AnalysisResult) error {
+	id, err := newResultID(result.Timestamp)
+	if err != nil {
+		return err
+	}
+
+	stored := *result
+	stored.ID = id
+	data, err := json.Marshal(&stored)
+	if err != nil {
+		return err
+	}
+	entry, err := json.Marshal(models.NewHistoryEntry(&stored))
+	if err != nil {
+		return err
+	}
+
+	err = s.db.Update(func(tx *bbolt.Tx) error {
+		if s.retention > 0 {
+			if err := prune(tx, time.Now().Add(-s.retention)); err != nil {
+				return err
+			}
+		}
+		if err := tx.Bucket(resultsBucket).Put([]byte(id), data); err != nil {
+			return err
+		}
+		return tx.Bucket(entriesBucket).Put([]byte(id), entry)
+	})
+	if err != nil {
+		return err
+	}
+
+	result.ID = id
+	return nil
+}
+
+// Get returns a stored result by ID
+func (s *BoltStore) Get(id string) (*models.AnalysisResult, error) {
+	var result *models.AnalysisResult
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		data := tx.Bucket(resultsBucket).Get([]byte(id))
+		if data == nil {
+			return ErrNotFound
+		}
+		return json.Unmarshal(data, &result)
+	})
+	return result, err
+}
+
+// List returns the stored results matching the filter, newest first
+func (s *BoltStore) List(filter models.HistoryFilter) (models.HistoryPage, error) {
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = defaultHistoryLimit
+	}
+	limit = min(limit, maxHistoryLimit)
+	offset := max(filter.Offset, 0)
+
+	page := models.HistoryPage{
+		Entries: make([]models.HistoryEntry, 0),
+		Limit:   limit,
+		Offset:  offset,
+	}
+
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		cursor := tx.Bucket(entriesBucket).Cursor()
+		// Keys start with the analysis time, so walking backwards from the end of the range lists the newest first
+		// and the walk stops at its start
+		key, value := cursor.Last()
+		if !filter.To.IsZero() {
+			if key, _ = cursor.Seek(append(timeKey(filter.To), 0xff)); key == nil {
+				key, value = cursor.Last()
+			} else {
+				key, value = cursor.Prev()
+			}
+		}
+		var from []byte
+		if !filter.From.IsZero() {
+			from = timeKey(filter.From)
+		}
+
+		for ; key != nil && bytes.Compare(key, from) >= 0; key, value = cursor.Prev() {
+			var entry models.HistoryEntry
+			if err := json.Unmarshal(value, &entry); err != nil {
+				return err
+			}
+			if !filter.Matches(entry) {
+				continue
+			}
+
+			if page.Total >= offset && len(page.Entries) < limit {
+				page.Entries = append(page.Entries, entry)
+			}
+			page.Total++
+		}
+		return nil
+	})
+
+	return page, err
+}
+
+// Delete removes a stored result
+func (s *BoltStore) Delete(id string) error {
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		results := tx.Bucket(resultsBucket)
+		if results.Get([]byte(id)) == nil {
+			return ErrNotFound
+		}
+		if err := results.Delete([]byte(id)); err != nil {
+			return err
+		}
+		return tx.Bucket(entriesBucket).Delete([]byte(id))
+	})
+}
+
+// SaveMonitor creates or replaces a monitor
+func (s *BoltStore) SaveMonitor(monitor models.Monitor) error {
+	data, err := json.Marshal(monitor)
+	if err != nil {
+		return err
+	}
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		return tx.Bucket(monitorsBucket).Put([]byte(monitor.ID), data)
+	})
+}
+
+// ListMonitors returns all stored monitors
+func (s *BoltStore) ListMonitors() ([]models.Monitor, error) {
+	monitors := make([]models.Monitor, 0)
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		return tx.Bucket(monitorsBucket).ForEach(func(_, value []byte) error {
+			var monitor models.Monitor
+			if err := json.Unmarshal(value, &monitor); err != nil {
+				return err
+			}
+			monitors = append(monitors, monitor)
+			return nil
+		})
+	})
+	return monitors, err
+}
+
+// DeleteMonitor removes a stored monitor
+func (s *BoltStore) DeleteMonitor(id string) error {
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		return tx.Bucket(monitorsBucket).Delete([]byte(id))
+	})
+}
+
+// Close closes the database file
+func (s *BoltStore) Close() error {
+	return s.db.Close()
+}
+
+// prune deletes the results analyzed before the cutoff. Keys start with the analysis time, so they come first.
+func prune(tx *bbolt.Tx, cutoff time.Time) error {
+	limit := timeKey(cutoff)
+	var expired [][]byte
+	cursor := tx.Bucket(entriesBucket).Cursor()
+	for key, _ := cursor.First(); key != nil && bytes.Compare(key, limit) < 0; key, _ = cursor.Next() {
+		expired = append(expired, bytes.Clone(key))
+	}
+
+	for _, key := range expired {
+		if err := tx.Bucket(resultsBucket).Delete(key); err != nil {
+			return err
+		}
+		if err := tx.Bucket(entriesBucket).Delete(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// newResultID returns an ID that sorts by the analysis time, with a random suffix to keep it unique
+func newResultID(timestamp time.Time) (string, error) {
+	if timestamp.IsZero() {
+		timestamp = time.Now()
+	}
+
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return "", err
+	}
+	return string(timeKey(timestamp)) + hex.EncodeToString(suffix), nil
+}
+
+// timeKey returns the time prefix of the IDs of results analyzed at the given time, clamped to the times IDs can hold
+func timeKey(t time.Time) []byte {
+	switch {
+	case t.Before(time.Unix(0, 0)):
+		t = time.Unix(0, 0)
+	case t.After(maxKeyTime):
+		t = maxKeyTime
+	}
+	return []byte(fmt.Sprintf("%016x", t.UnixNano()))
+}
@@ -0,0 +1,177 @@
+package store
+
+import (
+	"WebAppAnalyzer/internal/models"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func createTestStore(t *testing.T) *BoltStore {
+	t.Helper()
+
+	s, err := NewBoltStore(filepath.Join(t.TempDir(), "history", "history.db"), 0)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+// TestBoltStore tests saving, fetching and deleting results
+func TestBoltStore(t *testing.T) {
+	s := createTestStore(t)
+
+	result := models.NewAnalysisResult("https://example.com")
+	result.PageTitle = "Example"
+	result.AddLink(models.LinkDetail{URL: "https://example.com/broken", IsAccessible: false})
+
+	if err := s.Save(result); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if result.ID == "" {
+		t.Fatal("Expected the result to get an ID")
+	}
+
+	stored, err := s.Get(result.ID)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if stored.PageTitle != "Example" || len(stored.Links) != 1 || stored.InaccessibleLinks != 1 {
+		t.Errorf("Expected the stored result to match, got %+v", stored)
+	}
+
+	if err := s.Delete(result.ID); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := s.Get(result.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+	if err := s.Delete(result.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+}
+
+// TestBoltStoreList tests listing results newest first with filters and pagination
+func TestBoltStoreList(t *testing.T) {
+	s := createTestStore(t)
+
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < 6; i++ {
+		result := models.NewAnalysisResult(fmt.Sprintf("https://site%d.com", i%2))
+		result.Timestamp = start.Add(time.Duration(i) * 24 * time.Hour)
+		if err := s.Save(result); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+
+	tests := []struct {
+		name          string
+		filter        models.HistoryFilter
+		expectedTotal int
+		expectedDays  []int
+	}{
+		{
+			name:          "All, newest first",
+			filter:        models.HistoryFilter{},
+			expectedTotal: 6,
+			expectedDays:  []int{6, 5, 4, 3, 2, 1},
+		},
+		{
+			name:          "By URL",
+			filter:        models.HistoryFilter{URL: "https://site1.com"},
+			expectedTotal: 3,
+			expectedDays:  []int{6, 4, 2},
+		},
+		{
+			name:          "By date range",
+			filter:        models.HistoryFilter{From: start.Add(24 * time.Hour), To: start.Add(3 * 24 * time.Hour)},
+			expectedTotal: 3,
+			expectedDays:  []int{4, 3, 2},
+		},
+		{
+			name:          "Until a day",
+			filter:        models.HistoryFilter{To: start.Add(2 * 24 * time.Hour)},
+			expectedTotal: 3,
+			expectedDays:  []int{3, 2, 1},
+		},
+		{
+			name:          "From a day",
+			filter:        models.HistoryFilter{From: start.Add(4 * 24 * time.Hour)},
+			expectedTotal: 2,
+			expectedDays:  []int{6, 5},
+		},
+		{
+			name:          "Paginated",
+			filter:        models.HistoryFilter{Limit: 2, Offset: 1},
+			expectedTotal: 6,
+			expectedDays:  []int{5, 4},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, err := s.List(tt.filter)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if page.Total != tt.expectedTotal {
+				t.Errorf("Expected total %d, got %d", tt.expectedTotal, page.Total)
+			}
+			if len(page.Entries) != len(tt.expectedDays) {
+				t.Fatalf("Expected %d entries, got %d", len(tt.expectedDays), len(page.Entries))
+			}
+			for i, entry := range page.Entries {
+				if day := entry.Timestamp.Day(); day != tt.expectedDays[i] {
+					t.Errorf("Expected entry %d from day %d, got day %d", i, tt.expectedDays[i], day)
+				}
+			}
+		})
+	}
+}
+
+// TestBoltStoreSave_Failed tests that a result that could not be saved gets no ID
+func TestBoltStoreSave_Failed(t *testing.T) {
+	s := createTestStore(t)
+	s.Close()
+
+	result := models.NewAnalysisResult("https://example.com")
+	if err := s.Save(result); err == nil {
+		t.Fatal("Expected saving to a closed store to fail")
+	}
+	if result.ID != "" {
+		t.Errorf("Expected no ID after a failed save, got %q", result.ID)
+	}
+}
+
+// TestBoltStoreRetention tests that saving a result deletes the results older than the retention period
+func TestBoltStoreRetention(t *testing.T) {
+	s, err := NewBoltStore(filepath.Join(t.TempDir(), "history.db"), time.Hour)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer s.Close()
+
+	expired := models.NewAnalysisResult("https://example.com")
+	expired.Timestamp = time.Now().Add(-2 * time.Hour)
+	if err := s.Save(expired); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	recent := models.NewAnalysisResult("https://example.com")
+	if err := s.Save(recent); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if _, err := s.Get(expired.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected the expired result to be deleted, got %v", err)
+	}
+	page, err := s.List(models.HistoryFilter{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if page.Total != 1 || page.Entries[0].ID != recent.ID {
+		t.Errorf("Expected only the recent result to be listed, got %+v", page)
+	}
+}
@@ -0,0 +1,31 @@
+package store
+
+import (
+	"WebAppAnalyzer/internal/models"
+	"errors"
+)
+
+var ErrNotFound = errors.New("analysis not found")
+
+// ResultStore keeps past analysis results
+type ResultStore interface {
+	// Save stores the result and assigns it a new ID
+	Save(result *models.AnalysisResult) error
+	// Get returns a stored result by ID
+	Get(id string) (*models.AnalysisResult, error)
+	// List returns the stored results matching the filter, newest first
+	List(filter models.HistoryFilter) (models.HistoryPage, error)
+	// Delete removes a stored result
+	Delete(id string) error
+	Close() error
+}
+
+// MonitorStore keeps the registered monitors so they survive restarts
+type MonitorStore interface {
+	// SaveMonitor creates or replaces a monitor
+	SaveMonitor(monitor models.Monitor) error
+	// ListMonitors returns all stored monitors
+	ListMonitors() ([]models.Monitor, error)
+	// DeleteMonitor removes a stored monitor, deleting a missing monitor is not an error
+	DeleteMonitor(id string) error
+}
@@ -0,0 +1,200 @@
+package tlsreload
+
+import (
+	"WebAppAnalyzer/config/logger"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+const defaultReloadInterval = 30 * time.Second
+
+// Reloader serves a certificate, and optionally a pool of client CAs, loaded from files. The files are checked
+// for changes periodically and reloaded, so renewed certificates are picked up without a restart. A reload that
+// fails keeps the files loaded last.
+type Reloader struct {
+	certFile     string
+	keyFile      string
+	clientCAFile string
+	interval     time.Duration
+	logger       *logger.Logger
+
+	mu          sync.RWMutex
+	certificate *tls.Certificate
+	clientCAs   *x509.CertPool
+	// versions holds the modification time and size of every file as last read, loaded or not
+	versions map[string]fileVersion
+
+	stop     chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+type fileVersion struct {
+	modTime time.Time
+	size    int64
+}
+
+// NewReloader loads the certificate and key, and the client CAs when clientCAFile is set, and starts checking
+// the files for changes every interval
+func NewReloader(certFile, keyFile, clientCAFile string, interval time.Duration, logger *logger.Logger) (*Reloader, error) {
+	if certFile == "" || keyFile == "" {
+		return nil, errors.New("both a certificate file and a key file are required")
+	}
+	if interval <= 0 {
+		interval = defaultReloadInterval
+	}
+
+	r := &Reloader{
+		certFile:     certFile,
+		keyFile:      keyFile,
+		clientCAFile: clientCAFile,
+		interval:     interval,
+		logger:       logger,
+		stop:         make(chan struct{}),
+	}
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+
+	r.wg.Add(1)
+	go r.watch()
+	return r, nil
+}
+
+// Config returns a TLS configuration based on base that presents the current certificate. With client CAs,
+// client certificates are verified against them when presented, and the handlers decide which routes require one.
+func (r *Reloader) Config(base *tls.Config) *tls.Config {
+	config := base.Clone()
+	config.GetCertificate = r.GetCertificate
+	config.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		r.mu.RLock()
+		defer r.mu.RUnlock()
+
+		perConn := base.Clone()
+		perConn.Certificates = []tls.Certificate{*r.certificate}
+		if r.clientCAs != nil {
+			perConn.ClientCAs = r.clientCAs
+			perConn.ClientAuth = tls.VerifyClientCertIfGiven
+		}
+		return perConn, nil
+	}
+	return config
+}
+
+// GetCertificate returns the current certificate
+func (r *Reloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.certificate, nil
+}
+
+// Close stops checking the files for changes
+func (r *Reloader) Close() {
+	r.stopOnce.Do(func() {
+		close(r.stop)
+	})
+	r.wg.Wait()
+}
+
+func (r *Reloader) watch() {
+	defer r.wg.Done()
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if !r.changed() {
+				continue
+			}
+			// A reload that fails is not retried until the files change again, such as when a renewed
+			// certificate is written before its key
+			if err := r.load(); err != nil {
+				r.logger.Error("Failed to reload the TLS certificate, keeping the current one", err)
+				continue
+			}
+			r.logger.WithField("cert_file", r.certFile).Info("TLS certificate reloaded")
+		case <-r.stop:
+			return
+		}
+	}
+}
+
+// changed reports whether any of the files was modified since it was last loaded
+func (r *Reloader) changed() bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, name := range r.files() {
+		version, err := stat(name)
+		if err != nil {
+			// A file being replaced may be missing for a moment, it is checked again on the next tick
+			continue
+		}
+		if version != r.versions[name] {
+			return true
+		}
+	}
+	return false
+}
+
+// load reads all the files and replaces the served certificate and client CAs once every one of them is valid.
+// The versions of the files are recorded even when they are not valid.
+func (r *Reloader) load() error {
+	versions := make(map[string]fileVersion)
+	for _, name := range r.files() {
+		version, err := stat(name)
+		if err != nil {
+			return err
+		}
+		versions[name] = version
+	}
+	r.mu.Lock()
+	r.versions = versions
+	r.mu.Unlock()
+
+	certificate, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load the certificate: %w", err)
+	}
+
+	var clientCAs *x509.CertPool
+	if r.clientCAFile != "" {
+		pem, err := os.ReadFile(r.clientCAFile)
+		if err != nil {
+			return fmt.Errorf("failed to read the client CA file: %w", err)
+		}
+		clientCAs = x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("no certificate found in the client CA file %s", r.clientCAFile)
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.certificate = &certificate
+	r.clientCAs = clientCAs
+	return nil
+}
+
+func (r *Reloader) files() []string {
+	files := []string{r.certFile, r.keyFile}
+	if r.clientCAFile != "" {
+		files = append(files, r.clientCAFile)
+	}
+	return files
+}
+
+func stat(name string) (fileVersion, error) {
+	info, err := os.Stat(name)
+	if err != nil {
+		return fileVersion{}, err
+	}
+	return fileVersion{modTime: info.ModTime(), size: info.Size()}, nil
+}
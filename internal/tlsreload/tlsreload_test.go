@@ -0,0 +1,208 @@
+package tlsreload
+
+import (
+	"WebAppAnalyzer/config/env"
+	"WebAppAnalyzer/config/logger"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// testCA signs the certificates of the tests
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+func newTestCA(t *testing.T) *testCA {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	return &testCA{cert: cert, key: key, pem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+// issue returns a certificate and key in PEM for the serial number, usable by servers and clients
+func (ca *testCA) issue(t *testing.T, serial int64) ([]byte, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: "127.0.0.1"},
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+func writeFile(t *testing.T, name string, data []byte, modTime time.Time) {
+	if err := os.WriteFile(name, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(name, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func createTestLogger() *logger.Logger {
+	return logger.NewLogger(env.Config{LogLevel: "error"})
+}
+
+// TestReload tests that a renewed certificate is picked up and that an invalid one keeps the current certificate
+func TestReload(t *testing.T) {
+	ca := newTestCA(t)
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+
+	cert, key := ca.issue(t, 10)
+	modTime := time.Now().Add(-time.Minute)
+	writeFile(t, certFile, cert, modTime)
+	writeFile(t, keyFile, key, modTime)
+
+	reloader, err := NewReloader(certFile, keyFile, "", 10*time.Millisecond, createTestLogger())
+	if err != nil {
+		t.Fatalf("Expected the certificate to load, got %v", err)
+	}
+	defer reloader.Close()
+
+	serial := func() int64 {
+		current, _ := reloader.GetCertificate(nil)
+		leaf, err := x509.ParseCertificate(current.Certificate[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		return leaf.SerialNumber.Int64()
+	}
+	if serial() != 10 {
+		t.Fatalf("Expected the certificate with serial 10, got %d", serial())
+	}
+
+	// A certificate that does not match the key is not loaded
+	renewed, renewedKey := ca.issue(t, 11)
+	writeFile(t, certFile, renewed, time.Now())
+	time.Sleep(100 * time.Millisecond)
+	if serial() != 10 {
+		t.Errorf("Expected the current certificate to be kept, got serial %d", serial())
+	}
+
+	writeFile(t, keyFile, renewedKey, time.Now())
+	deadline := time.Now().Add(2 * time.Second)
+	for serial() != 11 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if serial() != 11 {
+		t.Errorf("Expected the renewed certificate with serial 11, got %d", serial())
+	}
+
+	if _, err := NewReloader(certFile, "", "", 0, createTestLogger()); err == nil {
+		t.Error("Expected an error without a key file")
+	}
+	if _, err := NewReloader(certFile, filepath.Join(dir, "missing.key"), "", 0, createTestLogger()); err == nil {
+		t.Error("Expected an error for a missing key file")
+	}
+}
+
+// TestClientCertificates tests that client certificates signed by the client CA are verified and that clients
+// without one can still connect
+func TestClientCertificates(t *testing.T) {
+	ca := newTestCA(t)
+	dir := t.TempDir()
+	certFile, keyFile, caFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key"), filepath.Join(dir, "ca.crt")
+
+	cert, key := ca.issue(t, 20)
+	writeFile(t, certFile, cert, time.Now())
+	writeFile(t, keyFile, key, time.Now())
+	writeFile(t, caFile, ca.pem, time.Now())
+
+	reloader, err := NewReloader(certFile, keyFile, caFile, time.Minute, createTestLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reloader.Close()
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(r.TLS.VerifiedChains) == 0 {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(r.Proto))
+	}))
+	server.TLS = reloader.Config(&tls.Config{MinVersion: tls.VersionTLS12, NextProtos: []string{"h2", "http/1.1"}})
+	server.EnableHTTP2 = true
+	server.StartTLS()
+	defer server.Close()
+
+	roots := x509.NewCertPool()
+	roots.AppendCertsFromPEM(ca.pem)
+	client := func(certificates ...tls.Certificate) *http.Client {
+		return &http.Client{Transport: &http.Transport{
+			TLSClientConfig:   &tls.Config{RootCAs: roots, Certificates: certificates},
+			ForceAttemptHTTP2: true,
+		}}
+	}
+
+	resp, err := client().Get(server.URL)
+	if err != nil {
+		t.Fatalf("Expected clients without a certificate to connect, got %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected no verified chain without a client certificate, got %d", resp.StatusCode)
+	}
+
+	clientCert, clientKey := ca.issue(t, 21)
+	certificate, err := tls.X509KeyPair(clientCert, clientKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err = client(certificate).Get(server.URL)
+	if err != nil {
+		t.Fatalf("Expected the client certificate to be accepted, got %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || resp.ProtoMajor != 2 {
+		t.Errorf("Expected a verified HTTP/2 request, got %d over %s", resp.StatusCode, resp.Proto)
+	}
+}
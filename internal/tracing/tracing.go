@@ -0,0 +1,86 @@
+package tracing
+
+import (
+	"WebAppAnalyzer/config/env"
+	"context"
+	"fmt"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"io"
+	"os"
+	"strings"
+)
+
+// Exporters accepted by the TRACING_EXPORTER setting
+const (
+	ExporterNone   = "none"
+	ExporterStdout = "stdout"
+	ExporterOTLP   = "otlp"
+)
+
+// ServiceName identifies the service in the exported spans
+const ServiceName = "web-analyzer"
+
+// NewTracerProvider creates the tracer provider exporting spans to the TRACING_EXPORTER, and registers it
+// globally together with the W3C trace context and baggage propagators. It returns nil when tracing is off.
+// The provider must be shut down to flush the spans still buffered.
+func NewTracerProvider(c *env.Config) (*sdktrace.TracerProvider, error) {
+	return newTracerProvider(c, os.Stdout)
+}
+
+func newTracerProvider(c *env.Config, stdout io.Writer) (*sdktrace.TracerProvider, error) {
+	exporter, err := newExporter(c, stdout)
+	if err != nil || exporter == nil {
+		return nil, err
+	}
+
+	res, err := resource.New(context.Background(),
+		resource.WithFromEnv(),
+		resource.WithTelemetrySDK(),
+		resource.WithAttributes(semconv.ServiceName(ServiceName)),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the trace resource: %w", err)
+	}
+
+	ratio := c.TracingSampleRatio
+	if ratio <= 0 {
+		ratio = 1
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		// Requests arriving with a sampled trace context are traced whatever the ratio, so traces started by
+		// callers stay whole
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio))),
+	)
+
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	return provider, nil
+}
+
+// newExporter creates the span exporter of the TRACING_EXPORTER setting, nil when tracing is off
+func newExporter(c *env.Config, stdout io.Writer) (sdktrace.SpanExporter, error) {
+	switch strings.ToLower(strings.TrimSpace(c.TracingExporter)) {
+	case "", ExporterNone:
+		return nil, nil
+	case ExporterStdout:
+		return stdouttrace.New(stdouttrace.WithWriter(stdout))
+	case ExporterOTLP:
+		var options []otlptracehttp.Option
+		// Without an endpoint the exporter falls back to the OTEL_EXPORTER_OTLP_* environment variables and
+		// then to https://localhost:4318/v1/traces
+		if c.TracingOTLPEndpoint != "" {
+			options = append(options, otlptracehttp.WithEndpointURL(c.TracingOTLPEndpoint))
+		}
+		return otlptracehttp.New(context.Background(), options...)
+	default:
+		return nil, fmt.Errorf("unknown tracing exporter %q, expected %s, %s or %s", c.TracingExporter, ExporterNone, ExporterStdout, ExporterOTLP)
+	}
+}
@@ -0,0 +1,61 @@
+package tracing
+
+import (
+	"WebAppAnalyzer/config/env"
+	"bytes"
+	"context"
+	"go.opentelemetry.io/otel"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// TestExporters tests that spans reach the stdout exporter and an OTLP collector, and that tracing is off by default
+func TestExporters(t *testing.T) {
+	provider, err := newTracerProvider(&env.Config{}, nil)
+	if err != nil || provider != nil {
+		t.Errorf("Expected tracing to be off without an exporter, got %v, %v", provider, err)
+	}
+	if _, err := newTracerProvider(&env.Config{TracingExporter: "jaeger"}, nil); err == nil {
+		t.Error("Expected an error for an unknown exporter")
+	}
+
+	var stdout bytes.Buffer
+	provider, err = newTracerProvider(&env.Config{TracingExporter: ExporterStdout}, &stdout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, span := otel.Tracer("test").Start(context.Background(), "stdout-span")
+	span.End()
+	if err := provider.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(stdout.String(), `"Name":"stdout-span"`) || !strings.Contains(stdout.String(), ServiceName) {
+		t.Errorf("Expected the span to be written to stdout, got %s", stdout.String())
+	}
+
+	var exports atomic.Int32
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost && r.URL.Path == "/v1/traces" && r.Header.Get("Content-Type") == "application/x-protobuf" {
+			exports.Add(1)
+		}
+		w.Header().Set("Content-Type", "application/x-protobuf")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer collector.Close()
+
+	provider, err = newTracerProvider(&env.Config{TracingExporter: ExporterOTLP, TracingOTLPEndpoint: collector.URL + "/v1/traces"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, span = provider.Tracer("test").Start(context.Background(), "otlp-span")
+	span.End()
+	if err := provider.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if exports.Load() == 0 {
+		t.Error("Expected the spans to be exported to the collector")
+	}
+}
@@ -0,0 +1,232 @@
+package validator
+
+import (
+	"WebAppAnalyzer/config/env"
+	"WebAppAnalyzer/internal/netguard"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"strings"
+	"syscall"
+	"time"
+)
+
+var (
+	ErrDomainDenied     = &ValidationError{Message: "The domain is on the denylist"}
+	ErrDomainNotAllowed = &ValidationError{Message: "The domain is not on the allowlist"}
+)
+
+// DomainPolicy overrides the request settings for the hosts matching its pattern
+type DomainPolicy struct {
+	Pattern        string `json:"pattern"`
+	TimeoutSeconds int    `json:"timeout_seconds,omitempty"`
+	UserAgent      string `json:"user_agent,omitempty"`
+	// CheckLinks turns off the link checks of the matching hosts when false
+	CheckLinks *bool `json:"check_links,omitempty"`
+
+	host hostPattern
+}
+
+// Timeout returns the request timeout of the policy, zero when it does not override it
+func (p DomainPolicy) Timeout() time.Duration {
+	return time.Duration(p.TimeoutSeconds) * time.Second
+}
+
+// LinkChecksAllowed reports whether links to the matching hosts may be checked
+func (p DomainPolicy) LinkChecksAllowed() bool {
+	return p.CheckLinks == nil || *p.CheckLinks
+}
+
+// hostPattern matches hosts by exact name, by subdomain with a leading "*." or by CIDR range for IP addresses
+type hostPattern struct {
+	name     string
+	wildcard bool
+	prefix   netip.Prefix
+}
+
+// NewURLValidatorWithPolicies creates a validator applying the DOMAIN_ALLOWLIST and DOMAIN_DENYLIST patterns and the
+// DOMAIN_POLICIES overrides. Invalid patterns and policies are left out and reported in the returned error.
+func NewURLValidatorWithPolicies(c *env.Config) (*URLValidator, error) {
+	v := NewURLValidator()
+	var errs []error
+
+	allowlist, err := parseHostPatterns(c.DomainAllowlist)
+	errs = append(errs, err)
+	v.allowlist = allowlist
+
+	denylist, err := parseHostPatterns(c.DomainDenylist)
+	errs = append(errs, err)
+	v.denylist = denylist
+
+	if strings.TrimSpace(c.DomainPolicies) != "" {
+		var policies []DomainPolicy
+		if err := json.Unmarshal([]byte(c.DomainPolicies), &policies); err != nil {
+			errs = append(errs, fmt.Errorf("invalid domain policies: %w", err))
+		}
+		for _, policy := range policies {
+			host, err := parseHostPattern(policy.Pattern)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("invalid domain policy pattern %q: %w", policy.Pattern, err))
+				continue
+			}
+			policy.host = host
+			v.policies = append(v.policies, policy)
+		}
+	}
+
+	return v, errors.Join(errs...)
+}
+
+// CheckHost checks a host against the denylist and the allowlist. A host name matching no name pattern of the
+// allowlist passes when the allowlist has CIDR ranges, its addresses are then checked by CheckAddress once resolved.
+func (v *URLValidator) CheckHost(host string) error {
+	host = normalizeHost(host)
+	for _, pattern := range v.denylist {
+		if pattern.matches(host) {
+			return ErrDomainDenied
+		}
+	}
+	if len(v.allowlist) == 0 {
+		return nil
+	}
+
+	_, err := netip.ParseAddr(host)
+	isAddr := err == nil
+	for _, pattern := range v.allowlist {
+		if pattern.matches(host) || (!isAddr && pattern.prefix.IsValid()) {
+			return nil
+		}
+	}
+	return ErrDomainNotAllowed
+}
+
+// CheckAddress checks an address a host resolved to against the CIDR ranges of the denylist and the allowlist
+func (v *URLValidator) CheckAddress(host string, addr netip.Addr) error {
+	host = normalizeHost(host)
+	addr = addr.Unmap()
+	for _, pattern := range v.denylist {
+		if pattern.prefix.IsValid() && pattern.prefix.Contains(addr) {
+			return ErrDomainDenied
+		}
+	}
+	if len(v.allowlist) == 0 {
+		return nil
+	}
+
+	for _, pattern := range v.allowlist {
+		if pattern.matches(host) || (pattern.prefix.IsValid() && pattern.prefix.Contains(addr)) {
+			return nil
+		}
+	}
+	return ErrDomainNotAllowed
+}
+
+// DialContext returns the dial function of clients sending requests to user-supplied URLs. Host names are checked
+// against the domain lists before resolution and the resolved addresses against the SSRF guard and the CIDR
+// ranges of the domain lists, so redirects cannot lead to a denied host either.
+func (v *URLValidator) DialContext(guard *netguard.Guard) func(ctx context.Context, network, address string) (net.Conn, error) {
+	return func(ctx context.Context, network, address string) (net.Conn, error) {
+		host, _, err := net.SplitHostPort(address)
+		if err != nil {
+			return nil, err
+		}
+		if err := v.CheckHost(host); err != nil {
+			return nil, fmt.Errorf("%s: %w", host, err)
+		}
+
+		dialer := guard.Dialer(30 * time.Second)
+		dialer.Control = func(network, resolved string, c syscall.RawConn) error {
+			if err := guard.Control(network, resolved, c); err != nil {
+				return err
+			}
+			addrPort, err := netip.ParseAddrPort(resolved)
+			if err != nil {
+				return err
+			}
+			if err := v.CheckAddress(host, addrPort.Addr()); err != nil {
+				return fmt.Errorf("%s resolves to %s: %w", host, addrPort.Addr(), err)
+			}
+			return nil
+		}
+		return dialer.DialContext(ctx, network, address)
+	}
+}
+
+// Policy returns the first domain policy whose pattern matches the host
+func (v *URLValidator) Policy(host string) (DomainPolicy, bool) {
+	host = normalizeHost(host)
+	for _, policy := range v.policies {
+		if policy.host.matches(host) {
+			return policy, true
+		}
+	}
+	return DomainPolicy{}, false
+}
+
+func parseHostPatterns(list string) ([]hostPattern, error) {
+	patterns := make([]hostPattern, 0)
+	var errs []error
+	for _, entry := range strings.Split(list, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		pattern, err := parseHostPattern(entry)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("invalid domain pattern %q: %w", entry, err))
+			continue
+		}
+		patterns = append(patterns, pattern)
+	}
+	return patterns, errors.Join(errs...)
+}
+
+func parseHostPattern(entry string) (hostPattern, error) {
+	entry = normalizeHost(entry)
+	if entry == "" {
+		return hostPattern{}, errors.New("empty pattern")
+	}
+
+	if strings.Contains(entry, "/") {
+		prefix, err := netip.ParsePrefix(entry)
+		if err != nil {
+			return hostPattern{}, err
+		}
+		return hostPattern{prefix: prefix.Masked()}, nil
+	}
+	if addr, err := netip.ParseAddr(entry); err == nil {
+		addr = addr.Unmap()
+		return hostPattern{prefix: netip.PrefixFrom(addr, addr.BitLen())}, nil
+	}
+
+	wildcard := strings.HasPrefix(entry, "*.")
+	name := strings.TrimPrefix(entry, "*.")
+	if strings.ContainsAny(name, "*/:@ ") {
+		return hostPattern{}, errors.New("expected a host, a *.domain wildcard or a CIDR range")
+	}
+	return hostPattern{name: name, wildcard: wildcard}, nil
+}
+
+// matches reports whether the normalized host matches the pattern. Wildcards match subdomains at any depth but
+// not the domain itself.
+func (p hostPattern) matches(host string) bool {
+	if p.prefix.IsValid() {
+		addr, err := netip.ParseAddr(host)
+		return err == nil && p.prefix.Contains(addr.Unmap())
+	}
+	if p.wildcard {
+		return strings.HasSuffix(host, "."+p.name)
+	}
+	return host == p.name
+}
+
+// normalizeHost lower-cases a host and strips the brackets of IPv6 addresses and the trailing dot of fully
+// qualified names
+func normalizeHost(host string) string {
+	host = strings.ToLower(strings.TrimSpace(host))
+	host = strings.TrimSuffix(strings.TrimPrefix(host, "["), "]")
+	return strings.TrimSuffix(host, ".")
+}
@@ -0,0 +1,97 @@
+package validator
+
+import (
+	"WebAppAnalyzer/config/env"
+	"errors"
+	"net/netip"
+	"testing"
+)
+
+// TestCheckHost tests the exact, wildcard and CIDR patterns of the domain allowlist and denylist
+func TestCheckHost(t *testing.T) {
+	v, err := NewURLValidatorWithPolicies(&env.Config{
+		DomainAllowlist: "example.com, *.example.com, 203.0.113.0/24",
+		DomainDenylist:  "admin.example.com, *.internal.example.com, 203.0.113.7",
+	})
+	if err != nil {
+		t.Fatalf("Expected valid patterns, got %v", err)
+	}
+
+	tests := []struct {
+		host     string
+		expected error
+	}{
+		{"example.com", nil},
+		{"EXAMPLE.com.", nil},
+		{"docs.example.com", nil},
+		{"a.b.example.com", nil},
+		{"admin.example.com", ErrDomainDenied},
+		{"db.internal.example.com", ErrDomainDenied},
+		{"203.0.113.10", nil},
+		{"203.0.113.7", ErrDomainDenied},
+		{"198.51.100.1", ErrDomainNotAllowed},
+		// Names outside the name patterns are checked once resolved because the allowlist has a CIDR range
+		{"example.org", nil},
+	}
+
+	for _, tt := range tests {
+		if err := v.CheckHost(tt.host); !errors.Is(err, tt.expected) {
+			t.Errorf("CheckHost(%s) = %v, expected %v", tt.host, err, tt.expected)
+		}
+	}
+
+	if err := v.CheckAddress("example.org", netip.MustParseAddr("198.51.100.1")); !errors.Is(err, ErrDomainNotAllowed) {
+		t.Errorf("Expected an address outside the allowlist to be refused, got %v", err)
+	}
+	if err := v.CheckAddress("example.org", netip.MustParseAddr("203.0.113.10")); err != nil {
+		t.Errorf("Expected an address in the allowlist to be accepted, got %v", err)
+	}
+	if err := v.CheckAddress("docs.example.com", netip.MustParseAddr("203.0.113.7")); !errors.Is(err, ErrDomainDenied) {
+		t.Errorf("Expected a denied address to be refused, got %v", err)
+	}
+
+	// Without a CIDR range in the allowlist, unlisted names are refused right away
+	v, _ = NewURLValidatorWithPolicies(&env.Config{DomainAllowlist: "*.example.com"})
+	if err := v.CheckHost("example.org"); !errors.Is(err, ErrDomainNotAllowed) {
+		t.Errorf("Expected an unlisted host to be refused, got %v", err)
+	}
+	if err := v.CheckHost("example.com"); !errors.Is(err, ErrDomainNotAllowed) {
+		t.Errorf("Expected a wildcard not to match the domain itself, got %v", err)
+	}
+	if _, err := v.ValidateURL("https://docs.example.com/guide"); err != nil {
+		t.Errorf("Expected an allowlisted URL to be valid, got %v", err)
+	}
+	if _, err := v.ValidateURL("https://example.org"); !errors.Is(err, ErrDomainNotAllowed) {
+		t.Errorf("Expected an unlisted URL to be refused, got %v", err)
+	}
+}
+
+// TestPolicy tests that the first policy matching a host applies and that invalid entries are reported
+func TestPolicy(t *testing.T) {
+	v, err := NewURLValidatorWithPolicies(&env.Config{
+		DomainPolicies: `[
+			{"pattern": "slow.example.com", "timeout_seconds": 60},
+			{"pattern": "*.example.com", "user_agent": "InternalBot/1.0", "check_links": false},
+			{"pattern": "bad/pattern"}
+		]`,
+	})
+	if err == nil {
+		t.Error("Expected an error for the invalid policy pattern")
+	}
+
+	policy, ok := v.Policy("slow.example.com")
+	if !ok || policy.Timeout().Seconds() != 60 || policy.UserAgent != "" || !policy.LinkChecksAllowed() {
+		t.Errorf("Expected the timeout policy, got %+v", policy)
+	}
+	policy, ok = v.Policy("docs.example.com")
+	if !ok || policy.UserAgent != "InternalBot/1.0" || policy.LinkChecksAllowed() {
+		t.Errorf("Expected the wildcard policy, got %+v", policy)
+	}
+	if _, ok := v.Policy("example.org"); ok {
+		t.Error("Expected no policy for an unmatched host")
+	}
+
+	if _, err := NewURLValidatorWithPolicies(&env.Config{DomainDenylist: "*.*.example.com, http://example.com"}); err == nil {
+		t.Error("Expected an error for the invalid patterns")
+	}
+}
@@ -8,6 +8,10 @@ import (
 
 type URLValidator struct {
 	urlRegex *regexp.Regexp
+	// allowlist and denylist restrict the hosts that may be analyzed, policies override request settings per host
+	allowlist []hostPattern
+	denylist  []hostPattern
+	policies  []DomainPolicy
 }
 
 func NewURLValidator() *URLValidator {
@@ -45,6 +49,9 @@ func (v *URLValidator) ValidateURL(input string) (string, error) {
 	if !v.isValidHost(parsedURL.Host) {
 		return "", ErrInvalidHost
 	}
+	if err := v.CheckHost(parsedURL.Hostname()); err != nil {
+		return "", err
+	}
 
 	return input, nil
 }
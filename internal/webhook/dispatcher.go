@@ -0,0 +1,242 @@
+package webhook
+
+import (
+	"WebAppAnalyzer/config/env"
+	"WebAppAnalyzer/config/logger"
+	"WebAppAnalyzer/internal/models"
+	"WebAppAnalyzer/internal/netguard"
+	"WebAppAnalyzer/internal/validator"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	defaultMaxAttempts  = 5
+	defaultRetryBackoff = 2 * time.Second
+	maxRetryBackoff     = 5 * time.Minute
+	// deliveryLogSize is the number of deliveries kept for the delivery log, the oldest are forgotten first
+	deliveryLogSize  = 1000
+	defaultListLimit = 50
+)
+
+var ErrDeliveryNotFound = errors.New("webhook delivery not found")
+
+// Dispatcher delivers webhook notifications in the background. Failed attempts are retried with exponential
+// backoff, and every delivery is kept in a bounded in-memory log with the outcome of its attempts.
+type Dispatcher struct {
+	sender      *Sender
+	logger      *logger.Logger
+	maxAttempts int
+	backoff     time.Duration
+
+	mu         sync.Mutex
+	deliveries map[string]*models.WebhookDelivery
+	// order holds the delivery IDs oldest first
+	order []string
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewDispatcher creates a dispatcher that makes up to WEBHOOK_MAX_ATTEMPTS attempts per delivery, waiting
+// WEBHOOK_RETRY_BACKOFF_SECONDS before the first retry and twice as long before every next one.
+func NewDispatcher(logger *logger.Logger, c *env.Config) *Dispatcher {
+	maxAttempts := c.WebhookMaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = defaultMaxAttempts
+	}
+	backoff := time.Duration(c.WebhookRetryBackoffInSeconds) * time.Second
+	if backoff <= 0 {
+		backoff = defaultRetryBackoff
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Dispatcher{
+		sender:      NewSender(c),
+		logger:      logger,
+		maxAttempts: maxAttempts,
+		backoff:     backoff,
+		deliveries:  make(map[string]*models.WebhookDelivery),
+		ctx:         ctx,
+		cancel:      cancel,
+	}
+}
+
+// Deliver posts the payload as JSON to the URL in the background and returns the new delivery
+func (d *Dispatcher) Deliver(event, resourceID, url string, payload any) models.WebhookDelivery {
+	delivery := &models.WebhookDelivery{
+		ID:         newDeliveryID(),
+		Event:      event,
+		ResourceID: resourceID,
+		URL:        url,
+		Status:     models.DeliveryStatusPending,
+		Attempts:   make([]models.WebhookAttempt, 0),
+		CreatedAt:  time.Now(),
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.deliveries[delivery.ID] = delivery
+	d.order = append(d.order, delivery.ID)
+	if len(d.order) > deliveryLogSize {
+		delete(d.deliveries, d.order[0])
+		d.order = d.order[1:]
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		d.complete(delivery, models.DeliveryStatusFailed)
+		delivery.Attempts = append(delivery.Attempts, models.WebhookAttempt{Error: err.Error(), At: time.Now()})
+		return d.snapshot(delivery)
+	}
+
+	d.wg.Add(1)
+	go d.deliver(delivery, body)
+
+	return d.snapshot(delivery)
+}
+
+// ValidateURL checks that a webhook URL can be posted to
+func (d *Dispatcher) ValidateURL(url string) error {
+	return d.sender.ValidateURL(url)
+}
+
+// Get returns a delivery from the log
+func (d *Dispatcher) Get(id string) (models.WebhookDelivery, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	delivery, ok := d.deliveries[id]
+	if !ok {
+		return models.WebhookDelivery{}, ErrDeliveryNotFound
+	}
+	return d.snapshot(delivery), nil
+}
+
+// List returns the deliveries in the log matching the filter, newest first
+func (d *Dispatcher) List(filter models.DeliveryFilter) []models.WebhookDelivery {
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = defaultListLimit
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	deliveries := make([]models.WebhookDelivery, 0)
+	for i := len(d.order) - 1; i >= 0 && len(deliveries) < limit; i-- {
+		delivery := d.deliveries[d.order[i]]
+		if filter.Matches(*delivery) {
+			deliveries = append(deliveries, d.snapshot(delivery))
+		}
+	}
+	return deliveries
+}
+
+// Close abandons the pending retries and waits for the attempts in progress to stop
+func (d *Dispatcher) Close() {
+	d.cancel()
+	d.wg.Wait()
+}
+
+// deliver makes attempts until the webhook accepts the delivery, the error is permanent or the attempts run out
+func (d *Dispatcher) deliver(delivery *models.WebhookDelivery, body []byte) {
+	defer d.wg.Done()
+
+	for attempt := 1; ; attempt++ {
+		startTime := time.Now()
+		statusCode, err := d.sender.Send(d.ctx, delivery.URL, delivery.Event, delivery.ID, body)
+		result := models.WebhookAttempt{
+			StatusCode: statusCode,
+			DurationMs: time.Since(startTime).Milliseconds(),
+			At:         startTime,
+		}
+		if err != nil {
+			result.Error = err.Error()
+		}
+
+		d.mu.Lock()
+		delivery.Attempts = append(delivery.Attempts, result)
+		if err == nil {
+			d.complete(delivery, models.DeliveryStatusDelivered)
+			d.mu.Unlock()
+			return
+		}
+		if attempt >= d.maxAttempts || !retryable(statusCode, err) || d.ctx.Err() != nil {
+			d.complete(delivery, models.DeliveryStatusFailed)
+			d.mu.Unlock()
+			d.logger.WithField("delivery_id", delivery.ID).
+				WithField("url", delivery.URL).
+				WithField("attempts", attempt).
+				Error("Webhook delivery failed", err)
+			return
+		}
+		wait := d.retryBackoff(attempt)
+		nextAttemptAt := time.Now().Add(wait)
+		delivery.NextAttemptAt = &nextAttemptAt
+		d.mu.Unlock()
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-d.ctx.Done():
+			timer.Stop()
+			d.mu.Lock()
+			d.complete(delivery, models.DeliveryStatusFailed)
+			d.mu.Unlock()
+			return
+		}
+	}
+}
+
+// retryBackoff returns the wait before the retry that follows the given attempt, doubling every attempt
+func (d *Dispatcher) retryBackoff(attempt int) time.Duration {
+	wait := d.backoff
+	for i := 1; i < attempt && wait < maxRetryBackoff; i++ {
+		wait *= 2
+	}
+	return min(wait, maxRetryBackoff)
+}
+
+// complete records the final status of a delivery. Callers must hold the dispatcher lock.
+func (d *Dispatcher) complete(delivery *models.WebhookDelivery, status string) {
+	completedAt := time.Now()
+	delivery.Status = status
+	delivery.CompletedAt = &completedAt
+	delivery.NextAttemptAt = nil
+}
+
+// snapshot copies a delivery for callers outside the lock. Callers must hold the dispatcher lock.
+func (d *Dispatcher) snapshot(delivery *models.WebhookDelivery) models.WebhookDelivery {
+	snapshot := *delivery
+	snapshot.Attempts = append(make([]models.WebhookAttempt, 0, len(delivery.Attempts)), delivery.Attempts...)
+	return snapshot
+}
+
+// retryable reports whether a failed attempt may succeed later. Network errors, timeouts, rate limiting and
+// server errors are retried, other client errors mean the receiver rejects the request as it is. Addresses the
+// SSRF guard blocks, hosts the domain lists refuse and redirects fail the same way on every attempt.
+func retryable(statusCode int, err error) bool {
+	if errors.Is(err, netguard.ErrBlockedAddress) || errors.Is(err, validator.ErrDomainDenied) ||
+		errors.Is(err, validator.ErrDomainNotAllowed) || errors.Is(err, ErrNoSecret) {
+		return false
+	}
+	return statusCode == 0 ||
+		statusCode == http.StatusRequestTimeout ||
+		statusCode == http.StatusTooManyRequests ||
+		statusCode >= 500
+}
+
+func newDeliveryID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,146 @@
+package webhook
+
+import (
+	"WebAppAnalyzer/config/env"
+	"WebAppAnalyzer/config/logger"
+	"WebAppAnalyzer/internal/models"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func createTestDispatcher(maxAttempts int) *Dispatcher {
+	config := &env.Config{LogLevel: "debug", WebhookMaxAttempts: maxAttempts, WebhookSecret: "s3cret", SSRFAllowlist: "127.0.0.1"}
+	d := NewDispatcher(logger.NewLogger(*config), config)
+	d.backoff = time.Millisecond
+	return d
+}
+
+// waitForDelivery polls the delivery until it is no longer pending
+func waitForDelivery(t *testing.T, d *Dispatcher, id string) models.WebhookDelivery {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		delivery, err := d.Get(id)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if delivery.Status != models.DeliveryStatusPending {
+			return delivery
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected the delivery to finish, got %+v", delivery)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// TestDispatcherRetries tests that failed attempts are retried until the webhook accepts the delivery
+func TestDispatcherRetries(t *testing.T) {
+	var requests atomic.Int32
+	var deliveryIDs []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		deliveryIDs = append(deliveryIDs, r.Header.Get(DeliveryHeader))
+		if requests.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	d := createTestDispatcher(5)
+	defer d.Close()
+
+	created := d.Deliver(models.WebhookEventAnalysisCompleted, "job1", server.URL, map[string]string{"url": "https://example.com"})
+	if created.Status != models.DeliveryStatusPending {
+		t.Errorf("Expected a pending delivery, got %s", created.Status)
+	}
+
+	delivery := waitForDelivery(t, d, created.ID)
+	if delivery.Status != models.DeliveryStatusDelivered || len(delivery.Attempts) != 3 {
+		t.Fatalf("Expected delivery on the third attempt, got %+v", delivery)
+	}
+	if delivery.Attempts[0].StatusCode != http.StatusServiceUnavailable || delivery.Attempts[0].Error == "" {
+		t.Errorf("Expected the first attempt to record the failure, got %+v", delivery.Attempts[0])
+	}
+	for _, id := range deliveryIDs {
+		if id != created.ID {
+			t.Errorf("Expected every attempt to carry the delivery ID %s, got %s", created.ID, id)
+		}
+	}
+}
+
+// TestDispatcherGivesUp tests that client errors are not retried and that attempts run out
+func TestDispatcherGivesUp(t *testing.T) {
+	status := http.StatusBadRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	d := createTestDispatcher(3)
+	defer d.Close()
+
+	rejected := waitForDelivery(t, d, d.Deliver(models.WebhookEventAnalysisCompleted, "job1", server.URL, "{}").ID)
+	if rejected.Status != models.DeliveryStatusFailed || len(rejected.Attempts) != 1 {
+		t.Errorf("Expected a rejected delivery not to be retried, got %+v", rejected)
+	}
+
+	status = http.StatusBadGateway
+	exhausted := waitForDelivery(t, d, d.Deliver(models.WebhookEventMonitorAlert, "monitor1", server.URL, "{}").ID)
+	if exhausted.Status != models.DeliveryStatusFailed || len(exhausted.Attempts) != 3 {
+		t.Errorf("Expected 3 attempts, got %+v", exhausted)
+	}
+
+	failed := d.List(models.DeliveryFilter{Status: models.DeliveryStatusFailed})
+	if len(failed) != 2 || failed[0].ID != exhausted.ID {
+		t.Errorf("Expected both deliveries listed newest first, got %+v", failed)
+	}
+	if byResource := d.List(models.DeliveryFilter{ResourceID: "job1"}); len(byResource) != 1 || byResource[0].ID != rejected.ID {
+		t.Errorf("Expected the job delivery only, got %+v", byResource)
+	}
+}
+
+// TestDispatcherRefusedURLs tests that deliveries to addresses the SSRF guard blocks and to denied hosts fail
+// without retries
+func TestDispatcherRefusedURLs(t *testing.T) {
+	var received atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received.Add(1)
+	}))
+	defer server.Close()
+
+	config := &env.Config{LogLevel: "debug", WebhookMaxAttempts: 3, WebhookSecret: "s3cret", DomainDenylist: "denied.example.com"}
+	d := NewDispatcher(logger.NewLogger(*config), config)
+	d.backoff = time.Millisecond
+	defer d.Close()
+
+	for _, url := range []string{server.URL, "https://denied.example.com/hook"} {
+		delivery := waitForDelivery(t, d, d.Deliver(models.WebhookEventAnalysisCompleted, "job1", url, "{}").ID)
+		if delivery.Status != models.DeliveryStatusFailed || len(delivery.Attempts) != 1 {
+			t.Errorf("Expected the delivery to %s to fail on the first attempt, got %+v", url, delivery)
+		}
+	}
+	if received.Load() != 0 {
+		t.Error("Expected no request to reach the loopback server")
+	}
+}
+
+// TestRetryBackoff tests that the wait doubles after every attempt up to the cap
+func TestRetryBackoff(t *testing.T) {
+	d := &Dispatcher{backoff: 2 * time.Second}
+
+	expected := []time.Duration{2 * time.Second, 4 * time.Second, 8 * time.Second, 16 * time.Second}
+	for i, wait := range expected {
+		if backoff := d.retryBackoff(i + 1); backoff != wait {
+			t.Errorf("Expected %v after attempt %d, got %v", wait, i+1, backoff)
+		}
+	}
+	if backoff := d.retryBackoff(20); backoff != maxRetryBackoff {
+		t.Errorf("Expected the backoff to be capped at %v, got %v", maxRetryBackoff, backoff)
+	}
+}
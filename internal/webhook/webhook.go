@@ -0,0 +1,135 @@
+package webhook
+
+import (
+	"WebAppAnalyzer/config/env"
+	"WebAppAnalyzer/internal/netguard"
+	"WebAppAnalyzer/internal/validator"
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"time"
+)
+
+// Headers sent with every webhook request. Receivers verify the signature by computing the HMAC-SHA256 of the
+// raw request body with the shared WEBHOOK_SECRET, and deduplicate retries by the delivery ID.
+const (
+	SignatureHeader = "X-Signature-256"
+	EventHeader     = "X-Webhook-Event"
+	DeliveryHeader  = "X-Webhook-Delivery"
+)
+
+const defaultWebhookTimeout = 10 * time.Second
+
+var (
+	ErrInvalidURL = errors.New("the webhook must be an http or https URL")
+	ErrNoSecret   = errors.New("webhooks are disabled until WEBHOOK_SECRET is set")
+)
+
+// Sender posts signed JSON notifications to webhook URLs
+type Sender struct {
+	client    *http.Client
+	secret    []byte
+	guard     *netguard.Guard
+	validator *validator.URLValidator
+}
+
+// NewSender creates a sender whose requests time out after WEBHOOK_TIMEOUT_SECONDS and are signed with
+// WEBHOOK_SECRET. Nothing is sent when no secret is configured, receivers could not tell the requests from
+// forged ones. Webhook URLs are user-supplied, so like analyzed pages they may only lead to hosts the domain lists
+// allow and to addresses the SSRF guard allows, and redirects are not followed.
+func NewSender(c *env.Config) *Sender {
+	timeout := time.Duration(c.WebhookTimeoutInSeconds) * time.Second
+	if timeout <= 0 {
+		timeout = defaultWebhookTimeout
+	}
+	// Invalid entries are left out here, the analyzer reports them at startup
+	guard, _ := netguard.NewGuard(c.SSRFAllowlist)
+	urlValidator, _ := validator.NewURLValidatorWithPolicies(c)
+
+	return &Sender{
+		client: &http.Client{
+			Timeout: timeout,
+			Transport: &http.Transport{
+				DialContext:     urlValidator.DialContext(guard),
+				IdleConnTimeout: 90 * time.Second,
+			},
+			// The redirect response fails the attempt, so the payload never reaches a URL that was not validated
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		secret:    []byte(c.WebhookSecret),
+		guard:     guard,
+		validator: urlValidator,
+	}
+}
+
+// ValidateURL checks that a webhook URL can be posted to: an http or https URL whose host the domain lists allow
+// and, for IP addresses, the SSRF guard too. Host names are checked against the guard once resolved when sending.
+func (s *Sender) ValidateURL(raw string) error {
+	if len(s.secret) == 0 {
+		return ErrNoSecret
+	}
+	parsed, err := url.Parse(raw)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return ErrInvalidURL
+	}
+
+	host := parsed.Hostname()
+	if err := s.validator.CheckHost(host); err != nil {
+		return err
+	}
+	if addr, err := netip.ParseAddr(host); err == nil {
+		if s.guard.Blocked(addr) {
+			return fmt.Errorf("%w: %s", netguard.ErrBlockedAddress, addr)
+		}
+		if err := s.validator.CheckAddress(host, addr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Send posts the JSON body to the URL once and returns the response status code. Any response other than 2xx
+// is an error.
+func (s *Sender) Send(ctx context.Context, url, event, deliveryID string, body []byte) (int, error) {
+	if len(s.secret) == 0 {
+		return 0, ErrNoSecret
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "WebPageAnalyzer/1.0")
+	req.Header.Set(EventHeader, event)
+	req.Header.Set(DeliveryHeader, deliveryID)
+	req.Header.Set(SignatureHeader, Sign(s.secret, body))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("webhook responded with HTTP %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// Sign returns the signature header value of a body, "sha256=" followed by the hex encoded HMAC-SHA256
+func Sign(secret, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
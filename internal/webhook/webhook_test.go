@@ -0,0 +1,116 @@
+package webhook
+
+import (
+	"WebAppAnalyzer/config/env"
+	"WebAppAnalyzer/internal/netguard"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+// TestSend tests that the body is posted as signed JSON and that error responses fail the attempt
+func TestSend(t *testing.T) {
+	status := http.StatusNoContent
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("Expected a JSON POST, got %s %s", r.Method, r.Header.Get("Content-Type"))
+		}
+		if r.Header.Get(EventHeader) != "analysis.completed" || r.Header.Get(DeliveryHeader) != "d1" {
+			t.Errorf("Expected the event and delivery headers, got %v", r.Header)
+		}
+		if signature := r.Header.Get(SignatureHeader); signature != Sign([]byte("s3cret"), body) {
+			t.Errorf("Expected the body to be signed with the secret, got %q", signature)
+		}
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	sender := NewSender(&env.Config{WebhookSecret: "s3cret", SSRFAllowlist: "127.0.0.1"})
+	body := []byte(`{"url":"https://example.com"}`)
+
+	statusCode, err := sender.Send(context.Background(), server.URL, "analysis.completed", "d1", body)
+	if err != nil || statusCode != http.StatusNoContent {
+		t.Fatalf("Expected HTTP 204 without error, got %d %v", statusCode, err)
+	}
+
+	status = http.StatusInternalServerError
+	if statusCode, err := sender.Send(context.Background(), server.URL, "analysis.completed", "d1", body); err == nil || statusCode != http.StatusInternalServerError {
+		t.Errorf("Expected an error for an HTTP 500 response, got %d %v", statusCode, err)
+	}
+}
+
+// TestSign tests the signature against a known HMAC-SHA256
+func TestSign(t *testing.T) {
+	expected := "sha256=f7bc83f430538424b13298e6aa6fb143ef4d59a14946175997479dbc2d1a3cd8"
+	if signature := Sign([]byte("key"), []byte("The quick brown fox jumps over the lazy dog")); signature != expected {
+		t.Errorf("Expected %s, got %s", expected, signature)
+	}
+}
+
+// TestValidateURL tests which webhook URLs are accepted
+func TestValidateURL(t *testing.T) {
+	sender := NewSender(&env.Config{WebhookSecret: "s3cret", DomainDenylist: "*.internal.example.com", SSRFAllowlist: "10.1.2.3"})
+	for _, valid := range []string{"https://hooks.example.com/a?token=1", "http://hooks.example.com:9000/hook", "http://10.1.2.3/hook"} {
+		if err := sender.ValidateURL(valid); err != nil {
+			t.Errorf("Expected %s to be valid, got %v", valid, err)
+		}
+	}
+	invalid := []string{
+		"", "hooks.example.com", "ftp://hooks.example.com", "https://",
+		"http://127.0.0.1:9000/hook", "http://[::1]/hook", "http://169.254.169.254/latest", "http://10.0.0.1/hook",
+		"https://ci.internal.example.com/hook",
+	}
+	for _, url := range invalid {
+		if err := sender.ValidateURL(url); err == nil {
+			t.Errorf("Expected %q to be rejected", url)
+		}
+	}
+
+	// Without a secret receivers could not verify the requests, so no webhook is accepted or sent
+	unsigned := NewSender(&env.Config{})
+	if err := unsigned.ValidateURL("https://hooks.example.com/a"); !errors.Is(err, ErrNoSecret) {
+		t.Errorf("Expected ErrNoSecret without a secret, got %v", err)
+	}
+	if _, err := unsigned.Send(context.Background(), "https://hooks.example.com/a", "analysis.completed", "d1", []byte(`{}`)); !errors.Is(err, ErrNoSecret) {
+		t.Errorf("Expected nothing to be sent without a secret, got %v", err)
+	}
+}
+
+// TestSend_SSRF tests that webhooks cannot reach loopback addresses, whether named in the URL, resolved from a
+// host name or redirected to
+func TestSend_SSRF(t *testing.T) {
+	var received atomic.Int32
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received.Add(1)
+	}))
+	defer target.Close()
+
+	sender := NewSender(&env.Config{WebhookSecret: "s3cret"})
+	_, port, _ := net.SplitHostPort(target.Listener.Addr().String())
+	for _, url := range []string{target.URL, "http://localhost:" + port + "/hook"} {
+		if _, err := sender.Send(context.Background(), url, "analysis.completed", "d1", []byte(`{}`)); !errors.Is(err, netguard.ErrBlockedAddress) {
+			t.Errorf("Expected the callback to %s to be blocked, got %v", url, err)
+		}
+	}
+	if received.Load() != 0 {
+		t.Error("Expected no request to reach the loopback server")
+	}
+
+	// Redirects are not followed, even to allowed addresses
+	redirect := httptest.NewServer(http.RedirectHandler(target.URL, http.StatusTemporaryRedirect))
+	defer redirect.Close()
+	sender = NewSender(&env.Config{WebhookSecret: "s3cret", SSRFAllowlist: "127.0.0.1"})
+	statusCode, err := sender.Send(context.Background(), redirect.URL, "analysis.completed", "d1", []byte(`{}`))
+	if err == nil || statusCode != http.StatusTemporaryRedirect {
+		t.Errorf("Expected the redirect to fail the attempt, got %d %v", statusCode, err)
+	}
+	if received.Load() != 0 {
+		t.Error("Expected the redirect not to be followed")
+	}
+}
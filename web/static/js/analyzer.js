@@ -0,0 +1,158 @@
+// Runs analyses as background jobs and renders their progress live from the job event stream.
+// Without JavaScript the form falls back to the synchronous POST /analyze.
+(function () {
+    'use strict';
+
+    const stageLabels = {
+        fetching: 'Fetching the page',
+        parsing: 'Parsing the HTML',
+        checking_links: 'Checking links',
+        completed: 'Completed'
+    };
+
+    let panel;
+
+    function byId(id) {
+        return document.getElementById(id);
+    }
+
+    function cell(row, content) {
+        const td = document.createElement('td');
+        if (content instanceof Node) {
+            td.appendChild(content);
+        } else {
+            td.textContent = content;
+        }
+        row.appendChild(td);
+        return td;
+    }
+
+    function badge(className, text) {
+        const span = document.createElement('span');
+        span.className = className;
+        span.textContent = text;
+        return span;
+    }
+
+    function renderProgress(progress) {
+        if (progress.stage) {
+            byId('live-stage').textContent = stageLabels[progress.stage] || progress.stage;
+        }
+        byId('live-found').textContent = progress.links_found;
+        byId('live-checked').textContent = progress.links_checked;
+        byId('live-skipped').textContent = progress.links_skipped;
+
+        const done = progress.links_checked + progress.links_skipped;
+        const percent = progress.links_found > 0 ? Math.round(100 * done / progress.links_found) : 0;
+        byId('live-progress-fill').style.width = percent + '%';
+    }
+
+    function renderLink(link) {
+        const row = document.createElement('tr');
+        cell(row, badge('truncate', link.url));
+        cell(row, link.anchor_text || 'None');
+        cell(row, badge('external-badge ' + (link.is_external ? 'external' : 'internal'), link.is_external ? 'External' : 'Internal'));
+        cell(row, badge('link-status ' + (link.is_accessible ? 'ok' : 'broken'), link.status_code || (link.is_accessible ? 'OK' : 'Failed')));
+        cell(row, link.error_class ? link.error_class + ': ' + link.error : '');
+        cell(row, link.response_time_ms + ' ms' + (link.cached ? ' (cached)' : ''));
+        byId('live-links').appendChild(row);
+
+        if (!link.is_accessible) {
+            const broken = byId('live-broken');
+            broken.textContent = Number(broken.textContent) + 1;
+        }
+    }
+
+    function renderSkippedLink(link) {
+        const row = document.createElement('tr');
+        cell(row, badge('truncate', link.url));
+        cell(row, link.anchor_text || 'None');
+        cell(row, badge('external-badge ' + (link.is_external ? 'external' : 'internal'), link.is_external ? 'External' : 'Internal'));
+        cell(row, badge('link-status broken', 'Skipped'));
+        cell(row, link.reason);
+        cell(row, '');
+        byId('live-links').appendChild(row);
+    }
+
+    function showError(message) {
+        const stage = byId('live-stage');
+        stage.classList.remove('loading');
+        stage.textContent = message;
+    }
+
+    // follow subscribes to the job events and opens the job page once the analysis has finished
+    function follow(jobID) {
+        panel.hidden = false;
+        const events = new EventSource('/api/v1/jobs/' + encodeURIComponent(jobID) + '/events');
+
+        const handle = function (render) {
+            return function (message) {
+                const event = JSON.parse(message.data);
+                renderProgress(event.progress);
+                if (render) {
+                    render(event);
+                }
+            };
+        };
+
+        events.addEventListener('stage', handle());
+        events.addEventListener('progress', handle());
+        events.addEventListener('link', handle(function (event) {
+            renderLink(event.link);
+        }));
+        events.addEventListener('skipped_link', handle(function (event) {
+            renderSkippedLink(event.skipped_link);
+        }));
+        events.addEventListener('done', function () {
+            events.close();
+            window.location.assign('/jobs/' + encodeURIComponent(jobID));
+        });
+        events.onerror = function () {
+            if (events.readyState === EventSource.CLOSED) {
+                showError('Lost the connection to the analysis');
+            }
+        };
+    }
+
+    function submit(event) {
+        event.preventDefault();
+        const url = byId('url').value;
+
+        byId('live-url').textContent = url;
+        byId('live-links').replaceChildren();
+        panel.hidden = false;
+
+        fetch('/api/v1/jobs', {
+            method: 'POST',
+            headers: {'Content-Type': 'application/json'},
+            body: JSON.stringify({url: url})
+        }).then(function (response) {
+            return response.json().then(function (body) {
+                if (!response.ok) {
+                    throw new Error(body.message || 'Failed to start the analysis');
+                }
+                return body;
+            });
+        }).then(function (job) {
+            history.pushState(null, '', '/jobs/' + encodeURIComponent(job.id));
+            follow(job.id);
+        }).catch(function (err) {
+            showError(err.message);
+        });
+    }
+
+    document.addEventListener('DOMContentLoaded', function () {
+        panel = byId('live-progress');
+        const form = byId('analyze-form');
+        if (!panel || !form || !window.EventSource) {
+            return;
+        }
+        form.addEventListener('submit', submit);
+
+        // Resume following a job page opened while the analysis is still running
+        const status = panel.dataset.jobStatus;
+        if (panel.dataset.jobId && (status === 'queued' || status === 'running')) {
+            follow(panel.dataset.jobId);
+        }
+    });
+})();